@@ -9,6 +9,7 @@ import (
 	"os/signal"
 	"strconv"
 	"syscall"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/cors"
@@ -21,6 +22,7 @@ import (
 	"y-net/internal/logger"
 	"y-net/internal/services/comments"
 	"y-net/internal/services/posts"
+	"y-net/internal/services/stories"
 	"y-net/internal/services/users"
 	"y-net/internal/utils"
 )
@@ -69,6 +71,16 @@ func main() {
 			logger.ServerLogger.Info("--------------------------------------------------------------------")
 			logger.ServerLogger.Fatalf("failed PostgreSQL migrations: %v", err)
 		}
+
+		// Background jobs
+		jobsCtx, cancelJobs := context.WithCancel(context.Background())
+		defer cancelJobs()
+
+		go posts.StartVideoProcessing(jobsCtx, 5*time.Second)
+		go posts.StartPostScheduler(jobsCtx, 30*time.Second)
+		go posts.StartTrashPurge(jobsCtx, time.Hour)
+		go comments.StartTrashPurge(jobsCtx, time.Hour)
+		go stories.StartStorySweeper(jobsCtx, time.Minute)
 	}
 
 	// Define host and port to run on
@@ -97,6 +109,10 @@ func main() {
 	r.Mount("/api/v1/users", api.UserHandler{Usecase: users.NewUserUsecase()}.Routes())
 	r.Mount("/api/v1/posts", api.PostHandler{Usecase: posts.NewPostUsecase()}.Routes())
 	r.Mount("/api/v1/comments", api.CommentHandler{Usecase: comments.NewCommentUsecase()}.Routes())
+	r.Mount("/api/v1/tags", api.TagHandler{Usecase: posts.NewPostUsecase()}.Routes())
+	r.Mount("/api/v1/places", api.PlaceHandler{Usecase: posts.NewPostUsecase()}.Routes())
+	r.Mount("/api/v1/stories", api.StoryHandler{Usecase: stories.NewStoryUsecase()}.Routes())
+	r.Mount("/api/v1/moderation", api.ModerationHandler{Usecase: posts.NewPostUsecase()}.Routes())
 
 	// Start the server api
 	logger.ServerLogger.Info(fmt.Sprintf("server running on http://%s:%s/api/v1/", host, port))
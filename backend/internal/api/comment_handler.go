@@ -1,9 +1,13 @@
 package api
 
 import (
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/google/uuid"
@@ -11,6 +15,7 @@ import (
 	"y-net/internal/auth"
 	"y-net/internal/logger"
 	"y-net/internal/services/comments"
+	"y-net/internal/services/posts"
 )
 
 type CommentHandler struct {
@@ -21,11 +26,23 @@ func (h CommentHandler) Routes() chi.Router {
 	r := chi.NewRouter()
 
 	r.Post("/", h.CreateComment)                    // POST /api/v1/comments - Create a new comment
-	r.Get("/post/{post_id}", h.GetCommentsFromPost) // GET /api/v1/comments/post/{post_id} - Read a list of comments by: post_id
+	r.Get("/post/{post_id}", h.GetCommentsFromPost) // GET /api/v1/comments/post/{post_id}?limit=20&cursor=base64string&order=top - Read a list of comments by: post_id using pagination
+	r.Get("/trash", h.GetDeletedComments)           // GET /api/v1/comments/trash - Read the recently deleted comments of the authenticated user
 
 	r.Route("/{id}", func(r chi.Router) {
-		r.Put("/", h.UpdateComment)    // PUT /api/v1/comments/{id} - Update a single comment by: id
-		r.Delete("/", h.DeleteComment) // DELETE /api/v1/comments/{id} - Delete a single comment by: id
+		r.Put("/", h.UpdateComment)          // PUT /api/v1/comments/{id} - Update a single comment by: id
+		r.Delete("/", h.DeleteComment)       // DELETE /api/v1/comments/{id} - Delete a single comment by: id
+		r.Post("/restore", h.RestoreComment) // POST /api/v1/comments/{id}/restore - Restore a deleted comment by: id
+		r.Post("/hide", h.HideComment)       // POST /api/v1/comments/{id}/hide - Hide a comment on a post of the authenticated user by: id
+		r.Delete("/hide", h.UnhideComment)   // DELETE /api/v1/comments/{id}/hide - Unhide a comment on a post of the authenticated user by: id
+		r.Post("/pin", h.PinComment)         // POST /api/v1/comments/{id}/pin - Pin a comment to the top of a post of the authenticated user by: id
+		r.Delete("/pin", h.UnpinComment)     // DELETE /api/v1/comments/{id}/pin - Unpin a comment from a post of the authenticated user by: id
+		r.Get("/replies", h.GetReplies)      // GET /api/v1/comments/{id}/replies?limit=10&cursor=base64string - Read a list of replies to a comment by: id using pagination
+
+		r.Post("/likes/{user_id}", h.LikeComment)           // POST /api/v1/comments/{id}/likes/{user_id} - Like a comment by: id
+		r.Get("/likes", h.GetCommentLikes)                  // GET /api/v1/comments/{id}/likes - Read a list of users who liked a comment by: id
+		r.Delete("/likes/{user_id}", h.UnlikeComment)       // DELETE /api/v1/comments/{id}/likes/{user_id} - Unlike a comment by: id
+		r.Get("/likes/check/{user_id}", h.UserLikedComment) // GET /api/v1/comments/{id}/likes/check/{user_id} - Check if a user has liked a comment by: id
 	})
 
 	return r
@@ -33,7 +50,7 @@ func (h CommentHandler) Routes() chi.Router {
 
 // CreateComment godoc
 // @Summary      Create a new comment
-// @Description  Create a new comment
+// @Description  Create a new comment, as long as the comment policy of the post allows the user to comment on it. Replies to a reply are added to the thread of its parent
 // @Tags         comments
 // @Accept       json
 // @Produce      json
@@ -43,6 +60,7 @@ func (h CommentHandler) Routes() chi.Router {
 // @Failure      400
 // @Failure      401
 // @Failure      403
+// @Failure      404
 // @Failure      500
 // @Router       /comments [post]
 func (h CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
@@ -80,7 +98,7 @@ func (h CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), commentErrorStatus(err))
 		return
 	}
 
@@ -98,14 +116,19 @@ func (h CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
 }
 
 // GetCommentsFromPost godoc
-// @Summary            Read a list of comments by: post_id
-// @Description        Read a list of comments by: post_id
+// @Summary            Read a list of comments by: post_id using pagination
+// @Description        Read the top-level comments of a post by: post_id using pagination, along with their reply counts and first replies. The first page starts with the pinned comment of the post, if any. Deleted comments with replies are returned without their author and message
 // @Tags               comments
 // @Produce            json
 // @Param              Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
 // @Param              post_id path string true "Post ID" Format(uuid)
+// @Param              limit query int false "limit of pagination, 20 by default and at most 100"
+// @Param              cursor query string false "cursor for pagination, led by the like count of the last comment for top" Format(byte)
+// @Param              order query string false "order of the comments, by creation date or by like count for top" Enums(asc, desc, top) default(desc)
 // @Success            200 {array} comments.Comment
+// @Failure            400
 // @Failure            401
+// @Failure            404
 // @Failure            500
 // @Router             /comments/post/{post_id} [get]
 func (h CommentHandler) GetCommentsFromPost(w http.ResponseWriter, r *http.Request) {
@@ -130,7 +153,49 @@ func (h CommentHandler) GetCommentsFromPost(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	comments, err := h.Usecase.GetFromPost(r.Context(), postId)
+	limit := comments.DefaultCommentsLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > comments.MaxCommentsLimit {
+			http.Error(w, "invalid comments limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	order := comments.CommentsOrderNewest
+	if orderStr := r.URL.Query().Get("order"); orderStr != "" {
+		if !comments.IsCommentsOrder(orderStr) {
+			http.Error(w, "invalid comments order", http.StatusBadRequest)
+			return
+		}
+		order = orderStr
+	}
+
+	lastLikeCount, lastCreatedAt, lastId := 0, time.Time{}, uuid.Nil
+	cursor := r.URL.Query().Get("cursor")
+	if cursor != "" {
+		if order == comments.CommentsOrderTop {
+			lastLikeCount, lastCreatedAt, lastId, err = decodeTopCursor(cursor)
+		} else {
+			lastCreatedAt, lastId, err = decodeCursor(cursor)
+		}
+		if err != nil {
+			logger.ServerLogger.Error(err.Error())
+
+			http.Error(w, "invalid comments cursor", http.StatusBadRequest)
+			return
+		}
+	}
+
+	postComments, err := h.Usecase.GetFromPost(r.Context(), authUser.ID, postId, limit, order, lastLikeCount, lastCreatedAt, lastId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), commentErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(postComments)
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
 
@@ -138,7 +203,77 @@ func (h CommentHandler) GetCommentsFromPost(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	response, err := json.Marshal(comments)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// GetReplies   godoc
+// @Summary      Read a list of replies to a comment by: id using pagination
+// @Description  Read a list of replies to a comment by: id using pagination, oldest first
+// @Tags         comments
+// @Produce      json
+// @Param        Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param        id path string true "Comment ID" Format(uuid)
+// @Param        limit query int false "limit of pagination, 20 by default and at most 100"
+// @Param        cursor query string false "cursor for pagination" Format(byte)
+// @Success      200 {array} comments.Comment
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /comments/{id}/replies [get]
+func (h CommentHandler) GetReplies(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	commentId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid comment id", http.StatusBadRequest)
+		return
+	}
+
+	limit := comments.DefaultCommentsLimit
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > comments.MaxCommentsLimit {
+			http.Error(w, "invalid replies limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	lastCreatedAt, lastId := time.Time{}, uuid.Nil
+	cursor := r.URL.Query().Get("cursor")
+	if cursor != "" {
+		lastCreatedAt, lastId, err = decodeCursor(cursor)
+		if err != nil {
+			logger.ServerLogger.Error(err.Error())
+
+			http.Error(w, "invalid replies cursor", http.StatusBadRequest)
+			return
+		}
+	}
+
+	replies, err := h.Usecase.GetReplies(r.Context(), authUser.ID, commentId, limit, lastCreatedAt, lastId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), commentErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(replies)
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
 
@@ -220,7 +355,7 @@ func (h CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
 
 // DeleteComment godoc
 // @Summary      Delete a single comment by: id
-// @Description  Delete a single comment by: id
+// @Description  Delete a single comment by: id, either by its author or by the author of the post, moving it to the trash of the user deleting it from where it can be restored for 30 days
 // @Tags         comments
 // @Param        Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
 // @Param        id path string true "Comment ID" Format(uuid)
@@ -228,6 +363,7 @@ func (h CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
 // @Failure      400
 // @Failure      401
 // @Failure      403
+// @Failure      404
 // @Failure      500
 // @Router       /comments/{id} [delete]
 func (h CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
@@ -256,11 +392,11 @@ func (h CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), commentErrorStatus(err))
 		return
 	}
 
-	if authUser.ID != comment.User.ID {
+	if authUser.ID != comment.User.ID && authUser.ID != comment.PostOwnerID {
 		err := fmt.Errorf("forbidden comment delete attempt from user: %v", authUser.ID)
 
 		logger.ServerLogger.Warn(err.Error())
@@ -269,7 +405,7 @@ func (h CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = h.Usecase.Delete(r.Context(), commentId)
+	err = h.Usecase.Delete(r.Context(), authUser.ID, commentId)
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
 
@@ -279,3 +415,558 @@ func (h CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// GetDeletedComments godoc
+// @Summary           Read the recently deleted comments of the authenticated user
+// @Description       Read the comments of the authenticated user that are in the trash and can still be restored, most recently deleted first
+// @Tags              comments
+// @Produce           json
+// @Param             Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success           200 {array} comments.Comment
+// @Failure           401
+// @Failure           500
+// @Router            /comments/trash [get]
+func (h CommentHandler) GetDeletedComments(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	deleted, err := h.Usecase.GetTrash(r.Context(), authUser.ID)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), commentErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(deleted)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// RestoreComment godoc
+// @Summary       Restore a deleted comment by: id
+// @Description   Restore a comment deleted by the authenticated user by: id from the trash
+// @Tags          comments
+// @Param         Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param         id path string true "Comment ID" Format(uuid)
+// @Success       200
+// @Failure       400
+// @Failure       401
+// @Failure       404
+// @Failure       500
+// @Router        /comments/{id}/restore [post]
+func (h CommentHandler) RestoreComment(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	commentId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid comment id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.Restore(r.Context(), authUser.ID, commentId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), commentErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HideComment  godoc
+// @Summary      Hide a comment on a post of the authenticated user by: id
+// @Description  Hide a comment on a post of the authenticated user by: id, the comment being only shown to its author and to the author of the post
+// @Tags         comments
+// @Param        Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param        id path string true "Comment ID" Format(uuid)
+// @Success      200
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /comments/{id}/hide [post]
+func (h CommentHandler) HideComment(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	h.setCommentHidden(w, r, true)
+}
+
+// UnhideComment godoc
+// @Summary      Unhide a comment on a post of the authenticated user by: id
+// @Description  Unhide a comment on a post of the authenticated user by: id
+// @Tags         comments
+// @Param        Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param        id path string true "Comment ID" Format(uuid)
+// @Success      200
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /comments/{id}/hide [delete]
+func (h CommentHandler) UnhideComment(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: delete %s", r.URL))
+
+	h.setCommentHidden(w, r, false)
+}
+
+// setCommentHidden hides or unhides a comment, which only the author of the post it belongs to is allowed to do
+func (h CommentHandler) setCommentHidden(w http.ResponseWriter, r *http.Request, hidden bool) {
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	commentId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid comment id", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.Usecase.Get(r.Context(), commentId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), commentErrorStatus(err))
+		return
+	}
+
+	if authUser.ID != comment.PostOwnerID {
+		err := fmt.Errorf("forbidden comment hide attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	if hidden {
+		err = h.Usecase.Hide(r.Context(), commentId)
+	} else {
+		err = h.Usecase.Unhide(r.Context(), commentId)
+	}
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), commentErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// PinComment   godoc
+// @Summary     Pin a comment to the top of a post of the authenticated user by: id
+// @Description Pin a top-level comment to the top of a post of the authenticated user by: id, in place of the comment pinned before it
+// @Tags        comments
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Comment ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /comments/{id}/pin [post]
+func (h CommentHandler) PinComment(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	h.setCommentPinned(w, r, true)
+}
+
+// UnpinComment godoc
+// @Summary     Unpin a comment from a post of the authenticated user by: id
+// @Description Unpin a comment from a post of the authenticated user by: id
+// @Tags        comments
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Comment ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /comments/{id}/pin [delete]
+func (h CommentHandler) UnpinComment(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: delete %s", r.URL))
+
+	h.setCommentPinned(w, r, false)
+}
+
+// setCommentPinned pins or unpins a comment, which only the author of the post it belongs to is allowed to do
+func (h CommentHandler) setCommentPinned(w http.ResponseWriter, r *http.Request, pinned bool) {
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	commentId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid comment id", http.StatusBadRequest)
+		return
+	}
+
+	comment, err := h.Usecase.Get(r.Context(), commentId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), commentErrorStatus(err))
+		return
+	}
+
+	if authUser.ID != comment.PostOwnerID {
+		err := fmt.Errorf("forbidden comment pin attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	if pinned {
+		err = h.Usecase.Pin(r.Context(), commentId)
+	} else {
+		err = h.Usecase.Unpin(r.Context(), commentId)
+	}
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), commentErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// LikeComment  godoc
+// @Summary     Like a comment by: id
+// @Description Like a comment by: id, a user liking a comment only once
+// @Tags        comments
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Comment ID" Format(uuid)
+// @Param       user_id path string true "User ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /comments/{id}/likes/{user_id} [post]
+func (h CommentHandler) LikeComment(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if authUser.ID != userId {
+		err := fmt.Errorf("forbidden comment like attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	commentId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid comment id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.Like(r.Context(), userId, commentId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), commentErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetCommentLikes godoc
+// @Summary        Read a list of users who liked a comment by: id
+// @Description    Read a list of users who liked a comment by: id, most recent likes first
+// @Tags           comments
+// @Produce        json
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param          id path string true "Comment ID" Format(uuid)
+// @Success        200 {array} shared.User
+// @Failure        400
+// @Failure        401
+// @Failure        404
+// @Failure        500
+// @Router         /comments/{id}/likes [get]
+func (h CommentHandler) GetCommentLikes(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	commentId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid comment id", http.StatusBadRequest)
+		return
+	}
+
+	likes, err := h.Usecase.GetLikes(r.Context(), authUser.ID, commentId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), commentErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(likes)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// UnlikeComment godoc
+// @Summary      Unlike a comment by: id
+// @Description  Unlike a comment by: id
+// @Tags         comments
+// @Param        Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param        id path string true "Comment ID" Format(uuid)
+// @Param        user_id path string true "User ID" Format(uuid)
+// @Success      200
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      500
+// @Router       /comments/{id}/likes/{user_id} [delete]
+func (h CommentHandler) UnlikeComment(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: delete %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if authUser.ID != userId {
+		err := fmt.Errorf("forbidden comment unlike attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	commentId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid comment id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.Unlike(r.Context(), userId, commentId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), commentErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// UserLikedComment godoc
+// @Summary         Check if a user has liked a comment by: id
+// @Description     Check if a user has liked a comment by: id
+// @Tags            comments
+// @Produce         json
+// @Param           Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param           id path string true "Comment ID" Format(uuid)
+// @Param           user_id path string true "User ID" Format(uuid)
+// @Success         200 {object} posts.LikedJson
+// @Failure         400
+// @Failure         401
+// @Failure         500
+// @Router          /comments/{id}/likes/check/{user_id} [get]
+func (h CommentHandler) UserLikedComment(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	commentId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid comment id", http.StatusBadRequest)
+		return
+	}
+
+	isLiked, err := h.Usecase.UserLikedComment(r.Context(), userId, commentId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := json.Marshal(posts.LikedJson{Liked: isLiked})
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// commentErrorStatus maps the errors of the comments usecase to a response status,
+// comments of posts the user is not allowed to read being reported as missing
+func commentErrorStatus(err error) int {
+	switch err.(type) {
+	case *comments.PostNotFoundError, *comments.CommentNotFoundError:
+		return http.StatusNotFound
+	case *comments.CommentNotAllowedError:
+		return http.StatusForbidden
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// decodeTopCursor decodes the cursor of the top comments, which holds the like count
+// of the last comment read in front of its creation date and id
+func decodeTopCursor(encodedCursor string) (int, time.Time, uuid.UUID, error) {
+	byt, err := base64.StdEncoding.DecodeString(encodedCursor)
+	if err != nil {
+		return 0, time.Time{}, uuid.Nil, err
+	}
+
+	arrStr := strings.Split(string(byt), ",")
+	if len(arrStr) != 3 {
+		return 0, time.Time{}, uuid.Nil, fmt.Errorf("invalid comments cursor")
+	}
+
+	lastLikeCount, err := strconv.Atoi(arrStr[0])
+	if err != nil {
+		return 0, time.Time{}, uuid.Nil, fmt.Errorf("invalid comments lastLikeCount")
+	}
+
+	lastCreatedAt, err := time.Parse(time.RFC3339Nano, arrStr[1])
+	if err != nil {
+		return 0, time.Time{}, uuid.Nil, fmt.Errorf("invalid comments lastCreatedAt")
+	}
+
+	lastId, err := uuid.Parse(arrStr[2])
+	if err != nil {
+		return 0, time.Time{}, uuid.Nil, fmt.Errorf("invalid comments lastId")
+	}
+
+	return lastLikeCount, lastCreatedAt, lastId, nil
+}
@@ -0,0 +1,344 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"y-net/internal/auth"
+	"y-net/internal/logger"
+	"y-net/internal/services/posts"
+)
+
+type ModerationHandler struct {
+	Usecase posts.IPostUsecase
+}
+
+func (h ModerationHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Post("/banned-images", h.BanImage)             // POST /api/v1/moderation/banned-images - Add an image to the banned images list
+	r.Get("/banned-images", h.GetBannedImages)       // GET /api/v1/moderation/banned-images - Read the banned images list
+	r.Delete("/banned-images/{id}", h.UnbanImage)    // DELETE /api/v1/moderation/banned-images/{id} - Remove an image from the banned images list by: id
+	r.Get("/posts/{id}/similar", h.GetSimilarImages) // GET /api/v1/moderation/posts/{id}/similar?distance=10 - Read a list of images similar to the ones of a post by: id
+	r.Put("/posts/{id}/sensitive", h.FlagSensitive)  // PUT /api/v1/moderation/posts/{id}/sensitive - Flag a post as sensitive or lift the flag by: id
+
+	return r
+}
+
+// BanImage     godoc
+// @Summary     Add an image to the banned images list
+// @Description Add an image to the banned images list, either by its base64 image or by its perceptual hash
+// @Tags        moderation
+// @Accept      json
+// @Produce     json
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       body body posts.BannedImage true "Banned Image Object"
+// @Success     200 {object} posts.BannedImage
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /moderation/banned-images [post]
+func (h ModerationHandler) BanImage(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if !authUser.IsModerator {
+		err := fmt.Errorf("forbidden image ban attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	var bannedImage posts.BannedImage
+	err := json.NewDecoder(r.Body).Decode(&bannedImage)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	bannedImage.CreatedBy = authUser.ID
+
+	newBannedImage, err := h.Usecase.BanImage(r.Context(), bannedImage)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := json.Marshal(newBannedImage)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// GetBannedImages godoc
+// @Summary        Read the banned images list
+// @Description    Read the banned images list
+// @Tags           moderation
+// @Produce        json
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success        200 {array} posts.BannedImage
+// @Failure        401
+// @Failure        403
+// @Failure        500
+// @Router         /moderation/banned-images [get]
+func (h ModerationHandler) GetBannedImages(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if !authUser.IsModerator {
+		err := fmt.Errorf("forbidden banned images read attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	bannedImages, err := h.Usecase.GetBannedImages(r.Context())
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := json.Marshal(bannedImages)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// UnbanImage   godoc
+// @Summary     Remove an image from the banned images list by: id
+// @Description Remove an image from the banned images list by: id
+// @Tags        moderation
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Banned Image ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     500
+// @Router      /moderation/banned-images/{id} [delete]
+func (h ModerationHandler) UnbanImage(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: delete %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if !authUser.IsModerator {
+		err := fmt.Errorf("forbidden image unban attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid banned image id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.UnbanImage(r.Context(), id)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetSimilarImages godoc
+// @Summary         Read a list of images similar to the ones of a post by: id
+// @Description     Read a list of images similar to the ones of a post by: id, comparing perceptual hashes
+// @Tags            moderation
+// @Produce         json
+// @Param           Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param           id path string true "Post ID" Format(uuid)
+// @Param           distance query int false "maximum hamming distance between hashes"
+// @Success         200 {array} posts.SimilarImage
+// @Failure         400
+// @Failure         401
+// @Failure         403
+// @Failure         500
+// @Router          /moderation/posts/{id}/similar [get]
+func (h ModerationHandler) GetSimilarImages(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if !authUser.IsModerator {
+		err := fmt.Errorf("forbidden similar images read attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	distance := posts.BannedImageMaxDistance
+	if distanceStr := r.URL.Query().Get("distance"); distanceStr != "" {
+		distance, err = strconv.Atoi(distanceStr)
+		if err != nil || distance < 0 || distance > posts.MaxSimilarDistance {
+			http.Error(w, "invalid distance", http.StatusBadRequest)
+			return
+		}
+	}
+
+	images, err := h.Usecase.GetSimilarImages(r.Context(), postId, distance)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := json.Marshal(images)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// FlagSensitive godoc
+// @Summary      Flag a post as sensitive or lift the flag by: id
+// @Description  Flag a post as sensitive or lift the flag by: id, optionally replacing its content warning. Sensitive posts are blurred for the users who did not choose to reveal sensitive media and left out of the explore surfaces
+// @Tags         moderation
+// @Accept       json
+// @Param        Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param        id path string true "Post ID" Format(uuid)
+// @Param        body body posts.SensitiveFlag true "Sensitive Flag Object"
+// @Success      200
+// @Failure      400
+// @Failure      401
+// @Failure      403
+// @Failure      404
+// @Failure      500
+// @Router       /moderation/posts/{id}/sensitive [put]
+func (h ModerationHandler) FlagSensitive(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: put %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	if !authUser.IsModerator {
+		err := fmt.Errorf("forbidden sensitive flag attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	var flag posts.SensitiveFlag
+	err = json.NewDecoder(r.Body).Decode(&flag)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.FlagSensitive(r.Context(), postId, flag)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"y-net/internal/auth"
+	"y-net/internal/logger"
+	"y-net/internal/services/posts"
+)
+
+type PlaceHandler struct {
+	Usecase posts.IPostUsecase
+}
+
+func (h PlaceHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/{id}/posts", h.ListPlacePosts) // GET /api/v1/places/{id}/posts?limit=10&cursor=base64string - Read a list of posts by: place_id using pagination
+
+	return r
+}
+
+// ListPlacePosts godoc
+// @Summary       Read a list of posts by: place_id using pagination
+// @Description   Read a list of posts by: place_id using pagination, the posts of users who hide their location being left out
+// @Tags          places
+// @Produce       json
+// @Param         Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param         id path string true "Place ID" Format(uuid)
+// @Param         limit query int true "limit of pagination"
+// @Param         cursor query string false "cursor for pagination" Format(byte)
+// @Success       200 {array} shared.Post
+// @Failure       400
+// @Failure       401
+// @Failure       500
+// @Router        /places/{id}/posts [get]
+func (h PlaceHandler) ListPlacePosts(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	placeId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid place id", http.StatusBadRequest)
+		return
+	}
+
+	limitStr := r.URL.Query().Get("limit")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid posts limit", http.StatusBadRequest)
+		return
+	}
+
+	lastCreatedAt, lastId := time.Time{}, uuid.Nil
+	cursor := r.URL.Query().Get("cursor")
+	if cursor != "" {
+		lastCreatedAt, lastId, err = decodeCursor(cursor)
+		if err != nil {
+			logger.ServerLogger.Error(err.Error())
+
+			http.Error(w, "invalid posts cursor", http.StatusBadRequest)
+			return
+		}
+	}
+
+	placePosts, err := h.Usecase.GetPostsByPlace(r.Context(), authUser.ID, placeId, limit, lastCreatedAt, lastId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := json.Marshal(placePosts)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
@@ -4,7 +4,9 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
+	"os"
 	"strconv"
 	"strings"
 	"time"
@@ -14,6 +16,7 @@ import (
 
 	"y-net/internal/auth"
 	"y-net/internal/logger"
+	"y-net/internal/media"
 	"y-net/internal/services/posts"
 	"y-net/internal/services/shared"
 )
@@ -28,6 +31,12 @@ func (h PostHandler) Routes() chi.Router {
 	r.Post("/", h.CreatePost) // POST /api/v1/posts - Create a new post
 	r.Get("/", h.ListPosts)   // GET /api/v1/posts?limit=10&cursor=base64string - Read a list of posts using pagination
 
+	r.Get("/search/{search_term}", h.SearchPosts) // GET /api/v1/posts/search/{search_term} - Read a list of posts by: search_term
+	r.Get("/reactions", h.ListReactionTypes)      // GET /api/v1/posts/reactions - Read the list of reactions users can leave on posts
+	r.Get("/drafts", h.ListDrafts)                // GET /api/v1/posts/drafts - Read the drafts and scheduled posts of the authenticated user
+	r.Get("/trash", h.ListDeletedPosts)           // GET /api/v1/posts/trash - Read the recently deleted posts of the authenticated user
+	r.Get("/nearby", h.ListNearbyPosts)           // GET /api/v1/posts/nearby?lat=0&lng=0&radius=1000 - Read the most recent posts tagged with a place near a position
+
 	r.Route("/{id}", func(r chi.Router) {
 		r.Get("/", h.GetPost)                            // GET /api/v1/posts/{id} - Read a single post by: id
 		r.Put("/", h.UpdatePost)                         // PUT /api/v1/posts/{id} - Update a single post by: id
@@ -36,6 +45,26 @@ func (h PostHandler) Routes() chi.Router {
 		r.Get("/likes", h.GetLikes)                      // GET /api/v1/posts/{id}/likes - Read a list of users who liked a post by: post_id
 		r.Delete("/likes/{user_id}", h.Unlike)           // DELETE /api/v1/posts/{id}/likes/{user_id} - Unlike a post by: id
 		r.Get("/likes/check/{user_id}", h.UserLikedPost) // GET /api/v1/posts/{id}/likes/check/{user_id} - Check if a user has liked a post by: id
+		r.Post("/saves", h.Save)                         // POST /api/v1/posts/{id}/saves - Save a post by: id for the authenticated user
+		r.Delete("/saves", h.Unsave)                     // DELETE /api/v1/posts/{id}/saves - Unsave a post by: id for the authenticated user
+		r.Get("/saves/check", h.UserSavedPost)           // GET /api/v1/posts/{id}/saves/check - Check if the authenticated user has saved a post by: id
+		r.Post("/reposts", h.Repost)                     // POST /api/v1/posts/{id}/reposts - Repost a post by: id for the authenticated user
+		r.Delete("/reposts", h.Unrepost)                 // DELETE /api/v1/posts/{id}/reposts - Undo a repost of a post by: id for the authenticated user
+		r.Post("/reactions", h.React)                    // POST /api/v1/posts/{id}/reactions - React to a post by: id for the authenticated user
+		r.Delete("/reactions", h.Unreact)                // DELETE /api/v1/posts/{id}/reactions - Remove the reaction of the authenticated user to a post by: id
+		r.Get("/reactions", h.GetReactions)              // GET /api/v1/posts/{id}/reactions?type=heart - Read a list of reactions to a post by: id, type
+		r.Get("/media/{media_id}/video", h.StreamVideo)  // GET /api/v1/posts/{id}/media/{media_id}/video - Stream a post video by: id, media_id
+		r.Post("/publish", h.Publish)                    // POST /api/v1/posts/{id}/publish - Publish a draft by: id right away
+		r.Put("/schedule", h.Schedule)                   // PUT /api/v1/posts/{id}/schedule - Schedule a draft by: id to be published later
+		r.Delete("/schedule", h.Unschedule)              // DELETE /api/v1/posts/{id}/schedule - Unschedule a draft by: id, keeping it as a draft
+		r.Get("/revisions", h.GetRevisions)              // GET /api/v1/posts/{id}/revisions - Read the edit history of a post by: id
+		r.Post("/restore", h.RestorePost)                // POST /api/v1/posts/{id}/restore - Restore a deleted post by: id
+		r.Post("/archive", h.Archive)                    // POST /api/v1/posts/{id}/archive - Archive a post by: id, hiding it from the profile of its author
+		r.Delete("/archive", h.Unarchive)                // DELETE /api/v1/posts/{id}/archive - Unarchive a post by: id
+		r.Post("/pin", h.Pin)                            // POST /api/v1/posts/{id}/pin - Pin a post by: id to the top of the profile of its author
+		r.Delete("/pin", h.Unpin)                        // DELETE /api/v1/posts/{id}/pin - Unpin a post by: id
+		r.Get("/poll", h.GetPoll)                        // GET /api/v1/posts/{id}/poll - Read the poll of a post by: id
+		r.Post("/poll/votes", h.Vote)                    // POST /api/v1/posts/{id}/poll/votes - Vote in the poll of a post by: id for the authenticated user
 	})
 
 	return r
@@ -53,6 +82,7 @@ func (h PostHandler) Routes() chi.Router {
 // @Failure     400
 // @Failure     401
 // @Failure     403
+// @Failure     404
 // @Failure     500
 // @Router      /posts [post]
 func (h PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
@@ -86,15 +116,24 @@ func (h PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	id, err := h.Usecase.Create(r.Context(), post)
+	err = validateAltText(post)
+	if err != nil {
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	newPost, err := h.Usecase.Create(r.Context(), post)
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), postErrorStatus(err))
 		return
 	}
 
-	response, err := json.Marshal(shared.Post{ID: id})
+	// Posts with videos stay in processing until their poster frames have been extracted
+	response, err := json.Marshal(shared.Post{ID: newPost.ID, Status: newPost.Status, Draft: newPost.Draft})
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
 
@@ -153,7 +192,7 @@ func (h PostHandler) ListPosts(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		posts, err = h.Usecase.GetPosts(r.Context(), limit, lastCreatedAt, lastId)
+		posts, err = h.Usecase.GetPosts(r.Context(), authUser.ID, limit, lastCreatedAt, lastId)
 		if err != nil {
 			logger.ServerLogger.Error(err.Error())
 
@@ -161,7 +200,7 @@ func (h PostHandler) ListPosts(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	} else {
-		posts, err = h.Usecase.GetPosts(r.Context(), limit, time.Time{}, uuid.Nil)
+		posts, err = h.Usecase.GetPosts(r.Context(), authUser.ID, limit, time.Time{}, uuid.Nil)
 		if err != nil {
 			logger.ServerLogger.Error(err.Error())
 
@@ -183,6 +222,123 @@ func (h PostHandler) ListPosts(w http.ResponseWriter, r *http.Request) {
 	w.Write(response)
 }
 
+// SearchPosts  godoc
+// @Summary     Read a list of posts by: search_term
+// @Description Read a list of posts by: search_term, matching post descriptions and image alt texts
+// @Tags        posts
+// @Produce     json
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       search_term path string true "Post Search Term"
+// @Success     200 {array} shared.Post
+// @Failure     400
+// @Failure     401
+// @Failure     500
+// @Router      /posts/search/{search_term} [get]
+func (h PostHandler) SearchPosts(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	searchStr := chi.URLParam(r, "search_term")
+	if strings.TrimSpace(searchStr) == "" {
+		http.Error(w, "invalid search term", http.StatusBadRequest)
+		return
+	}
+
+	posts, err := h.Usecase.GetBySearch(r.Context(), authUser.ID, searchStr)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := json.Marshal(posts)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// ListNearbyPosts godoc
+// @Summary        Read the most recent posts tagged with a place near a position
+// @Description    Read the most recent posts tagged with a place within radius metres of a position, places being stored with rounded coordinates
+// @Tags           posts
+// @Produce        json
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param          lat query number true "latitude of the position"
+// @Param          lng query number true "longitude of the position"
+// @Param          radius query int false "radius of the search in metres, 1000 by default"
+// @Success        200 {array} shared.Post
+// @Failure        400
+// @Failure        401
+// @Failure        500
+// @Router         /posts/nearby [get]
+func (h PostHandler) ListNearbyPosts(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	latitude, errLat := strconv.ParseFloat(r.URL.Query().Get("lat"), 64)
+	longitude, errLng := strconv.ParseFloat(r.URL.Query().Get("lng"), 64)
+	if errLat != nil || errLng != nil || !posts.ValidCoordinates(latitude, longitude) {
+		http.Error(w, "invalid nearby coordinates", http.StatusBadRequest)
+		return
+	}
+
+	radius := posts.DefaultNearbyRadius
+	if radiusStr := r.URL.Query().Get("radius"); radiusStr != "" {
+		var err error
+		radius, err = strconv.Atoi(radiusStr)
+		if err != nil || radius <= 0 || radius > posts.MaxNearbyRadius {
+			http.Error(w, "invalid nearby radius", http.StatusBadRequest)
+			return
+		}
+	}
+
+	nearbyPosts, err := h.Usecase.GetNearbyPosts(r.Context(), authUser.ID, latitude, longitude, float64(radius))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := json.Marshal(nearbyPosts)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
 // GetPost      godoc
 // @Summary     Read a single post by: id
 // @Description Read a single post by: id
@@ -193,6 +349,7 @@ func (h PostHandler) ListPosts(w http.ResponseWriter, r *http.Request) {
 // @Success     200 {object} shared.Post
 // @Failure     400
 // @Failure     401
+// @Failure     404
 // @Failure     500
 // @Router      /posts/{id} [get]
 func (h PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
@@ -217,11 +374,11 @@ func (h PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	post, err := h.Usecase.GetPost(r.Context(), postId)
+	post, err := h.Usecase.GetPost(r.Context(), authUser.ID, postId)
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), postErrorStatus(err))
 		return
 	}
 
@@ -240,7 +397,7 @@ func (h PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
 
 // UpdatePost   godoc
 // @Summary     Update a single post by: id
-// @Description Update a single post by: id
+// @Description Update a single post by: id, keeping a revision of its previous version once published
 // @Tags        posts
 // @Accept      json
 // @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
@@ -250,6 +407,7 @@ func (h PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
 // @Failure     400
 // @Failure     401
 // @Failure     403
+// @Failure     404
 // @Failure     500
 // @Router      /posts/{id} [put]
 func (h PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
@@ -274,11 +432,11 @@ func (h PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ogPost, err := h.Usecase.GetPost(r.Context(), postId)
+	ogPost, err := h.Usecase.GetPost(r.Context(), authUser.ID, postId)
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), postErrorStatus(err))
 		return
 	}
 
@@ -291,8 +449,25 @@ func (h PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if ogPost.RepostOf != nil {
+		err := fmt.Errorf("reposts can not be updated")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+
 	var post shared.Post
-	err = json.NewDecoder(r.Body).Decode(&post)
+	err = json.Unmarshal(body, &post)
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
 
@@ -300,11 +475,42 @@ func (h PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The quoted post and the poll are fixed once the post has been created
+	post.QuoteOf = ogPost.QuoteOf
+	post.Poll = ogPost.Poll
+	if post.Visibility == "" {
+		post.Visibility = ogPost.Visibility
+	}
+	if post.CommentPolicy == "" {
+		post.CommentPolicy = ogPost.CommentPolicy
+	}
+
+	// Posts keep their place unless the request sets it, a null place removing it
+	if !hasField(body, "place") {
+		post.Place = ogPost.Place
+	}
+
+	// Posts stay sensitive and keep their content warning unless the request sets them
+	if !hasField(body, "sensitive") {
+		post.Sensitive = ogPost.Sensitive
+	}
+	if !hasField(body, "contentWarning") {
+		post.ContentWarning = ogPost.ContentWarning
+	}
+
+	err = validateAltText(post)
+	if err != nil {
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	err = h.Usecase.Update(r.Context(), post, postId)
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), postErrorStatus(err))
 		return
 	}
 
@@ -313,7 +519,7 @@ func (h PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 
 // DeletePost   godoc
 // @Summary     Delete a single post by: id
-// @Description Delete a single post by: id
+// @Description Delete a single post by: id, moving it to the trash from where it can be restored for 30 days. Reposts are removed right away
 // @Tags        posts
 // @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
 // @Param       id path string true "Post ID" Format(uuid)
@@ -321,6 +527,7 @@ func (h PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 // @Failure     400
 // @Failure     401
 // @Failure     403
+// @Failure     404
 // @Failure     500
 // @Router      /posts/{id} [delete]
 func (h PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
@@ -345,11 +552,11 @@ func (h PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	ogPost, err := h.Usecase.GetPost(r.Context(), postId)
+	ogPost, err := h.Usecase.GetPost(r.Context(), authUser.ID, postId)
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), postErrorStatus(err))
 		return
 	}
 
@@ -366,7 +573,7 @@ func (h PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), postErrorStatus(err))
 		return
 	}
 
@@ -384,6 +591,7 @@ func (h PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 // @Failure     400
 // @Failure     401
 // @Failure     403
+// @Failure     404
 // @Failure     500
 // @Router      /posts/{id}/likes/{user_id} [post]
 func (h PostHandler) Like(w http.ResponseWriter, r *http.Request) {
@@ -428,7 +636,7 @@ func (h PostHandler) Like(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), postErrorStatus(err))
 		return
 	}
 
@@ -445,6 +653,7 @@ func (h PostHandler) Like(w http.ResponseWriter, r *http.Request) {
 // @Success         200 {array} shared.User
 // @Failure         400
 // @Failure         401
+// @Failure         404
 // @Failure         500
 // @Router          /posts/{id}/likes [get]
 func (h PostHandler) GetLikes(w http.ResponseWriter, r *http.Request) {
@@ -469,11 +678,11 @@ func (h PostHandler) GetLikes(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	likes, err := h.Usecase.GetLikes(r.Context(), postId)
+	likes, err := h.Usecase.GetLikes(r.Context(), authUser.ID, postId)
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), postErrorStatus(err))
 		return
 	}
 
@@ -563,6 +772,7 @@ func (h PostHandler) Unlike(w http.ResponseWriter, r *http.Request) {
 // @Success      200 {object} posts.LikedJson
 // @Failure      400
 // @Failure      401
+// @Failure      404
 // @Failure      500
 // @Router       /posts/{id}/likes/check/{user_id} [get]
 func (h PostHandler) UserLikedPost(w http.ResponseWriter, r *http.Request) {
@@ -594,11 +804,11 @@ func (h PostHandler) UserLikedPost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	isLiked, err := h.Usecase.UserLikedPost(r.Context(), userId, postId)
+	isLiked, err := h.Usecase.UserLikedPost(r.Context(), authUser.ID, userId, postId)
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
 
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		http.Error(w, err.Error(), postErrorStatus(err))
 		return
 	}
 
@@ -615,6 +825,1321 @@ func (h PostHandler) UserLikedPost(w http.ResponseWriter, r *http.Request) {
 	w.Write(response)
 }
 
+// Save         godoc
+// @Summary     Save a post by: id for the authenticated user
+// @Description Save a post by: id for the authenticated user, saved posts are only visible to the user
+// @Tags        posts
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/saves [post]
+func (h PostHandler) Save(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.Save(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Unsave       godoc
+// @Summary     Unsave a post by: id for the authenticated user
+// @Description Unsave a post by: id for the authenticated user, also removing it from the user collections
+// @Tags        posts
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     500
+// @Router      /posts/{id}/saves [delete]
+func (h PostHandler) Unsave(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: delete %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.Unsave(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// UserSavedPost godoc
+// @Summary      Check if the authenticated user has saved a post by: id
+// @Description  Check if the authenticated user has saved a post by: id
+// @Tags         posts
+// @Produce      json
+// @Param        Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param        id path string true "Post ID" Format(uuid)
+// @Success      200 {object} posts.SavedJson
+// @Failure      400
+// @Failure      401
+// @Failure      404
+// @Failure      500
+// @Router       /posts/{id}/saves/check [get]
+func (h PostHandler) UserSavedPost(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	isSaved, err := h.Usecase.UserSavedPost(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(posts.SavedJson{Saved: isSaved})
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// Repost       godoc
+// @Summary     Repost a post by: id for the authenticated user
+// @Description Repost a post by: id for the authenticated user, reposting a repost reposts the original post
+// @Tags        posts
+// @Produce     json
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Success     200 {object} shared.Post
+// @Failure     400
+// @Failure     401
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/reposts [post]
+func (h PostHandler) Repost(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	id, err := h.Usecase.Repost(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(shared.Post{ID: id, Status: shared.StatusReady})
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// Unrepost     godoc
+// @Summary     Undo a repost of a post by: id for the authenticated user
+// @Description Undo a repost of a post by: id for the authenticated user
+// @Tags        posts
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/reposts [delete]
+func (h PostHandler) Unrepost(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: delete %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.Unrepost(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// React        godoc
+// @Summary     React to a post by: id for the authenticated user
+// @Description React to a post by: id for the authenticated user, replacing any previous reaction of the user
+// @Tags        posts
+// @Accept      json
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Param       body body posts.ReactionJson true "Reaction Object"
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/reactions [post]
+func (h PostHandler) React(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	var reaction posts.ReactionJson
+	err = json.NewDecoder(r.Body).Decode(&reaction)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.React(r.Context(), authUser.ID, postId, reaction.Type)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Unreact      godoc
+// @Summary     Remove the reaction of the authenticated user to a post by: id
+// @Description Remove the reaction of the authenticated user to a post by: id, whatever its type
+// @Tags        posts
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     500
+// @Router      /posts/{id}/reactions [delete]
+func (h PostHandler) Unreact(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: delete %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.Unreact(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetReactions godoc
+// @Summary     Read a list of reactions to a post by: id, type
+// @Description Read a list of reactions to a post by: id, of every type unless one is given
+// @Tags        posts
+// @Produce     json
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Param       type query string false "reaction type"
+// @Success     200 {array} posts.Reaction
+// @Failure     400
+// @Failure     401
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/reactions [get]
+func (h PostHandler) GetReactions(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	reactions, err := h.Usecase.GetReactions(r.Context(), authUser.ID, postId, r.URL.Query().Get("type"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(reactions)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// ListReactionTypes godoc
+// @Summary          Read the list of reactions users can leave on posts
+// @Description      Read the list of reactions users can leave on posts
+// @Tags             posts
+// @Produce          json
+// @Param            Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success          200 {array} string
+// @Failure          401
+// @Failure          500
+// @Router           /posts/reactions [get]
+func (h PostHandler) ListReactionTypes(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	response, err := json.Marshal(posts.ReactionTypes())
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// GetRevisions godoc
+// @Summary     Read the edit history of a post by: id
+// @Description Read the previous versions of a post by: id, most recent first
+// @Tags        posts
+// @Produce     json
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Success     200 {array} posts.PostRevision
+// @Failure     400
+// @Failure     401
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/revisions [get]
+func (h PostHandler) GetRevisions(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	revisions, err := h.Usecase.GetRevisions(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(revisions)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// ListDrafts   godoc
+// @Summary     Read the drafts of the authenticated user
+// @Description Read the drafts and scheduled posts of the authenticated user, scheduled ones first in publishing order
+// @Tags        posts
+// @Produce     json
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success     200 {array} shared.Post
+// @Failure     401
+// @Failure     500
+// @Router      /posts/drafts [get]
+func (h PostHandler) ListDrafts(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	drafts, err := h.Usecase.GetDrafts(r.Context(), authUser.ID)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(drafts)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// Publish      godoc
+// @Summary     Publish a draft by: id
+// @Description Publish a draft by: id right away, dating it from now
+// @Tags        posts
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/publish [post]
+func (h PostHandler) Publish(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	ogPost, err := h.Usecase.GetPost(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	if authUser.ID != ogPost.User.ID {
+		err := fmt.Errorf("forbidden post publish attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	err = h.Usecase.Publish(r.Context(), postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Schedule     godoc
+// @Summary     Schedule a draft by: id
+// @Description Schedule a draft by: id to be published at the given time, replacing any previous schedule
+// @Tags        posts
+// @Accept      json
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Param       body body shared.Post true "Post Object with the scheduledAt time"
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/schedule [put]
+func (h PostHandler) Schedule(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: put %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	var post shared.Post
+	err = json.NewDecoder(r.Body).Decode(&post)
+	if err != nil || post.ScheduledAt == nil {
+		if err != nil {
+			logger.ServerLogger.Error(err.Error())
+		}
+
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	ogPost, err := h.Usecase.GetPost(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	if authUser.ID != ogPost.User.ID {
+		err := fmt.Errorf("forbidden post schedule attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	err = h.Usecase.Schedule(r.Context(), postId, *post.ScheduledAt)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Unschedule   godoc
+// @Summary     Unschedule a draft by: id
+// @Description Unschedule a draft by: id, keeping it as a draft
+// @Tags        posts
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/schedule [delete]
+func (h PostHandler) Unschedule(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: delete %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	ogPost, err := h.Usecase.GetPost(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	if authUser.ID != ogPost.User.ID {
+		err := fmt.Errorf("forbidden post unschedule attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	err = h.Usecase.Unschedule(r.Context(), postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ListDeletedPosts godoc
+// @Summary         Read the recently deleted posts of the authenticated user
+// @Description     Read the posts of the authenticated user that are in the trash and can still be restored, most recently deleted first
+// @Tags            posts
+// @Produce         json
+// @Param           Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success         200 {array} shared.Post
+// @Failure         401
+// @Failure         500
+// @Router          /posts/trash [get]
+func (h PostHandler) ListDeletedPosts(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	deleted, err := h.Usecase.GetTrash(r.Context(), authUser.ID)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(deleted)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// RestorePost  godoc
+// @Summary     Restore a deleted post by: id
+// @Description Restore a post of the authenticated user by: id from the trash
+// @Tags        posts
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/restore [post]
+func (h PostHandler) RestorePost(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.Restore(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Archive      godoc
+// @Summary     Archive a post by: id
+// @Description Archive a post by: id, hiding it from the feed and the profile of its author while keeping its likes and comments
+// @Tags        posts
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/archive [post]
+func (h PostHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	ogPost, err := h.Usecase.GetPost(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	if authUser.ID != ogPost.User.ID {
+		err := fmt.Errorf("forbidden post archive attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	err = h.Usecase.Archive(r.Context(), postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Unarchive    godoc
+// @Summary     Unarchive a post by: id
+// @Description Unarchive a post by: id, showing it again on the profile of its author
+// @Tags        posts
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/archive [delete]
+func (h PostHandler) Unarchive(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: delete %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	ogPost, err := h.Usecase.GetPost(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	if authUser.ID != ogPost.User.ID {
+		err := fmt.Errorf("forbidden post unarchive attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	err = h.Usecase.Unarchive(r.Context(), postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Pin          godoc
+// @Summary     Pin a post by: id
+// @Description Pin a post by: id to the top of the profile of its author, up to 3 posts can be pinned
+// @Tags        posts
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/pin [post]
+func (h PostHandler) Pin(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	ogPost, err := h.Usecase.GetPost(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	if authUser.ID != ogPost.User.ID {
+		err := fmt.Errorf("forbidden post pin attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	err = h.Usecase.Pin(r.Context(), postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// Unpin        godoc
+// @Summary     Unpin a post by: id
+// @Description Unpin a post by: id, showing it again among the other posts of the profile of its author
+// @Tags        posts
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/pin [delete]
+func (h PostHandler) Unpin(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: delete %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	ogPost, err := h.Usecase.GetPost(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	if authUser.ID != ogPost.User.ID {
+		err := fmt.Errorf("forbidden post unpin attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	err = h.Usecase.Unpin(r.Context(), postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// GetPoll      godoc
+// @Summary     Read the poll of a post by: id
+// @Description Read the poll attached to a post by: id, the vote counts of its options only being included once the authenticated user has voted or the poll has closed
+// @Tags        posts
+// @Produce     json
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Success     200 {object} shared.Poll
+// @Failure     400
+// @Failure     401
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/poll [get]
+func (h PostHandler) GetPoll(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	poll, err := h.Usecase.GetPoll(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(poll)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// Vote         godoc
+// @Summary     Vote in the poll of a post by: id for the authenticated user
+// @Description Vote in the poll of a post by: id for the authenticated user, picking a single option unless the poll is multiple choice. Users vote once and the poll is returned with its results
+// @Tags        posts
+// @Accept      json
+// @Produce     json
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Param       body body posts.PollVoteJson true "Vote Object"
+// @Success     200 {object} shared.Poll
+// @Failure     400
+// @Failure     401
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/poll/votes [post]
+func (h PostHandler) Vote(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	var vote posts.PollVoteJson
+	err = json.NewDecoder(r.Body).Decode(&vote)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.Vote(r.Context(), authUser.ID, postId, vote.OptionIDs)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	poll, err := h.Usecase.GetPoll(r.Context(), authUser.ID, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), postErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(poll)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// StreamVideo  godoc
+// @Summary     Stream a post video by: id, media_id
+// @Description Stream a post video by: id, media_id, supporting range requests
+// @Tags        posts
+// @Produce     video/mp4,video/webm
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Post ID" Format(uuid)
+// @Param       media_id path string true "Media ID" Format(uuid)
+// @Param       Range header string false "Byte range to read"
+// @Success     200
+// @Success     206
+// @Failure     400
+// @Failure     401
+// @Failure     404
+// @Failure     500
+// @Router      /posts/{id}/media/{media_id}/video [get]
+func (h PostHandler) StreamVideo(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	mediaId, err := uuid.Parse(chi.URLParam(r, "media_id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid media id", http.StatusBadRequest)
+		return
+	}
+
+	path, err := h.Usecase.GetVideoPath(r.Context(), authUser.ID, postId, mediaId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "video not found", http.StatusNotFound)
+		return
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "video not found", http.StatusNotFound)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// ServeContent takes care of range requests so clients can seek and stream the video
+	w.Header().Set("Content-Type", media.VideoContentType(path))
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
+
+// hasField reports whether a json object sets the given field, telling fields left out from the ones set to null
+func hasField(body []byte, field string) bool {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil {
+		return false
+	}
+
+	_, ok := fields[field]
+	return ok
+}
+
+// postErrorStatus maps the errors of the posts usecase to a response status,
+// posts the user is not allowed to read being reported as missing
+func postErrorStatus(err error) int {
+	switch err.(type) {
+	case *posts.PostNotFoundError, *posts.PollNotFoundError:
+		return http.StatusNotFound
+	case *posts.EditWindowExpiredError:
+		return http.StatusForbidden
+	case *posts.BannedImageError, *posts.InvalidReactionError, *posts.PostNotDraftError, *posts.PostIsDraftError,
+		*posts.PostIsArchivedError, *posts.TooManyPinnedPostsError, *posts.InvalidScheduleError,
+		*posts.InvalidPollOptionsError, *posts.InvalidPollCloseError, *posts.PollClosedError, *posts.InvalidVoteError, *posts.AlreadyVotedError,
+		*posts.InvalidPlaceError, *posts.ContentWarningTooLongError, *posts.InvalidCommentPolicyError, *posts.InvalidVisibilityError,
+		*posts.EmptyMediaError, *posts.TooManyMediaError, *posts.MissingDescriptionError, *posts.InvalidImageError,
+		*posts.InvalidVideoError, *posts.VideoTooLargeError, *posts.AltTextTooLongError:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// validateAltText checks the alt text of the post cover and of every media item
+func validateAltText(post shared.Post) error {
+	if _, err := posts.NormalizeAltText(post.AltText); err != nil {
+		return err
+	}
+
+	for _, item := range post.Media {
+		if _, err := posts.NormalizeAltText(item.AltText); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func decodeCursor(encodedCursor string) (time.Time, uuid.UUID, error) {
 	byt, err := base64.StdEncoding.DecodeString(encodedCursor)
 	if err != nil {
@@ -0,0 +1,434 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"y-net/internal/auth"
+	"y-net/internal/logger"
+	"y-net/internal/services/shared"
+	"y-net/internal/services/stories"
+)
+
+type StoryHandler struct {
+	Usecase stories.IStoryUsecase
+}
+
+func (h StoryHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Post("/", h.CreateStory)                   // POST /api/v1/stories - Create a new story for the authenticated user
+	r.Get("/tray", h.GetStoryTray)               // GET /api/v1/stories/tray - Read the accounts with unseen stories for the authenticated user
+	r.Get("/users/{user_id}", h.ListUserStories) // GET /api/v1/stories/users/{user_id} - Read the active stories of a user by: user_id
+
+	r.Route("/{id}", func(r chi.Router) {
+		r.Get("/", h.GetStory)            // GET /api/v1/stories/{id} - Read a single story by: id
+		r.Delete("/", h.DeleteStory)      // DELETE /api/v1/stories/{id} - Delete a single story by: id
+		r.Post("/views", h.ViewStory)     // POST /api/v1/stories/{id}/views - Mark a story by: id as seen by the authenticated user
+		r.Get("/views", h.ListStoryViews) // GET /api/v1/stories/{id}/views - Read the list of users who have seen a story by: id
+	})
+
+	return r
+}
+
+// CreateStory  godoc
+// @Summary     Create a new story
+// @Description Create a new image story for the authenticated user, expiring after 24 hours
+// @Tags        stories
+// @Accept      json
+// @Produce     json
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       body body stories.Story true "Story Object"
+// @Success     200 {object} stories.Story
+// @Failure     400
+// @Failure     401
+// @Failure     500
+// @Router      /stories [post]
+func (h StoryHandler) CreateStory(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	var story stories.Story
+	err := json.NewDecoder(r.Body).Decode(&story)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	story.User = &shared.User{ID: authUser.ID}
+
+	newStory, err := h.Usecase.Create(r.Context(), story)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), storyErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(stories.Story{ID: newStory.ID, CreatedAt: newStory.CreatedAt, ExpiresAt: newStory.ExpiresAt})
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// GetStoryTray godoc
+// @Summary     Read the story tray
+// @Description Read the authenticated user and the followed accounts with unseen stories, most recent first
+// @Tags        stories
+// @Produce     json
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Success     200 {array} stories.StoryTrayItem
+// @Failure     401
+// @Failure     500
+// @Router      /stories/tray [get]
+func (h StoryHandler) GetStoryTray(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	tray, err := h.Usecase.GetTray(r.Context(), authUser.ID)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), storyErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(tray)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// ListUserStories godoc
+// @Summary        Read the active stories of a user by: user_id
+// @Description    Read the active stories of a user by: user_id, oldest first, if the authenticated user follows them
+// @Tags           stories
+// @Produce        json
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param          user_id path string true "User ID" Format(uuid)
+// @Success        200 {array} stories.Story
+// @Failure        400
+// @Failure        401
+// @Failure        500
+// @Router         /stories/users/{user_id} [get]
+func (h StoryHandler) ListUserStories(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "user_id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	userStories, err := h.Usecase.GetUserStories(r.Context(), authUser.ID, userId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), storyErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(userStories)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// GetStory     godoc
+// @Summary     Read a single story by: id
+// @Description Read a single active story by: id
+// @Tags        stories
+// @Produce     json
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Story ID" Format(uuid)
+// @Success     200 {object} stories.Story
+// @Failure     400
+// @Failure     401
+// @Failure     404
+// @Failure     500
+// @Router      /stories/{id} [get]
+func (h StoryHandler) GetStory(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	storyId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid story id", http.StatusBadRequest)
+		return
+	}
+
+	story, err := h.Usecase.Get(r.Context(), authUser.ID, storyId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), storyErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(story)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// DeleteStory  godoc
+// @Summary     Delete a single story by: id
+// @Description Delete a single story by: id
+// @Tags        stories
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Story ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     403
+// @Failure     404
+// @Failure     500
+// @Router      /stories/{id} [delete]
+func (h StoryHandler) DeleteStory(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: delete %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	storyId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid story id", http.StatusBadRequest)
+		return
+	}
+
+	story, err := h.Usecase.Get(r.Context(), authUser.ID, storyId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), storyErrorStatus(err))
+		return
+	}
+
+	if authUser.ID != story.User.ID {
+		err := fmt.Errorf("forbidden story delete attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	err = h.Usecase.Delete(r.Context(), storyId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), storyErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ViewStory    godoc
+// @Summary     Mark a story by: id as seen
+// @Description Mark a story by: id as seen by the authenticated user
+// @Tags        stories
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       id path string true "Story ID" Format(uuid)
+// @Success     200
+// @Failure     400
+// @Failure     401
+// @Failure     404
+// @Failure     500
+// @Router      /stories/{id}/views [post]
+func (h StoryHandler) ViewStory(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	storyId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid story id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.MarkViewed(r.Context(), authUser.ID, storyId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), storyErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// ListStoryViews godoc
+// @Summary       Read the list of users who have seen a story by: id
+// @Description   Read the list of users who have seen a story by: id, only available to its author
+// @Tags          stories
+// @Produce       json
+// @Param         Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param         id path string true "Story ID" Format(uuid)
+// @Success       200 {array} stories.StoryView
+// @Failure       400
+// @Failure       401
+// @Failure       403
+// @Failure       404
+// @Failure       500
+// @Router        /stories/{id}/views [get]
+func (h StoryHandler) ListStoryViews(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	storyId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid story id", http.StatusBadRequest)
+		return
+	}
+
+	story, err := h.Usecase.Get(r.Context(), authUser.ID, storyId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), storyErrorStatus(err))
+		return
+	}
+
+	if authUser.ID != story.User.ID {
+		err := fmt.Errorf("forbidden story views read attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	views, err := h.Usecase.GetViews(r.Context(), storyId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), storyErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(views)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+func storyErrorStatus(err error) int {
+	switch err.(type) {
+	case *stories.StoryNotFoundError:
+		return http.StatusNotFound
+	default:
+		return http.StatusInternalServerError
+	}
+}
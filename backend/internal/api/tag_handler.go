@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+
+	"y-net/internal/auth"
+	"y-net/internal/logger"
+	"y-net/internal/services/posts"
+)
+
+type TagHandler struct {
+	Usecase posts.IPostUsecase
+}
+
+func (h TagHandler) Routes() chi.Router {
+	r := chi.NewRouter()
+
+	r.Get("/trending", h.GetTrendingTags) // GET /api/v1/tags/trending?hours=24&limit=10 - Read the most used tags of the last hours
+	r.Get("/{tag}/posts", h.ListTagPosts) // GET /api/v1/tags/{tag}/posts?limit=10&cursor=base64string - Read a list of posts by: tag using pagination
+
+	return r
+}
+
+// GetTrendingTags godoc
+// @Summary        Read the most used tags of the last hours
+// @Description    Read the tags used by the most posts created within the last hours
+// @Tags           tags
+// @Produce        json
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param          hours query int false "size of the time window in hours, 24 by default"
+// @Param          limit query int false "maximum number of tags, 10 by default"
+// @Success        200 {array} posts.TrendingTag
+// @Failure        400
+// @Failure        401
+// @Failure        500
+// @Router         /tags/trending [get]
+func (h TagHandler) GetTrendingTags(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	hours := 24
+	if hoursStr := r.URL.Query().Get("hours"); hoursStr != "" {
+		var err error
+		hours, err = strconv.Atoi(hoursStr)
+		if err != nil || hours <= 0 || time.Duration(hours)*time.Hour > posts.MaxTrendingWindow {
+			http.Error(w, "invalid trending hours", http.StatusBadRequest)
+			return
+		}
+	}
+
+	limit := 10
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 || limit > posts.MaxTrendingTags {
+			http.Error(w, "invalid tags limit", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tags, err := h.Usecase.GetTrendingTags(r.Context(), time.Duration(hours)*time.Hour, limit)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := json.Marshal(tags)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// ListTagPosts godoc
+// @Summary     Read a list of posts by: tag using pagination
+// @Description Read a list of posts by: tag using pagination
+// @Tags        tags
+// @Produce     json
+// @Param       Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param       tag path string true "Tag, with or without the leading #"
+// @Param       limit query int true "limit of pagination"
+// @Param       cursor query string false "cursor for pagination" Format(byte)
+// @Success     200 {array} shared.Post
+// @Failure     400
+// @Failure     401
+// @Failure     500
+// @Router      /tags/{tag}/posts [get]
+func (h TagHandler) ListTagPosts(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	tag, ok := posts.NormalizeTag(chi.URLParam(r, "tag"))
+	if !ok {
+		http.Error(w, "invalid tag", http.StatusBadRequest)
+		return
+	}
+
+	limitStr := r.URL.Query().Get("limit")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid posts limit", http.StatusBadRequest)
+		return
+	}
+
+	lastCreatedAt, lastId := time.Time{}, uuid.Nil
+	cursor := r.URL.Query().Get("cursor")
+	if cursor != "" {
+		lastCreatedAt, lastId, err = decodeCursor(cursor)
+		if err != nil {
+			logger.ServerLogger.Error(err.Error())
+
+			http.Error(w, "invalid posts cursor", http.StatusBadRequest)
+			return
+		}
+	}
+
+	tagPosts, err := h.Usecase.GetPostsByTag(r.Context(), authUser.ID, tag, limit, lastCreatedAt, lastId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := json.Marshal(tagPosts)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
@@ -26,15 +26,28 @@ func (h UserHandler) Routes() chi.Router {
 	r.Get("/search/{search_term}", h.SearchUsers) // GET /api/v1/users/search/{search_term} - Read a list of users by: search_term
 
 	r.Route("/{id}", func(r chi.Router) {
-		r.Get("/", h.GetUser)                                      // GET /api/v1/users/{id} - Read a single user by: id
-		r.Get("/posts", h.ListPostsFromUser)                       // GET /api/v1/users/{id}/posts?limit=10&cursor=base64string - Read a list of posts by: user_id using pagination
-		r.Put("/", h.UpdateUser)                                   // PUT /api/v1/users/{id} - Update a single user by: id
-		r.Delete("/", h.DeleteUser)                                // DELETE /api/v1/users/{id} - Delete a single user by: id
-		r.Post("/followers/{follower_id}", h.Follow)               // POST /api/v1/users/{id}/followers/{follower_id} - Follow a user by: id
-		r.Get("/followers", h.GetFollowers)                        // GET /api/v1/users/{id}/followers - Read a list of who follows a user by: user_id
-		r.Get("/followed", h.GetFollowed)                          // GET /api/v1/users/{id}/followed - Read a list of who a user follows by: user_id
-		r.Delete("/followers/{follower_id}", h.Unfollow)           // DELETE /api/v1/users/{id}/followers/{follower_id} - Unfollow a user by: id
-		r.Get("/followers/check/{follower_id}", h.UserFollowsUser) // GET /api/v1/users/{id}/followers/check/{follower_id} - Check if a user follows another user by: id
+		r.Get("/", h.GetUser)                                                            // GET /api/v1/users/{id} - Read a single user by: id
+		r.Get("/posts", h.ListPostsFromUser)                                             // GET /api/v1/users/{id}/posts?limit=10&cursor=base64string - Read a list of posts by: user_id using pagination
+		r.Get("/archive", h.ListArchivedPosts)                                           // GET /api/v1/users/{id}/archive?limit=10&cursor=base64string - Read a list of posts archived by: user_id using pagination
+		r.Put("/", h.UpdateUser)                                                         // PUT /api/v1/users/{id} - Update a single user by: id
+		r.Delete("/", h.DeleteUser)                                                      // DELETE /api/v1/users/{id} - Delete a single user by: id
+		r.Post("/followers/{follower_id}", h.Follow)                                     // POST /api/v1/users/{id}/followers/{follower_id} - Follow a user by: id
+		r.Get("/followers", h.GetFollowers)                                              // GET /api/v1/users/{id}/followers - Read a list of who follows a user by: user_id
+		r.Get("/followed", h.GetFollowed)                                                // GET /api/v1/users/{id}/followed - Read a list of who a user follows by: user_id
+		r.Delete("/followers/{follower_id}", h.Unfollow)                                 // DELETE /api/v1/users/{id}/followers/{follower_id} - Unfollow a user by: id
+		r.Get("/followers/check/{follower_id}", h.UserFollowsUser)                       // GET /api/v1/users/{id}/followers/check/{follower_id} - Check if a user follows another user by: id
+		r.Get("/saved", h.ListSavedPosts)                                                // GET /api/v1/users/{id}/saved?limit=10&cursor=base64string&collection_id=uuid - Read a list of posts saved by: user_id using pagination
+		r.Get("/collections", h.GetCollections)                                          // GET /api/v1/users/{id}/collections - Read the saved posts collections of a user by: user_id
+		r.Post("/collections", h.CreateCollection)                                       // POST /api/v1/users/{id}/collections - Create a new saved posts collection
+		r.Put("/collections/{collection_id}", h.UpdateCollection)                        // PUT /api/v1/users/{id}/collections/{collection_id} - Rename a saved posts collection by: collection_id
+		r.Delete("/collections/{collection_id}", h.DeleteCollection)                     // DELETE /api/v1/users/{id}/collections/{collection_id} - Delete a saved posts collection by: collection_id
+		r.Post("/collections/{collection_id}/posts/{post_id}", h.AddToCollection)        // POST /api/v1/users/{id}/collections/{collection_id}/posts/{post_id} - Add a post to a collection, saving it
+		r.Delete("/collections/{collection_id}/posts/{post_id}", h.RemoveFromCollection) // DELETE /api/v1/users/{id}/collections/{collection_id}/posts/{post_id} - Remove a post from a collection
+		r.Get("/close-friends", h.GetCloseFriends)                                       // GET /api/v1/users/{id}/close-friends - Read the close friends of a user by: user_id
+		r.Post("/close-friends/{friend_id}", h.AddCloseFriend)                           // POST /api/v1/users/{id}/close-friends/{friend_id} - Add a user to the close friends of a user by: friend_id
+		r.Delete("/close-friends/{friend_id}", h.RemoveCloseFriend)                      // DELETE /api/v1/users/{id}/close-friends/{friend_id} - Remove a user from the close friends of a user by: friend_id
+		r.Put("/location-privacy", h.UpdateLocationPrivacy)                              // PUT /api/v1/users/{id}/location-privacy - Hide or show the places of the posts of a user by: id
+		r.Put("/sensitive-media", h.UpdateSensitiveMedia)                                // PUT /api/v1/users/{id}/sensitive-media - Reveal or blur the sensitive media of other users for a user by: id
 	})
 
 	return r
@@ -130,6 +143,12 @@ func (h UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Privacy and content settings are only returned to the account owner
+	if authUser.ID != userId {
+		user.HideLocation = false
+		user.ShowSensitive = false
+	}
+
 	response, err := json.Marshal(user)
 	if err != nil {
 		logger.ServerLogger.Error(err.Error())
@@ -145,7 +164,7 @@ func (h UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 
 // ListPostsFromUser godoc
 // @Summary          Read a list of posts by: user_id using pagination
-// @Description      Read a list of posts by: user_id using pagination
+// @Description      Read a list of posts by: user_id using pagination, the first page starting with the posts pinned by the user
 // @Tags             users
 // @Produce          json
 // @Param            Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
@@ -199,7 +218,7 @@ func (h UserHandler) ListPostsFromUser(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
-		posts, err = h.Usecase.GetPostsFromUser(r.Context(), userId, limit, lastCreatedAt, lastId)
+		posts, err = h.Usecase.GetPostsFromUser(r.Context(), authUser.ID, userId, limit, lastCreatedAt, lastId)
 		if err != nil {
 			logger.ServerLogger.Error(err.Error())
 
@@ -207,7 +226,7 @@ func (h UserHandler) ListPostsFromUser(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	} else {
-		posts, err = h.Usecase.GetPostsFromUser(r.Context(), userId, limit, time.Time{}, uuid.Nil)
+		posts, err = h.Usecase.GetPostsFromUser(r.Context(), authUser.ID, userId, limit, time.Time{}, uuid.Nil)
 		if err != nil {
 			logger.ServerLogger.Error(err.Error())
 
@@ -229,6 +248,93 @@ func (h UserHandler) ListPostsFromUser(w http.ResponseWriter, r *http.Request) {
 	w.Write(response)
 }
 
+// ListArchivedPosts godoc
+// @Summary          Read a list of posts archived by: user_id using pagination
+// @Description      Read a list of posts archived by: user_id using pagination, most recent first. Only the user can read their archive
+// @Tags             users
+// @Produce          json
+// @Param            Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param            id path string true "User ID" Format(uuid)
+// @Param            limit query int true "limit of pagination"
+// @Param            cursor query string false "cursor for pagination" Format(byte)
+// @Success          200 {array} shared.Post
+// @Failure          400
+// @Failure          401
+// @Failure          403
+// @Failure          500
+// @Router           /users/{id}/archive [get]
+func (h UserHandler) ListArchivedPosts(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if authUser.ID != userId {
+		err := fmt.Errorf("forbidden archived posts read attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	limitStr := r.URL.Query().Get("limit")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid posts limit", http.StatusBadRequest)
+		return
+	}
+
+	lastCreatedAt, lastId := time.Time{}, uuid.Nil
+	cursor := r.URL.Query().Get("cursor")
+	if cursor != "" {
+		lastCreatedAt, lastId, err = decodeCursor(cursor)
+		if err != nil {
+			logger.ServerLogger.Error(err.Error())
+
+			http.Error(w, "invalid posts cursor", http.StatusBadRequest)
+			return
+		}
+	}
+
+	posts, err := h.Usecase.GetArchivedPosts(r.Context(), userId, limit, lastCreatedAt, lastId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := json.Marshal(posts)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
 // UpdateUser   godoc
 // @Summary     Update a single user by: id
 // @Description Update a single user by: id
@@ -642,3 +748,854 @@ func (h UserHandler) UserFollowsUser(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write(response)
 }
+
+// ListSavedPosts godoc
+// @Summary       Read a list of posts saved by: user_id using pagination
+// @Description   Read a list of posts saved by: user_id using pagination, most recently saved first. Only the user can read their saved posts
+// @Tags          users
+// @Produce       json
+// @Param         Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param         id path string true "User ID" Format(uuid)
+// @Param         limit query int true "limit of pagination"
+// @Param         cursor query string false "cursor for pagination, built from the savedAt and id of the last post" Format(byte)
+// @Param         collection_id query string false "only read the posts of this collection" Format(uuid)
+// @Success       200 {array} shared.Post
+// @Failure       400
+// @Failure       401
+// @Failure       403
+// @Failure       500
+// @Router        /users/{id}/saved [get]
+func (h UserHandler) ListSavedPosts(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if authUser.ID != userId {
+		err := fmt.Errorf("forbidden saved posts read attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	limitStr := r.URL.Query().Get("limit")
+	limit, err := strconv.Atoi(limitStr)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid posts limit", http.StatusBadRequest)
+		return
+	}
+
+	collectionId := uuid.Nil
+	if collectionIdStr := r.URL.Query().Get("collection_id"); collectionIdStr != "" {
+		collectionId, err = uuid.Parse(collectionIdStr)
+		if err != nil {
+			logger.ServerLogger.Error(err.Error())
+
+			http.Error(w, "invalid collection id", http.StatusBadRequest)
+			return
+		}
+	}
+
+	lastSavedAt, lastId := time.Time{}, uuid.Nil
+	cursor := r.URL.Query().Get("cursor")
+	if cursor != "" {
+		lastSavedAt, lastId, err = decodeCursor(cursor)
+		if err != nil {
+			logger.ServerLogger.Error(err.Error())
+
+			http.Error(w, "invalid posts cursor", http.StatusBadRequest)
+			return
+		}
+	}
+
+	posts, err := h.Usecase.GetSavedPosts(r.Context(), userId, collectionId, limit, lastSavedAt, lastId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := json.Marshal(posts)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// GetCollections godoc
+// @Summary       Read the saved posts collections of a user by: user_id
+// @Description   Read the saved posts collections of a user by: user_id. Only the user can read their collections
+// @Tags          users
+// @Produce       json
+// @Param         Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param         id path string true "User ID" Format(uuid)
+// @Success       200 {array} users.Collection
+// @Failure       400
+// @Failure       401
+// @Failure       403
+// @Failure       500
+// @Router        /users/{id}/collections [get]
+func (h UserHandler) GetCollections(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if authUser.ID != userId {
+		err := fmt.Errorf("forbidden collections read attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	collections, err := h.Usecase.GetCollections(r.Context(), userId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := json.Marshal(collections)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// CreateCollection godoc
+// @Summary         Create a new saved posts collection
+// @Description     Create a new saved posts collection, names being unique per user
+// @Tags            users
+// @Accept          json
+// @Produce         json
+// @Param           Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param           id path string true "User ID" Format(uuid)
+// @Param           body body users.Collection true "Collection Object"
+// @Success         200 {object} users.Collection
+// @Failure         400
+// @Failure         401
+// @Failure         403
+// @Failure         500
+// @Router          /users/{id}/collections [post]
+func (h UserHandler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if authUser.ID != userId {
+		err := fmt.Errorf("forbidden collection creation attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	var collection users.Collection
+	err = json.NewDecoder(r.Body).Decode(&collection)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	collection.UserID = userId
+
+	newCollection, err := h.Usecase.CreateCollection(r.Context(), collection)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	response, err := json.Marshal(newCollection)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// UpdateCollection godoc
+// @Summary         Rename a saved posts collection by: collection_id
+// @Description     Rename a saved posts collection by: collection_id
+// @Tags            users
+// @Accept          json
+// @Param           Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param           id path string true "User ID" Format(uuid)
+// @Param           collection_id path string true "Collection ID" Format(uuid)
+// @Param           body body users.Collection true "Collection Object"
+// @Success         200
+// @Failure         400
+// @Failure         401
+// @Failure         403
+// @Failure         404
+// @Failure         500
+// @Router          /users/{id}/collections/{collection_id} [put]
+func (h UserHandler) UpdateCollection(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: put %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if authUser.ID != userId {
+		err := fmt.Errorf("forbidden collection update attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	collectionId, err := uuid.Parse(chi.URLParam(r, "collection_id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid collection id", http.StatusBadRequest)
+		return
+	}
+
+	var collection users.Collection
+	err = json.NewDecoder(r.Body).Decode(&collection)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+	collection.UserID = userId
+
+	err = h.Usecase.UpdateCollection(r.Context(), collection, collectionId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// DeleteCollection godoc
+// @Summary         Delete a saved posts collection by: collection_id
+// @Description     Delete a saved posts collection by: collection_id, its posts staying saved
+// @Tags            users
+// @Param           Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param           id path string true "User ID" Format(uuid)
+// @Param           collection_id path string true "Collection ID" Format(uuid)
+// @Success         200
+// @Failure         400
+// @Failure         401
+// @Failure         403
+// @Failure         404
+// @Failure         500
+// @Router          /users/{id}/collections/{collection_id} [delete]
+func (h UserHandler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: delete %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if authUser.ID != userId {
+		err := fmt.Errorf("forbidden collection delete attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	collectionId, err := uuid.Parse(chi.URLParam(r, "collection_id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid collection id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.DeleteCollection(r.Context(), userId, collectionId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// AddToCollection godoc
+// @Summary        Add a post to a collection, saving it
+// @Description    Add a post to a saved posts collection by: collection_id, saving the post if it was not saved yet
+// @Tags           users
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param          id path string true "User ID" Format(uuid)
+// @Param          collection_id path string true "Collection ID" Format(uuid)
+// @Param          post_id path string true "Post ID" Format(uuid)
+// @Success        200
+// @Failure        400
+// @Failure        401
+// @Failure        403
+// @Failure        404
+// @Failure        500
+// @Router         /users/{id}/collections/{collection_id}/posts/{post_id} [post]
+func (h UserHandler) AddToCollection(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if authUser.ID != userId {
+		err := fmt.Errorf("forbidden collection post add attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	collectionId, err := uuid.Parse(chi.URLParam(r, "collection_id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid collection id", http.StatusBadRequest)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "post_id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.AddToCollection(r.Context(), userId, collectionId, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// RemoveFromCollection godoc
+// @Summary             Remove a post from a collection
+// @Description         Remove a post from a saved posts collection by: collection_id, the post staying saved
+// @Tags                users
+// @Param               Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param               id path string true "User ID" Format(uuid)
+// @Param               collection_id path string true "Collection ID" Format(uuid)
+// @Param               post_id path string true "Post ID" Format(uuid)
+// @Success             200
+// @Failure             400
+// @Failure             401
+// @Failure             403
+// @Failure             500
+// @Router              /users/{id}/collections/{collection_id}/posts/{post_id} [delete]
+func (h UserHandler) RemoveFromCollection(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: delete %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if authUser.ID != userId {
+		err := fmt.Errorf("forbidden collection post remove attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	collectionId, err := uuid.Parse(chi.URLParam(r, "collection_id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid collection id", http.StatusBadRequest)
+		return
+	}
+
+	postId, err := uuid.Parse(chi.URLParam(r, "post_id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid post id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.RemoveFromCollection(r.Context(), userId, collectionId, postId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// userErrorStatus maps the errors of collection operations to their response status
+// GetCloseFriends godoc
+// @Summary        Read the close friends of a user by: user_id
+// @Description    Read the close friends of a user by: user_id, who can read the close friends posts of the user. Only the user can read their close friends
+// @Tags           users
+// @Produce        json
+// @Param          Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param          id path string true "User ID" Format(uuid)
+// @Success        200 {array} shared.User
+// @Failure        400
+// @Failure        401
+// @Failure        403
+// @Failure        500
+// @Router         /users/{id}/close-friends [get]
+func (h UserHandler) GetCloseFriends(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: get %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if authUser.ID != userId {
+		err := fmt.Errorf("forbidden close friends read attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	friends, err := h.Usecase.GetCloseFriends(r.Context(), userId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	response, err := json.Marshal(friends)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write(response)
+}
+
+// AddCloseFriend godoc
+// @Summary       Add a user to the close friends of a user by: friend_id
+// @Description   Add a user to the close friends of a user by: friend_id
+// @Tags          users
+// @Param         Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param         id path string true "User ID" Format(uuid)
+// @Param         friend_id path string true "Friend ID" Format(uuid)
+// @Success       200
+// @Failure       400
+// @Failure       401
+// @Failure       403
+// @Failure       404
+// @Failure       500
+// @Router        /users/{id}/close-friends/{friend_id} [post]
+func (h UserHandler) AddCloseFriend(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: post %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if authUser.ID != userId {
+		err := fmt.Errorf("forbidden close friend add attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	friendId, err := uuid.Parse(chi.URLParam(r, "friend_id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid friend id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.AddCloseFriend(r.Context(), userId, friendId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// RemoveCloseFriend godoc
+// @Summary          Remove a user from the close friends of a user by: friend_id
+// @Description      Remove a user from the close friends of a user by: friend_id
+// @Tags             users
+// @Param            Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param            id path string true "User ID" Format(uuid)
+// @Param            friend_id path string true "Friend ID" Format(uuid)
+// @Success          200
+// @Failure          400
+// @Failure          401
+// @Failure          403
+// @Failure          500
+// @Router           /users/{id}/close-friends/{friend_id} [delete]
+func (h UserHandler) RemoveCloseFriend(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: delete %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if authUser.ID != userId {
+		err := fmt.Errorf("forbidden close friend remove attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	friendId, err := uuid.Parse(chi.URLParam(r, "friend_id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid friend id", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.RemoveCloseFriend(r.Context(), userId, friendId)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// UpdateLocationPrivacy godoc
+// @Summary              Hide or show the places of the posts of a user by: id
+// @Description          Hide or show the places of all the posts of a user by: id, hidden places being left out of post responses, nearby searches and place pages
+// @Tags                 users
+// @Accept               json
+// @Param                Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param                id path string true "User ID" Format(uuid)
+// @Param                body body users.LocationPrivacy true "Location Privacy Object"
+// @Success              200
+// @Failure              400
+// @Failure              401
+// @Failure              403
+// @Failure              404
+// @Failure              500
+// @Router               /users/{id}/location-privacy [put]
+func (h UserHandler) UpdateLocationPrivacy(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: put %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if authUser.ID != userId {
+		err := fmt.Errorf("forbidden location privacy update attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	var privacy users.LocationPrivacy
+	err = json.NewDecoder(r.Body).Decode(&privacy)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.SetHideLocation(r.Context(), userId, privacy.HideLocation)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// UpdateSensitiveMedia godoc
+// @Summary             Reveal or blur the sensitive media of other users for a user by: id
+// @Description         Reveal or blur the sensitive media of other users for a user by: id, sensitive posts being listed on the explore surfaces only for the users who reveal them
+// @Tags                users
+// @Accept              json
+// @Param               Authorization header string true "Insert your access token" default(Bearer <Add access token here>)
+// @Param               id path string true "User ID" Format(uuid)
+// @Param               body body users.SensitiveMedia true "Sensitive Media Object"
+// @Success             200
+// @Failure             400
+// @Failure             401
+// @Failure             403
+// @Failure             404
+// @Failure             500
+// @Router              /users/{id}/sensitive-media [put]
+func (h UserHandler) UpdateSensitiveMedia(w http.ResponseWriter, r *http.Request) {
+	logger.ServerLogger.Info(fmt.Sprintf("new request: put %s", r.URL))
+
+	authUser := auth.ForContext(r.Context())
+	if authUser == nil {
+		err := fmt.Errorf("access denied")
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	userId, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid user id", http.StatusBadRequest)
+		return
+	}
+
+	if authUser.ID != userId {
+		err := fmt.Errorf("forbidden sensitive media update attempt from user: %v", authUser.ID)
+
+		logger.ServerLogger.Warn(err.Error())
+
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+
+	var setting users.SensitiveMedia
+	err = json.NewDecoder(r.Body).Decode(&setting)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, "invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	err = h.Usecase.SetShowSensitive(r.Context(), userId, setting.ShowSensitive)
+	if err != nil {
+		logger.ServerLogger.Error(err.Error())
+
+		http.Error(w, err.Error(), userErrorStatus(err))
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+func userErrorStatus(err error) int {
+	switch err.(type) {
+	case *users.CollectionNotFoundError, *users.UserNotFoundError, *users.PostNotFoundError:
+		return http.StatusNotFound
+	case *users.CollectionAlreadyExistsError:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -41,13 +41,11 @@ func Middleware() func(http.Handler) http.Handler {
 			}
 
 			// Create user and check if user exists in db
-			user := shared.User{ID: id}
-			username, err := users.GetUsernameByUserID(r.Context(), id)
+			user, err := users.GetAuthUserByID(r.Context(), id)
 			if err != nil {
 				next.ServeHTTP(w, r)
 				return
 			}
-			user.Username = username
 			// Put it in context
 			ctx := context.WithValue(r.Context(), userCtxKey, &user)
 
@@ -66,6 +66,7 @@ CREATE TABLE IF NOT EXISTS migrations (
 
 
 -- -- Update like count
+-- -- No longer used since 000032, like counts only count hearts and are kept by the posts repository
 -- CREATE OR REPLACE FUNCTION update_like_counts()
 --     RETURNS TRIGGER AS $$
 --     BEGIN
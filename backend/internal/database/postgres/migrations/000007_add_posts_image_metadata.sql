@@ -0,0 +1,4 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS image_width int;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS image_height int;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS image_color text;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS image_blurhash text;
@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS post_media (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    post_id uuid REFERENCES posts(id) ON DELETE CASCADE,
+    position int NOT NULL,
+    image text NOT NULL,
+    alt_text text,
+    image_width int,
+    image_height int,
+    image_color text,
+    image_blurhash text,
+
+    created_at timestamp DEFAULT (NOW() AT TIME ZONE 'utc'),
+
+    UNIQUE (post_id, position)
+);
+INSERT INTO post_media (post_id, position, image, image_width, image_height, image_color, image_blurhash)
+    SELECT id, 0, image, image_width, image_height, image_color, image_blurhash FROM posts;
+ALTER TABLE posts DROP COLUMN IF EXISTS image;
+ALTER TABLE posts DROP COLUMN IF EXISTS image_width;
+ALTER TABLE posts DROP COLUMN IF EXISTS image_height;
+ALTER TABLE posts DROP COLUMN IF EXISTS image_color;
+ALTER TABLE posts DROP COLUMN IF EXISTS image_blurhash;
@@ -0,0 +1,7 @@
+ALTER TABLE post_media ADD COLUMN IF NOT EXISTS type text NOT NULL DEFAULT 'image';
+ALTER TABLE post_media ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'ready';
+ALTER TABLE post_media ADD COLUMN IF NOT EXISTS video_path text;
+ALTER TABLE post_media ADD COLUMN IF NOT EXISTS video_duration double precision;
+ALTER TABLE post_media ADD COLUMN IF NOT EXISTS processing_started_at timestamp;
+CREATE INDEX IF NOT EXISTS idx_post_media_processing ON post_media(created_at) WHERE status = 'processing';
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT 'ready';
@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_posts_description_search ON posts USING gin (to_tsvector('simple', coalesce(description, '')));
+CREATE INDEX IF NOT EXISTS idx_post_media_alt_text_search ON post_media USING gin (to_tsvector('simple', coalesce(alt_text, '')));
@@ -0,0 +1,12 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_moderator boolean NOT NULL DEFAULT false;
+ALTER TABLE post_media ADD COLUMN IF NOT EXISTS phash bigint;
+ALTER TABLE post_media ADD COLUMN IF NOT EXISTS duplicate_of uuid REFERENCES posts(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_post_media_phash ON post_media(phash);
+CREATE TABLE IF NOT EXISTS banned_images (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    hash bigint NOT NULL,
+    reason text,
+    created_by uuid REFERENCES users(id) ON DELETE SET NULL,
+
+    created_at timestamp DEFAULT (NOW() AT TIME ZONE 'utc')
+);
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS tags (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name text NOT NULL UNIQUE,
+
+    created_at timestamp DEFAULT (NOW() AT TIME ZONE 'utc')
+);
+CREATE TABLE IF NOT EXISTS post_tags (
+    post_id uuid REFERENCES posts(id) ON DELETE CASCADE,
+    tag_id uuid REFERENCES tags(id) ON DELETE CASCADE,
+
+    PRIMARY KEY (post_id, tag_id)
+);
+CREATE INDEX IF NOT EXISTS idx_post_tags_tag_id ON post_tags(tag_id);
+INSERT INTO tags (name)
+    SELECT DISTINCT lower(m[2]) FROM posts, regexp_matches(posts.description, '(^|[^[:alnum:]_&/])#([[:alnum:]_]+)', 'g') AS m
+    WHERE m[2] ~ '[[:alpha:]]' AND char_length(m[2]) <= 100
+    ON CONFLICT (name) DO NOTHING;
+INSERT INTO post_tags (post_id, tag_id)
+    SELECT DISTINCT posts.id, tags.id FROM posts, regexp_matches(posts.description, '(^|[^[:alnum:]_&/])#([[:alnum:]_]+)', 'g') AS m
+    INNER JOIN tags ON tags.name = lower(m[2])
+    ON CONFLICT DO NOTHING;
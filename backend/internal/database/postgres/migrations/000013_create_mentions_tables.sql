@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS post_mentions (
+    post_id uuid REFERENCES posts(id) ON DELETE CASCADE,
+    user_id uuid REFERENCES users(id) ON DELETE CASCADE,
+    text_offset int NOT NULL,
+    text_length int NOT NULL,
+
+    PRIMARY KEY (post_id, text_offset)
+);
+CREATE INDEX IF NOT EXISTS idx_post_mentions_user_id ON post_mentions(user_id);
+CREATE TABLE IF NOT EXISTS comment_mentions (
+    comment_id uuid REFERENCES comments(id) ON DELETE CASCADE,
+    user_id uuid REFERENCES users(id) ON DELETE CASCADE,
+    text_offset int NOT NULL,
+    text_length int NOT NULL,
+
+    PRIMARY KEY (comment_id, text_offset)
+);
+CREATE INDEX IF NOT EXISTS idx_comment_mentions_user_id ON comment_mentions(user_id);
@@ -0,0 +1,27 @@
+CREATE TABLE IF NOT EXISTS saves (
+    user_id uuid REFERENCES users(id) ON DELETE CASCADE,
+    post_id uuid REFERENCES posts(id) ON DELETE CASCADE,
+
+    created_at timestamp DEFAULT (NOW() AT TIME ZONE 'utc'),
+
+    PRIMARY KEY (user_id, post_id)
+);
+CREATE INDEX IF NOT EXISTS idx_saves_pagination ON saves(user_id, created_at, post_id);
+CREATE TABLE IF NOT EXISTS collections (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid REFERENCES users(id) ON DELETE CASCADE,
+    name text NOT NULL,
+
+    created_at timestamp DEFAULT (NOW() AT TIME ZONE 'utc'),
+
+    UNIQUE (user_id, name)
+);
+CREATE TABLE IF NOT EXISTS collection_posts (
+    collection_id uuid REFERENCES collections(id) ON DELETE CASCADE,
+    post_id uuid REFERENCES posts(id) ON DELETE CASCADE,
+
+    created_at timestamp DEFAULT (NOW() AT TIME ZONE 'utc'),
+
+    PRIMARY KEY (collection_id, post_id)
+);
+CREATE INDEX IF NOT EXISTS idx_collection_posts_post_id ON collection_posts(post_id);
@@ -0,0 +1,5 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS repost_of uuid REFERENCES posts(id) ON DELETE CASCADE;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS quote_of uuid REFERENCES posts(id) ON DELETE SET NULL;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS repost_count int DEFAULT 0;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_posts_user_repost ON posts(user_id, repost_of) WHERE repost_of IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_posts_quote_of ON posts(quote_of) WHERE quote_of IS NOT NULL;
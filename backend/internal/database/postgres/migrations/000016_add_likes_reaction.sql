@@ -0,0 +1,2 @@
+ALTER TABLE likes ADD COLUMN IF NOT EXISTS reaction varchar(32) NOT NULL DEFAULT 'heart';
+CREATE INDEX IF NOT EXISTS idx_likes_post_reaction ON likes(post_id, reaction, created_at DESC);
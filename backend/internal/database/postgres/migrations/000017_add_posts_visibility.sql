@@ -0,0 +1,10 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS visibility varchar(16) NOT NULL DEFAULT 'public';
+CREATE TABLE IF NOT EXISTS close_friends (
+    user_id uuid REFERENCES users(id) ON DELETE CASCADE,
+    friend_id uuid REFERENCES users(id) ON DELETE CASCADE,
+
+    created_at timestamp DEFAULT (NOW() AT TIME ZONE 'utc'),
+
+    PRIMARY KEY (user_id, friend_id)
+);
+CREATE INDEX IF NOT EXISTS idx_close_friends_friend_id ON close_friends(friend_id);
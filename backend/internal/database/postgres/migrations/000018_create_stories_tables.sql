@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS stories (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id uuid REFERENCES users(id) ON DELETE CASCADE,
+    image text NOT NULL,
+    image_width int,
+    image_height int,
+    image_color text,
+    image_blurhash text,
+
+    created_at timestamp DEFAULT (NOW() AT TIME ZONE 'utc'),
+    expires_at timestamp NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_stories_user_expires ON stories(user_id, expires_at);
+CREATE INDEX IF NOT EXISTS idx_stories_expires ON stories(expires_at);
+CREATE TABLE IF NOT EXISTS story_views (
+    story_id uuid REFERENCES stories(id) ON DELETE CASCADE,
+    viewer_id uuid REFERENCES users(id) ON DELETE CASCADE,
+
+    viewed_at timestamp DEFAULT (NOW() AT TIME ZONE 'utc'),
+
+    PRIMARY KEY (story_id, viewer_id)
+);
+CREATE INDEX IF NOT EXISTS idx_story_views_viewer_id ON story_views(viewer_id);
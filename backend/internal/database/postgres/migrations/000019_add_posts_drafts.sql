@@ -0,0 +1,4 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS draft boolean NOT NULL DEFAULT false;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS scheduled_at timestamp;
+CREATE INDEX IF NOT EXISTS idx_posts_drafts ON posts(user_id, created_at) WHERE draft;
+CREATE INDEX IF NOT EXISTS idx_posts_scheduled_at ON posts(scheduled_at) WHERE draft AND scheduled_at IS NOT NULL;
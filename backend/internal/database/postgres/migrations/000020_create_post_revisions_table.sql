@@ -0,0 +1,11 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS edited_at timestamp;
+CREATE TABLE IF NOT EXISTS post_revisions (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    post_id uuid REFERENCES posts(id) ON DELETE CASCADE,
+    description text,
+    visibility varchar(16) NOT NULL,
+    media jsonb NOT NULL DEFAULT '[]',
+
+    created_at timestamp DEFAULT (NOW() AT TIME ZONE 'utc')
+);
+CREATE INDEX IF NOT EXISTS idx_post_revisions_post_id ON post_revisions(post_id, created_at);
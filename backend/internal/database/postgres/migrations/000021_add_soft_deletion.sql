@@ -0,0 +1,8 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS deleted_at timestamp;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS deleted_at timestamp;
+CREATE INDEX IF NOT EXISTS idx_posts_deleted_at ON posts(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_deleted_at ON comments(user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+DROP TRIGGER IF EXISTS update_post_count_trigger ON posts;
+DROP TRIGGER IF EXISTS update_comment_count_trigger ON comments;
+UPDATE users u SET post_count = (SELECT COUNT(*) FROM posts p WHERE p.user_id = u.id AND NOT p.draft AND p.deleted_at IS NULL);
+UPDATE posts p SET comment_count = (SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id AND c.deleted_at IS NULL);
@@ -0,0 +1,3 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS archived_at timestamp;
+CREATE INDEX IF NOT EXISTS idx_posts_archived_at ON posts(user_id, created_at) WHERE archived_at IS NOT NULL;
+UPDATE users u SET post_count = (SELECT COUNT(*) FROM posts p WHERE p.user_id = u.id AND NOT p.draft AND p.deleted_at IS NULL AND p.archived_at IS NULL);
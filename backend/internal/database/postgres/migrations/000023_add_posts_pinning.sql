@@ -0,0 +1,2 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS pinned_at timestamp;
+CREATE INDEX IF NOT EXISTS idx_posts_pinned_at ON posts(user_id, pinned_at) WHERE pinned_at IS NOT NULL;
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS polls (
+    post_id uuid PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    multiple_choice boolean NOT NULL DEFAULT false,
+    closes_at timestamp NOT NULL
+);
+CREATE TABLE IF NOT EXISTS poll_options (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    post_id uuid REFERENCES polls(post_id) ON DELETE CASCADE,
+    position int NOT NULL,
+    text text NOT NULL,
+
+    UNIQUE (post_id, position)
+);
+CREATE TABLE IF NOT EXISTS poll_voters (
+    post_id uuid REFERENCES polls(post_id) ON DELETE CASCADE,
+    user_id uuid REFERENCES users(id) ON DELETE CASCADE,
+
+    voted_at timestamp DEFAULT (NOW() AT TIME ZONE 'utc'),
+
+    PRIMARY KEY (post_id, user_id)
+);
+CREATE TABLE IF NOT EXISTS poll_votes (
+    option_id uuid REFERENCES poll_options(id) ON DELETE CASCADE,
+    post_id uuid NOT NULL,
+    user_id uuid NOT NULL,
+
+    PRIMARY KEY (option_id, user_id),
+    FOREIGN KEY (post_id, user_id) REFERENCES poll_voters(post_id, user_id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_poll_votes_voter ON poll_votes(post_id, user_id);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS places (
+    id uuid PRIMARY KEY DEFAULT gen_random_uuid(),
+    name text NOT NULL,
+    latitude double precision NOT NULL,
+    longitude double precision NOT NULL,
+
+    created_at timestamp DEFAULT (NOW() AT TIME ZONE 'utc'),
+
+    UNIQUE (name, latitude, longitude)
+);
+CREATE INDEX IF NOT EXISTS idx_places_location ON places USING gist (point(longitude, latitude));
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS place_id uuid REFERENCES places(id) ON DELETE SET NULL;
+CREATE INDEX IF NOT EXISTS idx_posts_place_id ON posts(place_id) WHERE place_id IS NOT NULL;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS hide_location boolean NOT NULL DEFAULT false;
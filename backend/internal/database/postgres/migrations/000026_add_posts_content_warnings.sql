@@ -0,0 +1,4 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS content_warning text;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS sensitive boolean NOT NULL DEFAULT false;
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS flagged_sensitive boolean NOT NULL DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS show_sensitive boolean NOT NULL DEFAULT false;
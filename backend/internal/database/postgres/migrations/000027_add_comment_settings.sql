@@ -0,0 +1,5 @@
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS comment_policy varchar(16) NOT NULL DEFAULT 'everyone';
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS hidden_at timestamp;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS deleted_by uuid REFERENCES users(id) ON DELETE SET NULL;
+UPDATE comments SET deleted_by = user_id WHERE deleted_at IS NOT NULL AND deleted_by IS NULL;
+CREATE INDEX IF NOT EXISTS idx_comments_deleted_by ON comments(deleted_by, deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS parent_id uuid REFERENCES comments(id) ON DELETE CASCADE;
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS reply_count integer NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_comments_parent_id ON comments(parent_id, created_at, id) WHERE parent_id IS NOT NULL;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS comment_likes (
+    user_id uuid REFERENCES users(id) ON DELETE CASCADE,
+    comment_id uuid REFERENCES comments(id) ON DELETE CASCADE,
+
+    created_at timestamp DEFAULT (NOW() AT TIME ZONE 'utc'),
+
+    PRIMARY KEY (comment_id, user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_comment_likes_user_id ON comment_likes(user_id);
+
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS like_count integer NOT NULL DEFAULT 0;
+CREATE INDEX IF NOT EXISTS idx_comments_post_id_like_count ON comments(post_id, like_count, created_at, id) WHERE parent_id IS NULL;
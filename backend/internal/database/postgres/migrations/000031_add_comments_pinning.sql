@@ -0,0 +1,2 @@
+ALTER TABLE comments ADD COLUMN IF NOT EXISTS pinned_at timestamp;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_comments_pinned ON comments(post_id) WHERE pinned_at IS NOT NULL;
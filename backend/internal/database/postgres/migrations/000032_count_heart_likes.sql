@@ -0,0 +1,3 @@
+DROP TRIGGER IF EXISTS update_like_count_trigger ON likes;
+
+UPDATE posts p SET like_count = (SELECT COUNT(*) FROM likes l WHERE l.post_id = p.id AND l.reaction = 'heart');
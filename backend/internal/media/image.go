@@ -0,0 +1,169 @@
+package media
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"strings"
+)
+
+// ImageMetadata holds the placeholder information computed for an uploaded image
+type ImageMetadata struct {
+	Width         int
+	Height        int
+	DominantColor string
+	BlurHash      string
+	Hash          uint64
+}
+
+const (
+	blurHashXComponents = 4
+	blurHashYComponents = 3
+	thumbnailSize       = 32
+)
+
+// DecodeBase64 decodes base64 encoded media, optionally prefixed by a data URI header
+func DecodeBase64(data string) ([]byte, error) {
+	if i := strings.Index(data, ","); strings.HasPrefix(data, "data:") && i != -1 {
+		data = data[i+1:]
+	}
+
+	byt, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode base64: %w", err)
+	}
+
+	return byt, nil
+}
+
+// DecodeBase64Image decodes a base64 encoded image, optionally prefixed by a data URI header
+func DecodeBase64Image(data string) (image.Image, error) {
+	byt, err := DecodeBase64(data)
+	if err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(byt))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	return img, nil
+}
+
+// ExtractImageMetadata computes dimensions, dominant colour and blurhash of a base64 encoded image
+func ExtractImageMetadata(data string) (ImageMetadata, error) {
+	img, err := DecodeBase64Image(data)
+	if err != nil {
+		return ImageMetadata{}, err
+	}
+
+	bounds := img.Bounds()
+	thumb := thumbnail(img, thumbnailSize)
+
+	hash, err := EncodeBlurHash(thumb, blurHashXComponents, blurHashYComponents)
+	if err != nil {
+		return ImageMetadata{}, err
+	}
+
+	return ImageMetadata{
+		Width:         bounds.Dx(),
+		Height:        bounds.Dy(),
+		DominantColor: dominantColor(thumb),
+		BlurHash:      hash,
+		Hash:          DifferenceHash(thumb),
+	}, nil
+}
+
+// thumbnail downscales an image so that its largest side is at most maxSize
+func thumbnail(img image.Image, maxSize int) *image.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	tw, th := w, h
+	if w > maxSize || h > maxSize {
+		if w >= h {
+			tw, th = maxSize, max(1, h*maxSize/w)
+		} else {
+			tw, th = max(1, w*maxSize/h), maxSize
+		}
+	}
+
+	return resize(img, tw, th)
+}
+
+// resize scales an image to the given size, averaging each box of source pixels
+func resize(img image.Image, tw int, th int) *image.RGBA {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+
+	thumb := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for ty := 0; ty < th; ty++ {
+		y0 := bounds.Min.Y + ty*h/th
+		y1 := max(y0+1, bounds.Min.Y+(ty+1)*h/th)
+		for tx := 0; tx < tw; tx++ {
+			x0 := bounds.Min.X + tx*w/tw
+			x1 := max(x0+1, bounds.Min.X+(tx+1)*w/tw)
+
+			var r, g, b, a, n uint64
+			for y := y0; y < y1; y++ {
+				for x := x0; x < x1; x++ {
+					cr, cg, cb, ca := img.At(x, y).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+
+			thumb.SetRGBA(tx, ty, color.RGBA{
+				R: uint8(r / n >> 8),
+				G: uint8(g / n >> 8),
+				B: uint8(b / n >> 8),
+				A: uint8(a / n >> 8),
+			})
+		}
+	}
+
+	return thumb
+}
+
+// dominantColor returns the most common colour of an image as a hex string, bucketing similar colours together
+func dominantColor(img *image.RGBA) string {
+	type bucket struct {
+		r, g, b, n int
+	}
+	buckets := make(map[int]*bucket)
+
+	var best *bucket
+	bounds := img.Bounds()
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := img.RGBAAt(x, y)
+			key := int(c.R>>4)<<8 | int(c.G>>4)<<4 | int(c.B>>4)
+
+			bk, exists := buckets[key]
+			if !exists {
+				bk = &bucket{}
+				buckets[key] = bk
+			}
+			bk.r += int(c.R)
+			bk.g += int(c.G)
+			bk.b += int(c.B)
+			bk.n++
+
+			if best == nil || bk.n > best.n {
+				best = bk
+			}
+		}
+	}
+
+	if best == nil {
+		return "#000000"
+	}
+
+	return fmt.Sprintf("#%02x%02x%02x", best.r/best.n, best.g/best.n, best.b/best.n)
+}
@@ -0,0 +1,137 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"os/exec"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func encodeTestImage(t *testing.T, img image.Image) string {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, img)
+	assert.NoError(t, err)
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func solidImage(w, h int, c color.Color) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, c)
+		}
+	}
+
+	return img
+}
+
+func TestExtractImageMetadata(t *testing.T) {
+	data := encodeTestImage(t, solidImage(120, 80, color.RGBA{R: 255, A: 255}))
+
+	meta, err := ExtractImageMetadata(data)
+	assert.NoError(t, err)
+	assert.Equal(t, 120, meta.Width)
+	assert.Equal(t, 80, meta.Height)
+	assert.Equal(t, "#ff0000", meta.DominantColor)
+	assert.Len(t, meta.BlurHash, 4+2*blurHashXComponents*blurHashYComponents)
+}
+
+func TestExtractImageMetadataDataURI(t *testing.T) {
+	data := "data:image/png;base64," + encodeTestImage(t, solidImage(10, 10, color.White))
+
+	meta, err := ExtractImageMetadata(data)
+	assert.NoError(t, err)
+	assert.Equal(t, 10, meta.Width)
+}
+
+func TestExtractImageMetadataInvalid(t *testing.T) {
+	_, err := ExtractImageMetadata("not an image")
+	assert.Error(t, err)
+}
+
+func TestEncodeBlurHashSolidColor(t *testing.T) {
+	hash, err := EncodeBlurHash(solidImage(16, 16, color.RGBA{R: 255, G: 255, B: 255, A: 255}), 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "00TSUA", hash)
+}
+
+func TestSniffVideo(t *testing.T) {
+	mp4 := append([]byte{0x00, 0x00, 0x00, 0x18}, []byte("ftypisom\x00\x00\x02\x00")...)
+	ext, err := SniffVideo(mp4)
+	assert.NoError(t, err)
+	assert.Equal(t, ".mp4", ext)
+
+	webm := append([]byte{0x1A, 0x45, 0xDF, 0xA3, 0x9F, 0x42, 0x86, 0x81, 0x01, 0x42, 0x82, 0x84}, []byte("webm")...)
+	ext, err = SniffVideo(webm)
+	assert.NoError(t, err)
+	assert.Equal(t, ".webm", ext)
+}
+
+func TestSniffVideoUnsupported(t *testing.T) {
+	heic := append([]byte{0x00, 0x00, 0x00, 0x18}, []byte("ftypheic\x00\x00\x00\x00")...)
+	_, err := SniffVideo(heic)
+	assert.Error(t, err)
+
+	_, err = SniffVideo([]byte("not a video"))
+	assert.Error(t, err)
+}
+
+func TestToolError(t *testing.T) {
+	exitErr := exec.Command("false").Run()
+	assert.Error(t, exitErr)
+
+	err := toolError(context.Background(), fmt.Errorf("failed to probe video: %w", exitErr))
+	assert.IsType(t, &UnreadableVideoError{}, err)
+
+	// Missing binaries and cancelled runs are not the fault of the video
+	err = toolError(context.Background(), fmt.Errorf("failed to probe video: %w", exec.ErrNotFound))
+	_, unreadable := err.(*UnreadableVideoError)
+	assert.False(t, unreadable)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = toolError(ctx, fmt.Errorf("failed to probe video: %w", exitErr))
+	_, unreadable = err.(*UnreadableVideoError)
+	assert.False(t, unreadable)
+}
+
+func gradientImage(w, h int, reverse bool) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			v := uint8(x * 255 / w)
+			if reverse {
+				v = 255 - v
+			}
+			img.Set(x, y, color.RGBA{R: v, G: v, B: v, A: 255})
+		}
+	}
+
+	return img
+}
+
+func TestDifferenceHash(t *testing.T) {
+	original := DifferenceHash(gradientImage(300, 200, false))
+	resized := DifferenceHash(gradientImage(90, 60, false))
+	different := DifferenceHash(gradientImage(300, 200, true))
+
+	assert.LessOrEqual(t, HammingDistance(original, resized), 2)
+	assert.Greater(t, HammingDistance(original, different), 32)
+}
+
+func TestParseHash(t *testing.T) {
+	hash, err := ParseHash(FormatHash(0xf0f0f0f00f0f0f0f))
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0xf0f0f0f00f0f0f0f), hash)
+
+	_, err = ParseHash("not a hash")
+	assert.Error(t, err)
+}
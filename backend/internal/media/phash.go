@@ -0,0 +1,50 @@
+package media
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"math/bits"
+	"strconv"
+)
+
+// DifferenceHash computes a 64 bit perceptual hash (dHash) by comparing the brightness of neighbouring pixels,
+// so that resized or recompressed copies of an image end up with the same or a very close hash
+func DifferenceHash(img image.Image) uint64 {
+	small := resize(img, 9, 8)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			if luminance(small.RGBAAt(x, y)) < luminance(small.RGBAAt(x+1, y)) {
+				hash |= 1 << uint(y*8+x)
+			}
+		}
+	}
+
+	return hash
+}
+
+// HammingDistance returns the number of differing bits between two perceptual hashes
+func HammingDistance(a uint64, b uint64) int {
+	return bits.OnesCount64(a ^ b)
+}
+
+// FormatHash encodes a perceptual hash as a 16 character hex string
+func FormatHash(hash uint64) string {
+	return fmt.Sprintf("%016x", hash)
+}
+
+// ParseHash decodes a perceptual hash from its hex string form
+func ParseHash(s string) (uint64, error) {
+	hash, err := strconv.ParseUint(s, 16, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid image hash: %w", err)
+	}
+
+	return hash, nil
+}
+
+func luminance(c color.RGBA) int {
+	return 299*int(c.R) + 587*int(c.G) + 114*int(c.B)
+}
@@ -0,0 +1,86 @@
+package media
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/uuid"
+
+	"y-net/internal/utils"
+)
+
+// mediaDir returns the folder uploaded media files are stored in, creating it if needed
+func mediaDir() (string, error) {
+	dir := os.Getenv("MEDIA_DIR")
+	if dir == "" {
+		rootDir, err := utils.FindProjectRoot()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(rootDir, "media")
+	}
+
+	err := os.MkdirAll(dir, 0755)
+	if err != nil {
+		return "", fmt.Errorf("failed to create media folder: %w", err)
+	}
+
+	return dir, nil
+}
+
+// SaveFile stores media data under a new unique name with the given extension and returns that name
+func SaveFile(data []byte, ext string) (string, error) {
+	name := NewFileName(ext)
+	err := WriteFile(name, data)
+	if err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// NewFileName returns a new unique name for a media file with the given extension
+func NewFileName(ext string) string {
+	return uuid.NewString() + ext
+}
+
+// WriteFile stores media data under the given name
+func WriteFile(name string, data []byte) error {
+	path, err := FilePath(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.WriteFile(path, data, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to save media file: %w", err)
+	}
+
+	return nil
+}
+
+// FilePath returns the full path of a stored media file by: name
+func FilePath(name string) (string, error) {
+	dir, err := mediaDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, filepath.Base(name)), nil
+}
+
+// RemoveFile deletes a stored media file by: name, ignoring files that are already gone
+func RemoveFile(name string) error {
+	path, err := FilePath(name)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove media file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,143 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+)
+
+const (
+	MaxVideoSize     = 50 << 20 // 50 MB
+	MaxVideoDuration = 60.0     // seconds
+)
+
+// Brands of the ISO base media file format that are MP4 videos, other brands such as heic or avif are images
+var mp4Brands = [][]byte{
+	[]byte("isom"), []byte("iso2"), []byte("iso4"), []byte("iso5"), []byte("iso6"),
+	[]byte("mp41"), []byte("mp42"), []byte("avc1"), []byte("M4V "), []byte("dash"),
+}
+
+// UnreadableVideoError reports a video file that ffprobe or ffmpeg ran on but could not read,
+// as opposed to the tools being missing or interrupted
+type UnreadableVideoError struct {
+	err error
+}
+
+func (e *UnreadableVideoError) Error() string {
+	return e.err.Error()
+}
+
+func (e *UnreadableVideoError) Unwrap() error {
+	return e.err
+}
+
+type VideoMetadata struct {
+	Duration float64
+	Width    int
+	Height   int
+}
+
+// SniffVideo checks the container of a video by its leading bytes and returns the matching file extension
+func SniffVideo(data []byte) (string, error) {
+	if len(data) >= 12 && bytes.Equal(data[4:8], []byte("ftyp")) {
+		for _, brand := range mp4Brands {
+			if bytes.Equal(data[8:12], brand) {
+				return ".mp4", nil
+			}
+		}
+	}
+
+	// WebM is a matroska EBML document with the "webm" doc type in its header
+	if len(data) >= 4 && bytes.Equal(data[:4], []byte{0x1A, 0x45, 0xDF, 0xA3}) {
+		if bytes.Contains(data[:min(len(data), 64)], []byte("webm")) {
+			return ".webm", nil
+		}
+	}
+
+	return "", fmt.Errorf("unsupported video format, only mp4 and webm are allowed")
+}
+
+// VideoContentType returns the mime type of a stored video by: name
+func VideoContentType(name string) string {
+	if filepath.Ext(name) == ".webm" {
+		return "video/webm"
+	}
+
+	return "video/mp4"
+}
+
+// ProbeVideo reads the duration and dimensions of a video file using ffprobe
+func ProbeVideo(ctx context.Context, path string) (VideoMetadata, error) {
+	out, err := exec.CommandContext(
+		ctx,
+		"ffprobe", "-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "stream=width,height:format=duration",
+		"-of", "json",
+		path,
+	).Output()
+	if err != nil {
+		return VideoMetadata{}, toolError(ctx, fmt.Errorf("failed to probe video: %w", err))
+	}
+
+	var probe struct {
+		Streams []struct {
+			Width  int `json:"width"`
+			Height int `json:"height"`
+		} `json:"streams"`
+		Format struct {
+			Duration string `json:"duration"`
+		} `json:"format"`
+	}
+	err = json.Unmarshal(out, &probe)
+	if err != nil {
+		return VideoMetadata{}, fmt.Errorf("failed to parse video probe: %w", err)
+	}
+	if len(probe.Streams) == 0 {
+		return VideoMetadata{}, &UnreadableVideoError{err: fmt.Errorf("video has no video stream")}
+	}
+
+	duration, err := strconv.ParseFloat(probe.Format.Duration, 64)
+	if err != nil {
+		return VideoMetadata{}, &UnreadableVideoError{err: fmt.Errorf("invalid video duration: %w", err)}
+	}
+
+	return VideoMetadata{
+		Duration: duration,
+		Width:    probe.Streams[0].Width,
+		Height:   probe.Streams[0].Height,
+	}, nil
+}
+
+// ExtractPosterFrame returns the first frame of a video file as a jpeg image using ffmpeg
+func ExtractPosterFrame(ctx context.Context, path string) ([]byte, error) {
+	out, err := exec.CommandContext(
+		ctx,
+		"ffmpeg", "-v", "error",
+		"-i", path,
+		"-frames:v", "1",
+		"-f", "image2", "-c:v", "mjpeg",
+		"pipe:1",
+	).Output()
+	if err != nil {
+		return nil, toolError(ctx, fmt.Errorf("failed to extract poster frame: %w", err))
+	}
+
+	return out, nil
+}
+
+// toolError reports the failure of ffprobe or ffmpeg as an unreadable video when the tool ran to completion and
+// exited with an error, missing binaries and cancelled runs being left as they are so that they can be retried
+func toolError(ctx context.Context, err error) error {
+	var exitErr *exec.ExitError
+	if ctx.Err() == nil && errors.As(err, &exitErr) {
+		return &UnreadableVideoError{err: err}
+	}
+
+	return err
+}
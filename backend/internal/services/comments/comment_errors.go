@@ -0,0 +1,19 @@
+package comments
+
+type PostNotFoundError struct{}
+
+func (m *PostNotFoundError) Error() string {
+	return "post not found"
+}
+
+type CommentNotAllowedError struct{}
+
+func (m *CommentNotAllowedError) Error() string {
+	return "the author of the post does not allow this user to comment on it"
+}
+
+type CommentNotFoundError struct{}
+
+func (m *CommentNotFoundError) Error() string {
+	return "comment not found"
+}
@@ -0,0 +1,45 @@
+package comments
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"y-net/internal/logger"
+	"y-net/internal/services/shared"
+)
+
+// Number of deleted comments purged per transaction
+const purgeBatchSize = 500
+
+// StartTrashPurge permanently removes the comments that have been in the trash for longer than shared.TrashRetention,
+// polling every interval until ctx is cancelled
+func StartTrashPurge(ctx context.Context, interval time.Duration) {
+	repository := &commentRepositoryImpl{}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Drain every expired comment before waiting for the next tick
+		for {
+			purged, err := repository.purgeDeleted(ctx, shared.TrashRetention, purgeBatchSize)
+			if err != nil {
+				logger.ServerLogger.Error(err.Error())
+				break
+			}
+			if purged > 0 {
+				logger.ServerLogger.Info(fmt.Sprintf("purged %d deleted comments", purged))
+			}
+			if purged < purgeBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
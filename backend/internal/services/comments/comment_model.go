@@ -7,10 +7,42 @@ import (
 	"github.com/google/uuid"
 )
 
+// Page size of the comments of a post when none is asked for, and the largest one allowed
+const (
+	DefaultCommentsLimit = 20
+	MaxCommentsLimit     = 100
+)
+
+// Orders of the comments of a post: newest first, oldest first, or most liked first
+const (
+	CommentsOrderNewest = "desc"
+	CommentsOrderOldest = "asc"
+	CommentsOrderTop    = "top"
+)
+
+func IsCommentsOrder(order string) bool {
+	switch order {
+	case CommentsOrderNewest, CommentsOrderOldest, CommentsOrderTop:
+		return true
+	default:
+		return false
+	}
+}
+
 type Comment struct {
-	ID        uuid.UUID    `json:"id,omitempty"`
-	User      *shared.User `json:"user,omitempty"`
-	PostID    uuid.UUID    `json:"postId,omitempty"`
-	Message   string       `json:"message,omitempty"`
-	CreatedAt time.Time    `json:"createdAt,omitempty"`
+	ID          uuid.UUID        `json:"id,omitempty"`
+	User        *shared.User     `json:"user,omitempty"`
+	PostID      uuid.UUID        `json:"postId,omitempty"`
+	PostOwnerID uuid.UUID        `json:"-"`
+	ParentID    *uuid.UUID       `json:"parentId,omitempty"`
+	Message     string           `json:"message,omitempty"`
+	Mentions    []shared.Mention `json:"mentions,omitempty"`
+	Hidden      bool             `json:"hidden,omitempty"`
+	Pinned      bool             `json:"pinned,omitempty"`
+	IsAuthor    bool             `json:"isAuthor,omitempty"`
+	ReplyCount  int              `json:"replyCount,omitempty"`
+	LikeCount   int              `json:"likeCount,omitempty"`
+	Replies     []Comment        `json:"replies,omitempty"`
+	CreatedAt   time.Time        `json:"createdAt,omitempty"`
+	DeletedAt   *time.Time       `json:"deletedAt,omitempty"`
 }
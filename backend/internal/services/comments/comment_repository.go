@@ -3,6 +3,7 @@ package comments
 import (
 	"context"
 	"fmt"
+	"time"
 	database "y-net/internal/database/postgres"
 	"y-net/internal/services/shared"
 
@@ -12,14 +13,39 @@ import (
 
 type iCommentRepository interface {
 	create(ctx context.Context, comment Comment) (Comment, error)
-	getFromPost(ctx context.Context, postId uuid.UUID) ([]Comment, error)
+	getFromPost(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID, limit int, order string, lastLikeCount int, lastCreatedAt time.Time, lastId uuid.UUID) ([]Comment, error)
+	getReplies(ctx context.Context, viewerId uuid.UUID, id uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]Comment, error)
 	get(ctx context.Context, id uuid.UUID) (Comment, error)
 	update(ctx context.Context, comment Comment, id uuid.UUID) error
-	delete(ctx context.Context, id uuid.UUID) error
+	delete(ctx context.Context, userId uuid.UUID, id uuid.UUID) error
+	setHidden(ctx context.Context, id uuid.UUID, hidden bool) error
+	setPinned(ctx context.Context, id uuid.UUID, pinned bool) error
+	getUserIdsByUsernames(ctx context.Context, usernames []string) (map[string]uuid.UUID, error)
+	getTrash(ctx context.Context, userId uuid.UUID) ([]Comment, error)
+	restore(ctx context.Context, userId uuid.UUID, id uuid.UUID) error
+	like(ctx context.Context, userId uuid.UUID, id uuid.UUID) error
+	getLikes(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) ([]shared.User, error)
+	unlike(ctx context.Context, userId uuid.UUID, id uuid.UUID) error
+	userLikedComment(ctx context.Context, userId uuid.UUID, id uuid.UUID) (bool, error)
 }
 
 type commentRepositoryImpl struct{}
 
+// Number of replies returned along with each top-level comment of a post
+const previewReplies = 3
+
+// commentColumns are the columns read by scanComment. Queries using them read from comments c joined by commentJoins,
+// comments written by the author of their post p being flagged as such
+const commentColumns = `c.id, c.user_id, u.username, u.avatar, c.post_id, c.parent_id, c.message, c.reply_count, c.like_count, c.hidden_at IS NOT NULL,
+	c.pinned_at IS NOT NULL, c.user_id = p.user_id, c.created_at, c.deleted_at`
+
+const commentJoins = `INNER JOIN users u ON c.user_id = u.id
+	INNER JOIN posts p ON p.id = c.post_id`
+
+// shownToViewer leaves out the comments c hidden by the author of their post p, unless the viewer bound to $2
+// wrote the comment or the post
+const shownToViewer = `(c.hidden_at IS NULL OR c.user_id = $2 OR p.user_id = $2)`
+
 func (r *commentRepositoryImpl) create(ctx context.Context, comment Comment) (Comment, error) {
 	tx, err := database.Postgres.Begin(ctx)
 	if err != nil {
@@ -30,20 +56,68 @@ func (r *commentRepositoryImpl) create(ctx context.Context, comment Comment) (Co
 		database.HandleTransaction(ctx, tx, err)
 	}()
 
+	err = checkPostVisible(ctx, tx, comment.User.ID, comment.PostID)
+	if err != nil {
+		return Comment{}, err
+	}
+
+	err = checkCommentAllowed(ctx, tx, comment.User.ID, comment.PostID)
+	if err != nil {
+		return Comment{}, err
+	}
+
+	// Threads are a single level deep, replying to a reply adding to the thread of its parent
+	var parentId *uuid.UUID
+	if comment.ParentID != nil {
+		err = tx.QueryRow(
+			ctx,
+			"SELECT COALESCE(parent_id, id) FROM comments WHERE id = $1 AND post_id = $2 AND deleted_at IS NULL",
+			*comment.ParentID, comment.PostID,
+		).Scan(&parentId)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				err = &CommentNotFoundError{}
+				return Comment{}, err
+			}
+
+			return Comment{}, fmt.Errorf("failed to select parent comment: %w", err)
+		}
+	}
+
 	var newComment Comment
 	err = tx.QueryRow(
 		ctx,
-		"INSERT INTO comments (user_id, post_id, message) VALUES ($1, $2, $3) RETURNING id, created_at",
-		comment.User.ID, comment.PostID, comment.Message,
+		"INSERT INTO comments (user_id, post_id, parent_id, message) VALUES ($1, $2, $3, $4) RETURNING id, created_at",
+		comment.User.ID, comment.PostID, parentId, comment.Message,
 	).Scan(&newComment.ID, &newComment.CreatedAt)
 	if err != nil {
 		return Comment{}, fmt.Errorf("failed to insert comment: %w", err)
 	}
+	newComment.ParentID = parentId
+
+	err = updateCommentCount(ctx, tx, comment.PostID)
+	if err != nil {
+		return Comment{}, err
+	}
+
+	err = updateReplyCount(ctx, tx, parentId)
+	if err != nil {
+		return Comment{}, err
+	}
+
+	err = syncCommentMentions(ctx, tx, newComment.ID, comment.Mentions)
+	if err != nil {
+		return Comment{}, err
+	}
+	newComment.Mentions = comment.Mentions
 
 	return newComment, nil
 }
 
-func (r *commentRepositoryImpl) getFromPost(ctx context.Context, postId uuid.UUID) ([]Comment, error) {
+// getFromPost lists a page of the top-level comments of a post in the given order.
+// The page starts after the comment given by lastCreatedAt and lastId in that order, top comments also being given
+// the like count the last comment had when it was read. The first page is led by the pinned comment of the post on top of the limit
+func (r *commentRepositoryImpl) getFromPost(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID, limit int, order string, lastLikeCount int, lastCreatedAt time.Time, lastId uuid.UUID) ([]Comment, error) {
 	tx, err := database.Postgres.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -53,21 +127,266 @@ func (r *commentRepositoryImpl) getFromPost(ctx context.Context, postId uuid.UUI
 		database.HandleTransaction(ctx, tx, err)
 	}()
 
+	err = checkPostVisible(ctx, tx, viewerId, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	// The cursor of the top comments carries the like count of the last comment read, so that pages do not shift
+	// when that comment is liked in between
+	var orderBy, after string
+	cursor := []interface{}{lastCreatedAt, lastId}
+	switch order {
+	case CommentsOrderOldest:
+		orderBy = "c.created_at, c.id"
+		after = "(c.created_at, c.id) > ($%[1]d, $%[2]d)"
+	case CommentsOrderTop:
+		orderBy = "c.like_count DESC, c.created_at DESC, c.id DESC"
+		after = "(c.like_count, c.created_at, c.id) < ($%[3]d, $%[1]d, $%[2]d)"
+		cursor = append(cursor, lastLikeCount)
+	default:
+		orderBy = "c.created_at DESC, c.id DESC"
+		after = "(c.created_at, c.id) < ($%[1]d, $%[2]d)"
+	}
+
+	// Deleted comments are kept as tombstones while they have replies, so that their threads stay together
 	query := `
-		SELECT c.id, c.user_id, u.username, u.avatar, c.post_id, c.message, c.created_at
+		SELECT ` + commentColumns + `
 		FROM comments c
-		INNER JOIN users u ON c.user_id = u.id
-		WHERE c.post_id = $1
-		ORDER BY c.created_at DESC
+		` + commentJoins + `
+		WHERE c.post_id = $1 AND c.parent_id IS NULL AND c.pinned_at IS NULL AND ` + shownToViewer + `
+		AND (c.deleted_at IS NULL OR EXISTS (SELECT 1 FROM comments r WHERE r.parent_id = c.id AND r.deleted_at IS NULL))
 	`
+	args := []interface{}{postId, viewerId}
+
+	if !lastCreatedAt.IsZero() || lastId != uuid.Nil {
+		first := len(args) + 1
+		args = append(args, cursor...)
+		query += "AND " + fmt.Sprintf(after, first, first+1, first+2)
+	}
+
+	args = append(args, limit)
+	query += fmt.Sprintf(`
+		ORDER BY %s
+		LIMIT $%d
+	`, orderBy, len(args))
+
+	comments, err := selectComments(ctx, tx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastCreatedAt.IsZero() && lastId == uuid.Nil {
+		var pinned []Comment
+		pinned, err = selectComments(
+			ctx, tx,
+			`SELECT `+commentColumns+`
+			FROM comments c
+			`+commentJoins+`
+			WHERE c.post_id = $1 AND c.pinned_at IS NOT NULL AND c.deleted_at IS NULL AND `+shownToViewer,
+			postId, viewerId,
+		)
+		if err != nil {
+			return nil, err
+		}
+		comments = append(pinned, comments...)
+	}
 
-	rows, err := tx.Query(ctx, query, postId)
+	ids := make([]uuid.UUID, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+
+	replies, err := selectReplyPreviews(ctx, tx, viewerId, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range comments {
+		comments[i].Replies = replies[comments[i].ID]
+	}
+
+	return comments, nil
+}
+
+// getReplies lists the replies to a comment, oldest first
+func (r *commentRepositoryImpl) getReplies(ctx context.Context, viewerId uuid.UUID, id uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]Comment, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	var postId uuid.UUID
+	err = tx.QueryRow(ctx, "SELECT post_id FROM comments WHERE id = $1 AND parent_id IS NULL", id).Scan(&postId)
 	if err != nil {
 		if err == pgx.ErrNoRows {
-			return []Comment{}, nil
+			err = &CommentNotFoundError{}
+			return nil, err
 		}
 
-		return nil, fmt.Errorf("failed to select comments: %w", err)
+		return nil, fmt.Errorf("failed to select comment: %w", err)
+	}
+
+	err = checkPostVisible(ctx, tx, viewerId, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	var query string
+	var args []interface{}
+
+	if lastCreatedAt.IsZero() && lastId == uuid.Nil {
+		query = `
+			SELECT ` + commentColumns + `
+			FROM comments c
+			` + commentJoins + `
+			WHERE c.parent_id = $1 AND c.deleted_at IS NULL AND ` + shownToViewer + `
+			ORDER BY c.created_at, c.id
+			LIMIT $3
+		`
+		args = append(args, id, viewerId, limit)
+	} else {
+		query = `
+			SELECT ` + commentColumns + `
+			FROM comments c
+			` + commentJoins + `
+			WHERE c.parent_id = $1 AND c.deleted_at IS NULL AND ` + shownToViewer + `
+			AND (c.created_at > $3 OR (c.created_at = $3 AND c.id > $4))
+			ORDER BY c.created_at, c.id
+			LIMIT $5
+		`
+		args = append(args, id, viewerId, lastCreatedAt, lastId, limit)
+	}
+
+	replies, err := selectComments(ctx, tx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return replies, nil
+}
+
+func (r *commentRepositoryImpl) get(ctx context.Context, id uuid.UUID) (Comment, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return Comment{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	var comment Comment
+	comment.User = &shared.User{}
+	err = tx.QueryRow(
+		ctx,
+		"SELECT c.id, c.user_id, c.post_id, p.user_id, c.hidden_at IS NOT NULL FROM comments c INNER JOIN posts p ON p.id = c.post_id WHERE c.id = $1 AND c.deleted_at IS NULL",
+		id).Scan(&comment.ID, &comment.User.ID, &comment.PostID, &comment.PostOwnerID, &comment.Hidden)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = &CommentNotFoundError{}
+			return Comment{}, err
+		}
+
+		return Comment{}, fmt.Errorf("failed to scan comment: %w", err)
+	}
+
+	return comment, nil
+}
+
+func (r *commentRepositoryImpl) update(ctx context.Context, comment Comment, id uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	_, err = tx.Exec(
+		ctx,
+		"UPDATE comments SET message = $1 WHERE id = $2 AND deleted_at IS NULL",
+		comment.Message, id,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+
+	err = syncCommentMentions(ctx, tx, id, comment.Mentions)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// delete moves a comment to the trash of the user deleting it, either its author or the author of the post
+func (r *commentRepositoryImpl) delete(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	// Comments are moved to the trash, from where they can be restored until they are purged
+	var postId uuid.UUID
+	var parentId *uuid.UUID
+	err = tx.QueryRow(
+		ctx,
+		"UPDATE comments SET deleted_at = (NOW() AT TIME ZONE 'utc'), deleted_by = $2, pinned_at = NULL WHERE id = $1 AND deleted_at IS NULL RETURNING post_id, parent_id",
+		id, userId,
+	).Scan(&postId, &parentId)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = nil
+			return nil
+		}
+
+		return fmt.Errorf("failed to execute query: %w", err)
+	}
+
+	err = updateCommentCount(ctx, tx, postId)
+	if err != nil {
+		return err
+	}
+
+	err = updateReplyCount(ctx, tx, parentId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *commentRepositoryImpl) getTrash(ctx context.Context, userId uuid.UUID) ([]Comment, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	query := `
+		SELECT c.id, c.user_id, u.username, u.avatar, c.post_id, c.message, c.created_at, c.deleted_at
+		FROM comments c
+		INNER JOIN users u ON c.user_id = u.id
+		WHERE c.deleted_by = $1 AND c.deleted_at >= (NOW() AT TIME ZONE 'utc') - make_interval(secs => $2::float8)
+		ORDER BY c.deleted_at DESC
+	`
+
+	rows, err := tx.Query(ctx, query, userId, shared.TrashRetention.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to select deleted comments: %w", err)
 	}
 	defer rows.Close()
 
@@ -75,7 +394,7 @@ func (r *commentRepositoryImpl) getFromPost(ctx context.Context, postId uuid.UUI
 	for rows.Next() {
 		var comment Comment
 		comment.User = &shared.User{}
-		err := rows.Scan(&comment.ID, &comment.User.ID, &comment.User.Username, &comment.User.Avatar, &comment.PostID, &comment.Message, &comment.CreatedAt)
+		err := rows.Scan(&comment.ID, &comment.User.ID, &comment.User.Username, &comment.User.Avatar, &comment.PostID, &comment.Message, &comment.CreatedAt, &comment.DeletedAt)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan comment: %w", err)
 		}
@@ -88,30 +407,48 @@ func (r *commentRepositoryImpl) getFromPost(ctx context.Context, postId uuid.UUI
 	return comments, nil
 }
 
-func (r *commentRepositoryImpl) get(ctx context.Context, id uuid.UUID) (Comment, error) {
+func (r *commentRepositoryImpl) restore(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
 	tx, err := database.Postgres.Begin(ctx)
 	if err != nil {
-		return Comment{}, fmt.Errorf("failed to begin transaction: %w", err)
+		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
 	defer func() {
 		database.HandleTransaction(ctx, tx, err)
 	}()
 
-	var comment Comment
-	comment.User = &shared.User{}
+	var postId uuid.UUID
+	var parentId *uuid.UUID
 	err = tx.QueryRow(
 		ctx,
-		"SELECT id, user_id, post_id FROM comments WHERE id = $1",
-		id).Scan(&comment.ID, &comment.User.ID, &comment.PostID)
+		`UPDATE comments SET deleted_at = NULL, deleted_by = NULL
+		WHERE id = $1 AND deleted_by = $2 AND deleted_at >= (NOW() AT TIME ZONE 'utc') - make_interval(secs => $3::float8)
+		RETURNING post_id, parent_id`,
+		id, userId, shared.TrashRetention.Seconds(),
+	).Scan(&postId, &parentId)
 	if err != nil {
-		return Comment{}, fmt.Errorf("failed to scan comment: %w", err)
+		if err == pgx.ErrNoRows {
+			err = &CommentNotFoundError{}
+			return err
+		}
+
+		return fmt.Errorf("failed to restore comment: %w", err)
 	}
 
-	return comment, nil
+	err = updateCommentCount(ctx, tx, postId)
+	if err != nil {
+		return err
+	}
+
+	err = updateReplyCount(ctx, tx, parentId)
+	if err != nil {
+		return err
+	}
+
+	return nil
 }
 
-func (r *commentRepositoryImpl) update(ctx context.Context, comment Comment, id uuid.UUID) error {
+func (r *commentRepositoryImpl) setHidden(ctx context.Context, id uuid.UUID, hidden bool) error {
 	tx, err := database.Postgres.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -121,19 +458,24 @@ func (r *commentRepositoryImpl) update(ctx context.Context, comment Comment, id
 		database.HandleTransaction(ctx, tx, err)
 	}()
 
-	_, err = tx.Exec(
+	tag, err := tx.Exec(
 		ctx,
-		"UPDATE comments SET message = $1 WHERE id = $1",
-		comment.Message, id,
+		"UPDATE comments SET hidden_at = CASE WHEN $2 THEN COALESCE(hidden_at, (NOW() AT TIME ZONE 'utc')) END WHERE id = $1 AND deleted_at IS NULL",
+		id, hidden,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update comment: %w", err)
 	}
+	if tag.RowsAffected() == 0 {
+		err = &CommentNotFoundError{}
+		return err
+	}
 
 	return nil
 }
 
-func (r *commentRepositoryImpl) delete(ctx context.Context, id uuid.UUID) error {
+// setPinned pins a top-level comment to the top of its post, in place of the comment pinned before it, or unpins it
+func (r *commentRepositoryImpl) setPinned(ctx context.Context, id uuid.UUID, pinned bool) error {
 	tx, err := database.Postgres.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -143,10 +485,423 @@ func (r *commentRepositoryImpl) delete(ctx context.Context, id uuid.UUID) error
 		database.HandleTransaction(ctx, tx, err)
 	}()
 
-	_, err = tx.Exec(ctx, "DELETE FROM comments WHERE id = $1", id)
+	if pinned {
+		_, err = tx.Exec(
+			ctx,
+			"UPDATE comments SET pinned_at = NULL WHERE post_id = (SELECT post_id FROM comments WHERE id = $1) AND pinned_at IS NOT NULL AND id <> $1",
+			id,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to unpin comments: %w", err)
+		}
+	}
+
+	tag, err := tx.Exec(
+		ctx,
+		"UPDATE comments SET pinned_at = CASE WHEN $2 THEN COALESCE(pinned_at, (NOW() AT TIME ZONE 'utc')) END WHERE id = $1 AND parent_id IS NULL AND deleted_at IS NULL",
+		id, pinned,
+	)
 	if err != nil {
-		return fmt.Errorf("failed to execute query: %w", err)
+		return fmt.Errorf("failed to update comment: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		err = &CommentNotFoundError{}
+		return err
 	}
 
 	return nil
 }
+
+// purgeDeleted permanently removes up to limit comments that have been in the trash for longer than the retention,
+// returning how many were removed. Comments with replies are kept as tombstones until their replies are removed
+func (r *commentRepositoryImpl) purgeDeleted(ctx context.Context, retention time.Duration, limit int) (int, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	tag, err := tx.Exec(
+		ctx,
+		`DELETE FROM comments WHERE id IN (
+			SELECT id FROM comments
+			WHERE deleted_at < (NOW() AT TIME ZONE 'utc') - make_interval(secs => $1::float8)
+			AND NOT EXISTS (SELECT 1 FROM comments r WHERE r.parent_id = comments.id)
+			ORDER BY deleted_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)`,
+		retention.Seconds(), limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge comments: %w", err)
+	}
+
+	return int(tag.RowsAffected()), nil
+}
+
+// like records the like of a user on a comment of a post they can read, counting it only once
+func (r *commentRepositoryImpl) like(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	err = checkCommentVisible(ctx, tx, userId, id)
+	if err != nil {
+		return err
+	}
+
+	tag, err := tx.Exec(ctx, "INSERT INTO comment_likes (user_id, comment_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", userId, id)
+	if err != nil {
+		return fmt.Errorf("failed to insert comment like: %w", err)
+	}
+
+	err = updateLikeCount(ctx, tx, id, int(tag.RowsAffected()))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *commentRepositoryImpl) getLikes(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) ([]shared.User, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	err = checkCommentVisible(ctx, tx, viewerId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(
+		ctx,
+		"SELECT u.id, u.username, u.full_name, u.avatar FROM comment_likes l JOIN users u ON l.user_id = u.id WHERE l.comment_id = $1 ORDER BY l.created_at DESC",
+		id,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select comment likes: %w", err)
+	}
+	defer rows.Close()
+
+	var userLikes []shared.User
+	for rows.Next() {
+		var user shared.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.FullName, &user.Avatar); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		userLikes = append(userLikes, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return userLikes, nil
+}
+
+func (r *commentRepositoryImpl) unlike(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	tag, err := tx.Exec(ctx, "DELETE FROM comment_likes WHERE user_id = $1 AND comment_id = $2", userId, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment like: %w", err)
+	}
+
+	err = updateLikeCount(ctx, tx, id, -int(tag.RowsAffected()))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *commentRepositoryImpl) userLikedComment(ctx context.Context, userId uuid.UUID, id uuid.UUID) (bool, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	var exists bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM comment_likes WHERE user_id = $1 AND comment_id = $2)", userId, id).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if user liked comment: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *commentRepositoryImpl) getUserIdsByUsernames(ctx context.Context, usernames []string) (map[string]uuid.UUID, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	userIds, err := shared.GetUserIdsByUsernames(ctx, tx, usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	return userIds, nil
+}
+
+// updateCommentCount recounts the comments of a post that are not in the trash
+func updateCommentCount(ctx context.Context, tx pgx.Tx, postId uuid.UUID) error {
+	_, err := tx.Exec(
+		ctx,
+		"UPDATE posts p SET comment_count = (SELECT COUNT(*) FROM comments c WHERE c.post_id = p.id AND c.deleted_at IS NULL) WHERE p.id = $1",
+		postId,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update comment count: %w", err)
+	}
+
+	return nil
+}
+
+// updateReplyCount recounts the replies of a comment that are not in the trash, if any
+func updateReplyCount(ctx context.Context, tx pgx.Tx, parentId *uuid.UUID) error {
+	if parentId == nil {
+		return nil
+	}
+
+	_, err := tx.Exec(
+		ctx,
+		"UPDATE comments c SET reply_count = (SELECT COUNT(*) FROM comments r WHERE r.parent_id = c.id AND r.deleted_at IS NULL) WHERE c.id = $1",
+		*parentId,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update reply count: %w", err)
+	}
+
+	return nil
+}
+
+// updateLikeCount adds the given difference to the like count of a comment
+func updateLikeCount(ctx context.Context, tx pgx.Tx, id uuid.UUID, difference int) error {
+	if difference == 0 {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, "UPDATE comments SET like_count = GREATEST(like_count + $2, 0) WHERE id = $1", id, difference)
+	if err != nil {
+		return fmt.Errorf("failed to update like count: %w", err)
+	}
+
+	return nil
+}
+
+// selectComments reads the comments selected by the given query with commentColumns along with their mentions.
+// Deleted comments are returned as tombstones, without their author and message
+func selectComments(ctx context.Context, tx pgx.Tx, query string, args ...interface{}) ([]Comment, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select comments: %w", err)
+	}
+	defer rows.Close()
+
+	var comments []Comment
+	for rows.Next() {
+		var comment Comment
+		comment.User = &shared.User{}
+		err := rows.Scan(&comment.ID, &comment.User.ID, &comment.User.Username, &comment.User.Avatar, &comment.PostID, &comment.ParentID, &comment.Message, &comment.ReplyCount, &comment.LikeCount, &comment.Hidden, &comment.Pinned, &comment.IsAuthor, &comment.CreatedAt, &comment.DeletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan comment: %w", err)
+		}
+		if comment.DeletedAt != nil {
+			comment.User = nil
+			comment.Message = ""
+			comment.IsAuthor = false
+		}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	ids := make([]uuid.UUID, len(comments))
+	for i, comment := range comments {
+		ids[i] = comment.ID
+	}
+
+	mentions, err := selectCommentMentions(ctx, tx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range comments {
+		if comments[i].DeletedAt == nil {
+			comments[i].Mentions = mentions[comments[i].ID]
+		}
+	}
+
+	return comments, nil
+}
+
+// selectReplyPreviews reads the oldest replies to each of the given comments shown to the viewer, grouped by parent id
+func selectReplyPreviews(ctx context.Context, tx pgx.Tx, viewerId uuid.UUID, parentIds []uuid.UUID) (map[uuid.UUID][]Comment, error) {
+	query := `
+		SELECT ` + commentColumns + `
+		FROM (
+			SELECT c.*, row_number() OVER (PARTITION BY c.parent_id ORDER BY c.created_at, c.id) AS position
+			FROM comments c
+			INNER JOIN posts p ON p.id = c.post_id
+			WHERE c.parent_id = ANY($1) AND c.deleted_at IS NULL AND ` + shownToViewer + `
+		) c
+		` + commentJoins + `
+		WHERE c.position <= $3
+		ORDER BY c.parent_id, c.created_at, c.id
+	`
+
+	replies, err := selectComments(ctx, tx, query, parentIds, viewerId, previewReplies)
+	if err != nil {
+		return nil, err
+	}
+
+	previews := make(map[uuid.UUID][]Comment)
+	for _, reply := range replies {
+		previews[*reply.ParentID] = append(previews[*reply.ParentID], reply)
+	}
+
+	return previews, nil
+}
+
+// checkPostVisible reports a post the viewer can not read as missing, so that its comments are hidden along with it
+func checkPostVisible(ctx context.Context, tx pgx.Tx, viewerId uuid.UUID, postId uuid.UUID) error {
+	var visible bool
+	err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM posts p WHERE p.id = $2 AND "+shared.PostVisibleTo("p", "$1")+")", viewerId, postId).Scan(&visible)
+	if err != nil {
+		return fmt.Errorf("failed to check post visibility: %w", err)
+	}
+	if !visible {
+		return &PostNotFoundError{}
+	}
+
+	return nil
+}
+
+// checkCommentVisible reports a comment as missing when it is in the trash, hidden from the viewer or on a post
+// they can not read
+func checkCommentVisible(ctx context.Context, tx pgx.Tx, viewerId uuid.UUID, id uuid.UUID) error {
+	var postId uuid.UUID
+	err := tx.QueryRow(
+		ctx,
+		"SELECT c.post_id FROM comments c INNER JOIN posts p ON p.id = c.post_id WHERE c.id = $1 AND c.deleted_at IS NULL AND "+shownToViewer,
+		id, viewerId,
+	).Scan(&postId)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return &CommentNotFoundError{}
+		}
+
+		return fmt.Errorf("failed to select comment: %w", err)
+	}
+
+	return checkPostVisible(ctx, tx, viewerId, postId)
+}
+
+// checkCommentAllowed enforces the comment policy of a post, its author being always allowed to comment
+func checkCommentAllowed(ctx context.Context, tx pgx.Tx, userId uuid.UUID, postId uuid.UUID) error {
+	var allowed bool
+	err := tx.QueryRow(
+		ctx,
+		`SELECT p.user_id = $1 OR CASE p.comment_policy
+			WHEN 'everyone' THEN true
+			WHEN 'followers' THEN EXISTS (SELECT 1 FROM followers f WHERE f.follower_id = $1 AND f.followed_id = p.user_id)
+			WHEN 'mentioned' THEN EXISTS (SELECT 1 FROM post_mentions pm WHERE pm.post_id = p.id AND pm.user_id = $1)
+			ELSE false
+		END
+		FROM posts p WHERE p.id = $2`,
+		userId, postId,
+	).Scan(&allowed)
+	if err != nil {
+		return fmt.Errorf("failed to check comment policy: %w", err)
+	}
+	if !allowed {
+		return &CommentNotAllowedError{}
+	}
+
+	return nil
+}
+
+// syncCommentMentions replaces the resolved mentions of a comment message
+func syncCommentMentions(ctx context.Context, tx pgx.Tx, commentId uuid.UUID, mentions []shared.Mention) error {
+	_, err := tx.Exec(ctx, "DELETE FROM comment_mentions WHERE comment_id = $1", commentId)
+	if err != nil {
+		return fmt.Errorf("failed to delete comment mentions: %w", err)
+	}
+
+	for _, mention := range mentions {
+		_, err = tx.Exec(
+			ctx,
+			"INSERT INTO comment_mentions (comment_id, user_id, text_offset, text_length) VALUES ($1, $2, $3, $4)",
+			commentId, mention.UserID, mention.Offset, mention.Length,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert comment mention: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// selectCommentMentions reads the mentions of the given comments, grouped by comment id.
+// Usernames are read from the mentioned users so links keep working after a rename
+func selectCommentMentions(ctx context.Context, tx pgx.Tx, commentIds []uuid.UUID) (map[uuid.UUID][]shared.Mention, error) {
+	rows, err := tx.Query(
+		ctx,
+		`SELECT cm.comment_id, cm.user_id, u.username, cm.text_offset, cm.text_length
+		FROM comment_mentions cm
+		INNER JOIN users u ON u.id = cm.user_id
+		WHERE cm.comment_id = ANY($1)
+		ORDER BY cm.comment_id, cm.text_offset`,
+		commentIds,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select comment mentions: %w", err)
+	}
+	defer rows.Close()
+
+	mentions := make(map[uuid.UUID][]shared.Mention)
+	for rows.Next() {
+		var commentId uuid.UUID
+		var mention shared.Mention
+		err := rows.Scan(&commentId, &mention.UserID, &mention.Username, &mention.Offset, &mention.Length)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan comment mention: %w", err)
+		}
+		mentions[commentId] = append(mentions[commentId], mention)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return mentions, nil
+}
@@ -3,7 +3,9 @@ package comments
 import (
 	"context"
 	"fmt"
+	"sort"
 	"testing"
+	"time"
 	"y-net/internal/services/shared"
 
 	"github.com/google/uuid"
@@ -75,6 +77,27 @@ func TestUpdateComment(t *testing.T) {
 	assert.Equal(t, "Updated comment.", updatedComment.Message)
 }
 
+func TestUpdateCommentKeepsOtherComments(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	postId := uuid.New()
+	first, _ := ts.usecase.Create(context.Background(), Comment{User: &user, PostID: postId, Message: "First comment."})
+	second, _ := ts.usecase.Create(context.Background(), Comment{User: &user, PostID: postId, Message: "Second comment."})
+
+	first.Message = "Updated comment."
+	err := ts.usecase.Update(context.Background(), first, first.ID)
+	assert.NoError(t, err)
+
+	updatedComment, err := ts.usecase.Get(context.Background(), first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Updated comment.", updatedComment.Message)
+
+	otherComment, err := ts.usecase.Get(context.Background(), second.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Second comment.", otherComment.Message)
+}
+
 func TestUpdateCommentNotFound(t *testing.T) {
 	ts := setup()
 
@@ -94,10 +117,10 @@ func TestDeleteComment(t *testing.T) {
 	comment := Comment{User: &user, PostID: postId, Message: "This is a comment."}
 	createdComment, _ := ts.usecase.Create(context.Background(), comment)
 
-	err := ts.usecase.Delete(context.Background(), createdComment.ID)
+	err := ts.usecase.Delete(context.Background(), user.ID, createdComment.ID)
 	assert.NoError(t, err)
 
-	comments, err := ts.usecase.GetFromPost(context.Background(), createdComment.PostID)
+	comments, err := ts.usecase.GetFromPost(context.Background(), user.ID, createdComment.PostID, DefaultCommentsLimit, CommentsOrderNewest, 0, time.Time{}, uuid.Nil)
 	assert.NoError(t, err)
 	assert.NotContains(t, comments, createdComment)
 }
@@ -107,11 +130,51 @@ func TestDeleteCommentNotFound(t *testing.T) {
 
 	id := uuid.New()
 
-	err := ts.usecase.Delete(context.Background(), id)
+	err := ts.usecase.Delete(context.Background(), uuid.New(), id)
 	assert.Error(t, err)
 	assert.Equal(t, "comment not found", err.Error())
 }
 
+func TestTrashRestoreComment(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	createdComment, err := ts.usecase.Create(context.Background(), Comment{User: &user, PostID: uuid.New(), Message: "This is a comment."})
+	assert.NoError(t, err)
+
+	err = ts.usecase.Delete(context.Background(), user.ID, createdComment.ID)
+	assert.NoError(t, err)
+
+	_, err = ts.usecase.Get(context.Background(), createdComment.ID)
+	assert.IsType(t, &CommentNotFoundError{}, err)
+
+	trash, err := ts.usecase.GetTrash(context.Background(), user.ID)
+	assert.NoError(t, err)
+	assert.Len(t, trash, 1)
+
+	// Only the author can restore the comment
+	err = ts.usecase.Restore(context.Background(), uuid.New(), createdComment.ID)
+	assert.IsType(t, &CommentNotFoundError{}, err)
+
+	err = ts.usecase.Restore(context.Background(), user.ID, createdComment.ID)
+	assert.NoError(t, err)
+
+	comments, err := ts.usecase.GetFromPost(context.Background(), user.ID, createdComment.PostID, DefaultCommentsLimit, CommentsOrderNewest, 0, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 1)
+
+	// Comments deleted for longer than the retention can no longer be restored
+	err = ts.usecase.Delete(context.Background(), user.ID, createdComment.ID)
+	assert.NoError(t, err)
+	comment := ts.repo.comments[createdComment.ID]
+	deletedAt := time.Now().Add(-shared.TrashRetention - time.Hour)
+	comment.DeletedAt = &deletedAt
+	ts.repo.comments[createdComment.ID] = comment
+
+	err = ts.usecase.Restore(context.Background(), user.ID, createdComment.ID)
+	assert.IsType(t, &CommentNotFoundError{}, err)
+}
+
 func TestGetFromPost(t *testing.T) {
 	ts := setup()
 
@@ -121,9 +184,190 @@ func TestGetFromPost(t *testing.T) {
 	_, _ = ts.usecase.Create(context.Background(), comment1)
 	_, _ = ts.usecase.Create(context.Background(), comment2)
 
-	comments, err := ts.usecase.GetFromPost(context.Background(), postID)
+	comments, err := ts.usecase.GetFromPost(context.Background(), uuid.New(), postID, DefaultCommentsLimit, CommentsOrderNewest, 0, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 2)
+}
+
+func TestGetFromPostPagination(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	postId := uuid.New()
+	var created []Comment
+	for i := 0; i < 5; i++ {
+		comment, err := ts.usecase.Create(context.Background(), Comment{User: &user, PostID: postId, Message: fmt.Sprintf("Comment %d", i)})
+		assert.NoError(t, err)
+		comment.CreatedAt = time.Now().Add(time.Duration(i) * time.Minute)
+		stored := ts.repo.comments[comment.ID]
+		stored.CreatedAt = comment.CreatedAt
+		ts.repo.comments[comment.ID] = stored
+		created = append(created, comment)
+	}
+
+	for _, order := range []string{CommentsOrderNewest, CommentsOrderOldest} {
+		var ids []uuid.UUID
+		lastCreatedAt, lastId := time.Time{}, uuid.Nil
+		for {
+			page, err := ts.usecase.GetFromPost(context.Background(), user.ID, postId, 2, order, 0, lastCreatedAt, lastId)
+			assert.NoError(t, err)
+			if len(page) == 0 {
+				break
+			}
+			assert.LessOrEqual(t, len(page), 2)
+
+			for _, comment := range page {
+				ids = append(ids, comment.ID)
+			}
+			last := page[len(page)-1]
+			lastCreatedAt, lastId = last.CreatedAt, last.ID
+		}
+
+		assert.Len(t, ids, len(created))
+		for i, id := range ids {
+			expected := created[len(created)-1-i].ID
+			if order == CommentsOrderOldest {
+				expected = created[i].ID
+			}
+			assert.Equal(t, expected, id)
+		}
+	}
+}
+
+func TestLikeComment(t *testing.T) {
+	ts := setup()
+
+	author := shared.User{ID: uuid.New(), Username: "author"}
+	fan := shared.User{ID: uuid.New(), Username: "fan"}
+	postId := uuid.New()
+	createdComment, err := ts.usecase.Create(context.Background(), Comment{User: &author, PostID: postId, Message: "A comment"})
+	assert.NoError(t, err)
+
+	// Liking a comment twice counts once
+	for i := 0; i < 2; i++ {
+		err = ts.usecase.Like(context.Background(), fan.ID, createdComment.ID)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 1, ts.repo.comments[createdComment.ID].LikeCount)
+
+	liked, err := ts.usecase.UserLikedComment(context.Background(), fan.ID, createdComment.ID)
+	assert.NoError(t, err)
+	assert.True(t, liked)
+
+	likes, err := ts.usecase.GetLikes(context.Background(), author.ID, createdComment.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []shared.User{{ID: fan.ID}}, likes)
+
+	for i := 0; i < 2; i++ {
+		err = ts.usecase.Unlike(context.Background(), fan.ID, createdComment.ID)
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 0, ts.repo.comments[createdComment.ID].LikeCount)
+
+	liked, err = ts.usecase.UserLikedComment(context.Background(), fan.ID, createdComment.ID)
+	assert.NoError(t, err)
+	assert.False(t, liked)
+
+	err = ts.usecase.Like(context.Background(), fan.ID, uuid.New())
+	assert.IsType(t, &CommentNotFoundError{}, err)
+}
+
+func TestGetFromPostTop(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	postId := uuid.New()
+	var created []Comment
+	for i := 0; i < 3; i++ {
+		comment, err := ts.usecase.Create(context.Background(), Comment{User: &user, PostID: postId, Message: fmt.Sprintf("Comment %d", i)})
+		assert.NoError(t, err)
+		created = append(created, comment)
+	}
+
+	// The oldest comment gets the most likes and the newest one none
+	for i, comment := range created[:2] {
+		for j := 0; j < 2-i; j++ {
+			err := ts.usecase.Like(context.Background(), uuid.New(), comment.ID)
+			assert.NoError(t, err)
+		}
+	}
+
+	page, err := ts.usecase.GetFromPost(context.Background(), user.ID, postId, 2, CommentsOrderTop, 0, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, page, 2)
+	assert.Equal(t, created[0].ID, page[0].ID)
+	assert.Equal(t, 2, page[0].LikeCount)
+	assert.Equal(t, created[1].ID, page[1].ID)
+
+	// The next page starts from the like count read with the previous one, likes given in between not shifting it
+	err = ts.usecase.Like(context.Background(), uuid.New(), page[1].ID)
+	assert.NoError(t, err)
+
+	page, err = ts.usecase.GetFromPost(context.Background(), user.ID, postId, 2, CommentsOrderTop, page[1].LikeCount, page[1].CreatedAt, page[1].ID)
+	assert.NoError(t, err)
+	assert.Len(t, page, 1)
+	assert.Equal(t, created[2].ID, page[0].ID)
+}
+
+func TestPinComment(t *testing.T) {
+	ts := setup()
+
+	author := shared.User{ID: uuid.New(), Username: "author"}
+	commenter := shared.User{ID: uuid.New(), Username: "commenter"}
+	postId := uuid.New()
+	ts.repo.posts[postId] = shared.Post{User: &author}
+
+	first, err := ts.usecase.Create(context.Background(), Comment{User: &commenter, PostID: postId, Message: "First comment"})
+	assert.NoError(t, err)
+	second, err := ts.usecase.Create(context.Background(), Comment{User: &author, PostID: postId, Message: "Second comment"})
+	assert.NoError(t, err)
+	reply, err := ts.usecase.Create(context.Background(), Comment{User: &author, PostID: postId, ParentID: &first.ID, Message: "A reply"})
+	assert.NoError(t, err)
+
+	err = ts.usecase.Pin(context.Background(), first.ID)
+	assert.NoError(t, err)
+
+	// The pinned comment leads the first page only, on top of the limit
+	comments, err := ts.usecase.GetFromPost(context.Background(), commenter.ID, postId, 1, CommentsOrderNewest, 0, time.Time{}, uuid.Nil)
 	assert.NoError(t, err)
 	assert.Len(t, comments, 2)
+	assert.Equal(t, first.ID, comments[0].ID)
+	assert.True(t, comments[0].Pinned)
+	assert.False(t, comments[0].IsAuthor)
+	assert.True(t, comments[0].Replies[0].IsAuthor)
+	assert.Equal(t, second.ID, comments[1].ID)
+	assert.True(t, comments[1].IsAuthor)
+
+	comments, err = ts.usecase.GetFromPost(context.Background(), commenter.ID, postId, 1, CommentsOrderNewest, 0, comments[1].CreatedAt, comments[1].ID)
+	assert.NoError(t, err)
+	assert.Empty(t, comments)
+
+	// Pinning another comment replaces the pinned one
+	err = ts.usecase.Pin(context.Background(), second.ID)
+	assert.NoError(t, err)
+	assert.False(t, ts.repo.comments[first.ID].Pinned)
+
+	err = ts.usecase.Unpin(context.Background(), second.ID)
+	assert.NoError(t, err)
+	assert.False(t, ts.repo.comments[second.ID].Pinned)
+
+	// Replies can not be pinned
+	err = ts.usecase.Pin(context.Background(), reply.ID)
+	assert.IsType(t, &CommentNotFoundError{}, err)
+}
+
+func TestGetFromPostHidden(t *testing.T) {
+	ts := setup()
+
+	postID := uuid.New()
+	ts.repo.hidden[postID] = true
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	_, err := ts.usecase.Create(context.Background(), Comment{User: &user, PostID: postID, Message: "Hidden comment"})
+	assert.IsType(t, &PostNotFoundError{}, err)
+
+	_, err = ts.usecase.GetFromPost(context.Background(), user.ID, postID, DefaultCommentsLimit, CommentsOrderNewest, 0, time.Time{}, uuid.Nil)
+	assert.IsType(t, &PostNotFoundError{}, err)
 }
 
 func TestGetComment(t *testing.T) {
@@ -148,27 +392,263 @@ func TestGetCommentNotFound(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestCreateCommentMentions(t *testing.T) {
+	ts := setup()
+
+	aliceId := uuid.New()
+	bobId := uuid.New()
+	ts.repo.users["alice"] = aliceId
+	ts.repo.users["bob.smith"] = bobId
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	comment := Comment{User: &user, PostID: uuid.New(), Message: "🎉 @alice, @bob.smith. @alice"}
+
+	createdComment, err := ts.usecase.Create(context.Background(), comment)
+	assert.NoError(t, err)
+	assert.Equal(t, []shared.Mention{
+		{UserID: aliceId, Username: "alice", Offset: 3, Length: 6},
+		{UserID: bobId, Username: "bob.smith", Offset: 11, Length: 10},
+		{UserID: aliceId, Username: "alice", Offset: 23, Length: 6},
+	}, createdComment.Mentions)
+}
+
+func TestCommentPolicy(t *testing.T) {
+	ts := setup()
+
+	author := shared.User{ID: uuid.New(), Username: "author"}
+	follower := shared.User{ID: uuid.New(), Username: "follower"}
+	mentioned := shared.User{ID: uuid.New(), Username: "mentioned"}
+	stranger := shared.User{ID: uuid.New(), Username: "stranger"}
+	ts.repo.followed[follower.ID] = []uuid.UUID{author.ID}
+
+	tests := []struct {
+		policy  string
+		allowed []shared.User
+		denied  []shared.User
+	}{
+		{shared.CommentPolicyEveryone, []shared.User{author, follower, mentioned, stranger}, nil},
+		{shared.CommentPolicyFollowers, []shared.User{author, follower}, []shared.User{mentioned, stranger}},
+		{shared.CommentPolicyMentioned, []shared.User{author, mentioned}, []shared.User{follower, stranger}},
+		{shared.CommentPolicyOff, []shared.User{author}, []shared.User{follower, mentioned, stranger}},
+	}
+
+	for _, test := range tests {
+		postId := uuid.New()
+		ts.repo.posts[postId] = shared.Post{User: &author, CommentPolicy: test.policy, Mentions: []shared.Mention{{UserID: mentioned.ID}}}
+
+		for _, user := range test.allowed {
+			_, err := ts.usecase.Create(context.Background(), Comment{User: &user, PostID: postId, Message: "A comment"})
+			assert.NoError(t, err, "%s commenting on a post open to %s", user.Username, test.policy)
+		}
+		for _, user := range test.denied {
+			_, err := ts.usecase.Create(context.Background(), Comment{User: &user, PostID: postId, Message: "A comment"})
+			assert.IsType(t, &CommentNotAllowedError{}, err, "%s commenting on a post open to %s", user.Username, test.policy)
+		}
+	}
+}
+
+func TestHideComment(t *testing.T) {
+	ts := setup()
+
+	author := shared.User{ID: uuid.New(), Username: "author"}
+	commenter := shared.User{ID: uuid.New(), Username: "commenter"}
+	postId := uuid.New()
+	ts.repo.posts[postId] = shared.Post{User: &author}
+
+	createdComment, err := ts.usecase.Create(context.Background(), Comment{User: &commenter, PostID: postId, Message: "A comment"})
+	assert.NoError(t, err)
+
+	err = ts.usecase.Hide(context.Background(), createdComment.ID)
+	assert.NoError(t, err)
+
+	// Hidden comments are only shown to their author and to the author of the post
+	comments, err := ts.usecase.GetFromPost(context.Background(), uuid.New(), postId, DefaultCommentsLimit, CommentsOrderNewest, 0, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Empty(t, comments)
+
+	for _, viewerId := range []uuid.UUID{author.ID, commenter.ID} {
+		comments, err = ts.usecase.GetFromPost(context.Background(), viewerId, postId, DefaultCommentsLimit, CommentsOrderNewest, 0, time.Time{}, uuid.Nil)
+		assert.NoError(t, err)
+		assert.Len(t, comments, 1)
+		assert.True(t, comments[0].Hidden)
+	}
+
+	err = ts.usecase.Unhide(context.Background(), createdComment.ID)
+	assert.NoError(t, err)
+
+	comments, err = ts.usecase.GetFromPost(context.Background(), uuid.New(), postId, DefaultCommentsLimit, CommentsOrderNewest, 0, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 1)
+
+	err = ts.usecase.Hide(context.Background(), uuid.New())
+	assert.IsType(t, &CommentNotFoundError{}, err)
+}
+
+func TestDeleteCommentByPostOwner(t *testing.T) {
+	ts := setup()
+
+	author := shared.User{ID: uuid.New(), Username: "author"}
+	commenter := shared.User{ID: uuid.New(), Username: "commenter"}
+	postId := uuid.New()
+	ts.repo.posts[postId] = shared.Post{User: &author}
+
+	createdComment, err := ts.usecase.Create(context.Background(), Comment{User: &commenter, PostID: postId, Message: "A comment"})
+	assert.NoError(t, err)
+
+	comment, err := ts.usecase.Get(context.Background(), createdComment.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, author.ID, comment.PostOwnerID)
+
+	err = ts.usecase.Delete(context.Background(), author.ID, createdComment.ID)
+	assert.NoError(t, err)
+
+	// Comments deleted by the author of the post go to their trash and cannot be restored by the commenter
+	trash, err := ts.usecase.GetTrash(context.Background(), commenter.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, trash)
+
+	err = ts.usecase.Restore(context.Background(), commenter.ID, createdComment.ID)
+	assert.IsType(t, &CommentNotFoundError{}, err)
+
+	trash, err = ts.usecase.GetTrash(context.Background(), author.ID)
+	assert.NoError(t, err)
+	assert.Len(t, trash, 1)
+}
+
+func TestReplies(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	postId := uuid.New()
+
+	parent, err := ts.usecase.Create(context.Background(), Comment{User: &user, PostID: postId, Message: "A comment"})
+	assert.NoError(t, err)
+
+	_, err = ts.usecase.Create(context.Background(), Comment{User: &user, PostID: uuid.New(), ParentID: &parent.ID, Message: "A reply on another post"})
+	assert.IsType(t, &CommentNotFoundError{}, err)
+
+	var replyIds []uuid.UUID
+	for i := 0; i < previewReplies+2; i++ {
+		reply, err := ts.usecase.Create(context.Background(), Comment{User: &user, PostID: postId, ParentID: &parent.ID, Message: fmt.Sprintf("Reply %d", i)})
+		assert.NoError(t, err)
+		replyIds = append(replyIds, reply.ID)
+		time.Sleep(time.Millisecond)
+	}
+
+	// Replying to a reply adds to the thread of its parent
+	nested, err := ts.usecase.Create(context.Background(), Comment{User: &user, PostID: postId, ParentID: &replyIds[0], Message: "A nested reply"})
+	assert.NoError(t, err)
+	assert.Equal(t, parent.ID, *nested.ParentID)
+
+	comments, err := ts.usecase.GetFromPost(context.Background(), user.ID, postId, DefaultCommentsLimit, CommentsOrderNewest, 0, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 1)
+	assert.Equal(t, previewReplies+3, comments[0].ReplyCount)
+	assert.Len(t, comments[0].Replies, previewReplies)
+	assert.Equal(t, replyIds[0], comments[0].Replies[0].ID)
+
+	replies, err := ts.usecase.GetReplies(context.Background(), user.ID, parent.ID, 2, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, replies, 2)
+	assert.Equal(t, replyIds[:2], []uuid.UUID{replies[0].ID, replies[1].ID})
+
+	replies, err = ts.usecase.GetReplies(context.Background(), user.ID, parent.ID, 10, replies[1].CreatedAt, replies[1].ID)
+	assert.NoError(t, err)
+	assert.Len(t, replies, previewReplies+1)
+
+	_, err = ts.usecase.GetReplies(context.Background(), user.ID, replyIds[0], 10, time.Time{}, uuid.Nil)
+	assert.IsType(t, &CommentNotFoundError{}, err)
+}
+
+func TestDeleteParentCommentTombstone(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	postId := uuid.New()
+
+	parent, err := ts.usecase.Create(context.Background(), Comment{User: &user, PostID: postId, Message: "A comment"})
+	assert.NoError(t, err)
+	reply, err := ts.usecase.Create(context.Background(), Comment{User: &user, PostID: postId, ParentID: &parent.ID, Message: "A reply"})
+	assert.NoError(t, err)
+
+	err = ts.usecase.Delete(context.Background(), user.ID, parent.ID)
+	assert.NoError(t, err)
+
+	// The deleted parent is kept without its author and message while it has replies
+	comments, err := ts.usecase.GetFromPost(context.Background(), user.ID, postId, DefaultCommentsLimit, CommentsOrderNewest, 0, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, comments, 1)
+	assert.Equal(t, parent.ID, comments[0].ID)
+	assert.NotNil(t, comments[0].DeletedAt)
+	assert.Nil(t, comments[0].User)
+	assert.Empty(t, comments[0].Message)
+	assert.Equal(t, reply.ID, comments[0].Replies[0].ID)
+
+	replies, err := ts.usecase.GetReplies(context.Background(), user.ID, parent.ID, 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, replies, 1)
+
+	err = ts.usecase.Delete(context.Background(), user.ID, reply.ID)
+	assert.NoError(t, err)
+
+	comments, err = ts.usecase.GetFromPost(context.Background(), user.ID, postId, DefaultCommentsLimit, CommentsOrderNewest, 0, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Empty(t, comments)
+}
+
 // mockCommentRepository is a mock implementation of iCommentRepository for testing purposes
 type mockCommentRepository struct {
 	comments map[uuid.UUID]Comment
+	users    map[string]uuid.UUID
+	// hidden holds the posts no viewer is allowed to read
+	hidden map[uuid.UUID]bool
+	// posts holds the authors, comment policies and mentions of the posts, posts missing from it being open to everyone
+	posts     map[uuid.UUID]shared.Post
+	followed  map[uuid.UUID][]uuid.UUID
+	deletedBy map[uuid.UUID]uuid.UUID
+	// likes holds the users who liked each comment
+	likes map[uuid.UUID]map[uuid.UUID]bool
 }
 
 func newMockCommentRepository() *mockCommentRepository {
 	return &mockCommentRepository{
-		comments: make(map[uuid.UUID]Comment),
+		comments:  make(map[uuid.UUID]Comment),
+		users:     make(map[string]uuid.UUID),
+		hidden:    make(map[uuid.UUID]bool),
+		posts:     make(map[uuid.UUID]shared.Post),
+		followed:  make(map[uuid.UUID][]uuid.UUID),
+		deletedBy: make(map[uuid.UUID]uuid.UUID),
+		likes:     make(map[uuid.UUID]map[uuid.UUID]bool),
 	}
 }
 
 func (m *mockCommentRepository) create(ctx context.Context, comment Comment) (Comment, error) {
+	if m.hidden[comment.PostID] {
+		return Comment{}, &PostNotFoundError{}
+	}
+	if post, exists := m.posts[comment.PostID]; exists && !m.commentAllowed(comment.User.ID, post) {
+		return Comment{}, &CommentNotAllowedError{}
+	}
+	if comment.ParentID != nil {
+		parent, exists := m.comments[*comment.ParentID]
+		if !exists || parent.PostID != comment.PostID || parent.DeletedAt != nil {
+			return Comment{}, &CommentNotFoundError{}
+		}
+		if parent.ParentID != nil {
+			comment.ParentID = parent.ParentID
+		}
+	}
+
 	id := uuid.New()
 	comment.ID = id
+	comment.CreatedAt = time.Now()
 	m.comments[id] = comment
 
 	return comment, nil
 }
 
 func (m *mockCommentRepository) update(ctx context.Context, comment Comment, id uuid.UUID) error {
-	if _, exists := m.comments[id]; !exists {
+	if stored, exists := m.comments[id]; !exists || stored.DeletedAt != nil {
 		return fmt.Errorf("comment not found")
 	}
 	comment.ID = id
@@ -177,30 +657,296 @@ func (m *mockCommentRepository) update(ctx context.Context, comment Comment, id
 	return nil
 }
 
-func (m *mockCommentRepository) delete(ctx context.Context, id uuid.UUID) error {
-	if _, exists := m.comments[id]; !exists {
+func (m *mockCommentRepository) delete(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	comment, exists := m.comments[id]
+	if !exists || comment.DeletedAt != nil {
 		return fmt.Errorf("comment not found")
 	}
-	delete(m.comments, id)
+	deletedAt := time.Now()
+	comment.DeletedAt = &deletedAt
+	comment.Pinned = false
+	m.comments[id] = comment
+	m.deletedBy[id] = userId
+
+	return nil
+}
+
+func (m *mockCommentRepository) setHidden(ctx context.Context, id uuid.UUID, hidden bool) error {
+	comment, exists := m.comments[id]
+	if !exists || comment.DeletedAt != nil {
+		return &CommentNotFoundError{}
+	}
+	comment.Hidden = hidden
+	m.comments[id] = comment
+
+	return nil
+}
+
+func (m *mockCommentRepository) setPinned(ctx context.Context, id uuid.UUID, pinned bool) error {
+	comment, exists := m.comments[id]
+	if !exists || comment.DeletedAt != nil || comment.ParentID != nil {
+		return &CommentNotFoundError{}
+	}
+	if pinned {
+		for otherId, other := range m.comments {
+			if other.PostID == comment.PostID && other.Pinned {
+				other.Pinned = false
+				m.comments[otherId] = other
+			}
+		}
+	}
+	comment.Pinned = pinned
+	m.comments[id] = comment
 
 	return nil
 }
 
 func (m *mockCommentRepository) get(ctx context.Context, id uuid.UUID) (Comment, error) {
-	if comment, exists := m.comments[id]; exists {
+	if comment, exists := m.comments[id]; exists && comment.DeletedAt == nil {
+		if post, exists := m.posts[comment.PostID]; exists {
+			comment.PostOwnerID = post.User.ID
+		}
 		return comment, nil
 	}
 
-	return Comment{}, fmt.Errorf("comment not found")
+	return Comment{}, &CommentNotFoundError{}
 }
 
-func (m *mockCommentRepository) getFromPost(ctx context.Context, postId uuid.UUID) ([]Comment, error) {
+func (m *mockCommentRepository) getFromPost(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID, limit int, order string, lastLikeCount int, lastCreatedAt time.Time, lastId uuid.UUID) ([]Comment, error) {
+	if m.hidden[postId] {
+		return nil, &PostNotFoundError{}
+	}
+
+	var result, pinned []Comment
+	for _, comment := range m.comments {
+		if comment.PostID != postId || comment.ParentID != nil || !m.shownTo(viewerId, comment) {
+			continue
+		}
+
+		if post, exists := m.posts[postId]; exists {
+			comment.IsAuthor = comment.User.ID == post.User.ID
+		}
+		comment.ReplyCount = len(m.replies(uuid.Nil, comment.ID))
+		if comment.DeletedAt != nil {
+			if comment.ReplyCount == 0 {
+				continue
+			}
+			comment.User = nil
+			comment.Message = ""
+			comment.IsAuthor = false
+		}
+
+		comment.Replies = m.replies(viewerId, comment.ID)
+		if len(comment.Replies) > previewReplies {
+			comment.Replies = comment.Replies[:previewReplies]
+		}
+		if comment.Pinned {
+			pinned = append(pinned, comment)
+		} else {
+			result = append(result, comment)
+		}
+	}
+
+	// Comments are ordered by creation date then id, top comments being ordered by like count first
+	var last Comment
+	if !lastCreatedAt.IsZero() || lastId != uuid.Nil {
+		last = Comment{ID: lastId, CreatedAt: lastCreatedAt, LikeCount: lastLikeCount}
+	}
+	before := func(a Comment, b Comment) bool {
+		if order == CommentsOrderTop && a.LikeCount != b.LikeCount {
+			return a.LikeCount > b.LikeCount
+		}
+		if !a.CreatedAt.Equal(b.CreatedAt) {
+			return a.CreatedAt.Before(b.CreatedAt) == (order == CommentsOrderOldest)
+		}
+		if order == CommentsOrderOldest {
+			return a.ID.String() < b.ID.String()
+		}
+		return a.ID.String() > b.ID.String()
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return before(result[i], result[j])
+	})
+
+	if last.ID != uuid.Nil || !last.CreatedAt.IsZero() {
+		var page []Comment
+		for _, comment := range result {
+			if before(last, comment) {
+				page = append(page, comment)
+			}
+		}
+		result = page
+	}
+	if len(result) > limit {
+		result = result[:limit]
+	}
+	if last.ID == uuid.Nil && last.CreatedAt.IsZero() {
+		result = append(pinned, result...)
+	}
+
+	return result, nil
+}
+
+func (m *mockCommentRepository) getReplies(ctx context.Context, viewerId uuid.UUID, id uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]Comment, error) {
+	parent, exists := m.comments[id]
+	if !exists || parent.ParentID != nil {
+		return nil, &CommentNotFoundError{}
+	}
+	if m.hidden[parent.PostID] {
+		return nil, &PostNotFoundError{}
+	}
+
+	var result []Comment
+	for _, reply := range m.replies(viewerId, id) {
+		if (lastCreatedAt.IsZero() && lastId == uuid.Nil) || reply.CreatedAt.After(lastCreatedAt) {
+			result = append(result, reply)
+		}
+	}
+	if len(result) > limit {
+		result = result[:limit]
+	}
+
+	return result, nil
+}
+
+// replies lists the replies to a comment that are not in the trash, oldest first. Replies hidden by the author of the
+// post are left out unless shown to the viewer, every reply being listed for uuid.Nil
+func (m *mockCommentRepository) replies(viewerId uuid.UUID, parentId uuid.UUID) []Comment {
 	var result []Comment
 	for _, comment := range m.comments {
-		if comment.PostID == postId {
+		if comment.ParentID == nil || *comment.ParentID != parentId || comment.DeletedAt != nil {
+			continue
+		}
+		if post, exists := m.posts[comment.PostID]; exists {
+			comment.IsAuthor = comment.User.ID == post.User.ID
+		}
+		if viewerId == uuid.Nil || m.shownTo(viewerId, comment) {
+			result = append(result, comment)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt.Before(result[j].CreatedAt)
+	})
+
+	return result
+}
+
+// shownTo mirrors shownToViewer for the comments of the mock
+func (m *mockCommentRepository) shownTo(viewerId uuid.UUID, comment Comment) bool {
+	return !comment.Hidden || comment.User.ID == viewerId || m.posts[comment.PostID].User.ID == viewerId
+}
+
+func (m *mockCommentRepository) getTrash(ctx context.Context, userId uuid.UUID) ([]Comment, error) {
+	var result []Comment
+	for id, comment := range m.comments {
+		if comment.DeletedAt != nil && m.deletedBy[id] == userId && time.Since(*comment.DeletedAt) <= shared.TrashRetention {
 			result = append(result, comment)
 		}
 	}
 
 	return result, nil
 }
+
+func (m *mockCommentRepository) restore(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	comment, exists := m.comments[id]
+	if !exists || comment.DeletedAt == nil || m.deletedBy[id] != userId || time.Since(*comment.DeletedAt) > shared.TrashRetention {
+		return &CommentNotFoundError{}
+	}
+	comment.DeletedAt = nil
+	m.comments[id] = comment
+	delete(m.deletedBy, id)
+
+	return nil
+}
+
+func (m *mockCommentRepository) like(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	comment, exists := m.comments[id]
+	if !exists || comment.DeletedAt != nil || !m.shownTo(userId, comment) {
+		return &CommentNotFoundError{}
+	}
+	if m.hidden[comment.PostID] {
+		return &PostNotFoundError{}
+	}
+
+	if m.likes[id] == nil {
+		m.likes[id] = make(map[uuid.UUID]bool)
+	}
+	if !m.likes[id][userId] {
+		m.likes[id][userId] = true
+		comment.LikeCount++
+		m.comments[id] = comment
+	}
+
+	return nil
+}
+
+func (m *mockCommentRepository) getLikes(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) ([]shared.User, error) {
+	comment, exists := m.comments[id]
+	if !exists || comment.DeletedAt != nil || !m.shownTo(viewerId, comment) {
+		return nil, &CommentNotFoundError{}
+	}
+	if m.hidden[comment.PostID] {
+		return nil, &PostNotFoundError{}
+	}
+
+	var users []shared.User
+	for userId := range m.likes[id] {
+		users = append(users, shared.User{ID: userId})
+	}
+
+	return users, nil
+}
+
+func (m *mockCommentRepository) unlike(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	if m.likes[id][userId] {
+		delete(m.likes[id], userId)
+		comment := m.comments[id]
+		comment.LikeCount--
+		m.comments[id] = comment
+	}
+
+	return nil
+}
+
+func (m *mockCommentRepository) userLikedComment(ctx context.Context, userId uuid.UUID, id uuid.UUID) (bool, error) {
+	return m.likes[id][userId], nil
+}
+
+func (m *mockCommentRepository) getUserIdsByUsernames(ctx context.Context, usernames []string) (map[string]uuid.UUID, error) {
+	userIds := make(map[string]uuid.UUID)
+	for _, username := range usernames {
+		if id, exists := m.users[username]; exists {
+			userIds[username] = id
+		}
+	}
+
+	return userIds, nil
+}
+
+// commentAllowed mirrors checkCommentAllowed for the posts of the mock
+func (m *mockCommentRepository) commentAllowed(userId uuid.UUID, post shared.Post) bool {
+	if post.User.ID == userId {
+		return true
+	}
+
+	switch post.CommentPolicy {
+	case shared.CommentPolicyFollowers:
+		for _, followedId := range m.followed[userId] {
+			if followedId == post.User.ID {
+				return true
+			}
+		}
+		return false
+	case shared.CommentPolicyMentioned:
+		for _, mention := range post.Mentions {
+			if mention.UserID == userId {
+				return true
+			}
+		}
+		return false
+	case shared.CommentPolicyOff:
+		return false
+	default:
+		return true
+	}
+}
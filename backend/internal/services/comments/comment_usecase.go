@@ -3,6 +3,7 @@ package comments
 import (
 	"context"
 	"fmt"
+	"time"
 	"y-net/internal/services/shared"
 
 	"github.com/google/uuid"
@@ -10,10 +11,21 @@ import (
 
 type ICommentUsecase interface {
 	Create(ctx context.Context, comment Comment) (Comment, error)
-	GetFromPost(ctx context.Context, postId uuid.UUID) ([]Comment, error)
+	GetFromPost(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID, limit int, order string, lastLikeCount int, lastCreatedAt time.Time, lastId uuid.UUID) ([]Comment, error)
+	GetReplies(ctx context.Context, viewerId uuid.UUID, id uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]Comment, error)
 	Get(ctx context.Context, id uuid.UUID) (Comment, error)
 	Update(ctx context.Context, comment Comment, id uuid.UUID) error
-	Delete(ctx context.Context, id uuid.UUID) error
+	Delete(ctx context.Context, userId uuid.UUID, id uuid.UUID) error
+	Hide(ctx context.Context, id uuid.UUID) error
+	Unhide(ctx context.Context, id uuid.UUID) error
+	Pin(ctx context.Context, id uuid.UUID) error
+	Unpin(ctx context.Context, id uuid.UUID) error
+	GetTrash(ctx context.Context, userId uuid.UUID) ([]Comment, error)
+	Restore(ctx context.Context, userId uuid.UUID, id uuid.UUID) error
+	Like(ctx context.Context, userId uuid.UUID, id uuid.UUID) error
+	GetLikes(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) ([]shared.User, error)
+	Unlike(ctx context.Context, userId uuid.UUID, id uuid.UUID) error
+	UserLikedComment(ctx context.Context, userId uuid.UUID, id uuid.UUID) (bool, error)
 }
 
 type commentUsecaseImpl struct {
@@ -39,6 +51,12 @@ func (u *commentUsecaseImpl) Create(ctx context.Context, comment Comment) (Comme
 		return Comment{}, fmt.Errorf("comment text must not be empty")
 	}
 
+	mentions, err := u.resolveMentions(ctx, comment.Message)
+	if err != nil {
+		return Comment{}, err
+	}
+	comment.Mentions = mentions
+
 	newComment, err := u.repository.create(ctx, comment)
 	if err != nil {
 		return Comment{}, err
@@ -47,8 +65,8 @@ func (u *commentUsecaseImpl) Create(ctx context.Context, comment Comment) (Comme
 	return newComment, nil
 }
 
-func (u *commentUsecaseImpl) GetFromPost(ctx context.Context, postId uuid.UUID) ([]Comment, error) {
-	comments, err := u.repository.getFromPost(ctx, postId)
+func (u *commentUsecaseImpl) GetFromPost(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID, limit int, order string, lastLikeCount int, lastCreatedAt time.Time, lastId uuid.UUID) ([]Comment, error) {
+	comments, err := u.repository.getFromPost(ctx, viewerId, postId, limit, order, lastLikeCount, lastCreatedAt, lastId)
 	if err != nil {
 		return nil, err
 	}
@@ -56,6 +74,15 @@ func (u *commentUsecaseImpl) GetFromPost(ctx context.Context, postId uuid.UUID)
 	return comments, nil
 }
 
+func (u *commentUsecaseImpl) GetReplies(ctx context.Context, viewerId uuid.UUID, id uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]Comment, error) {
+	replies, err := u.repository.getReplies(ctx, viewerId, id, limit, lastCreatedAt, lastId)
+	if err != nil {
+		return nil, err
+	}
+
+	return replies, nil
+}
+
 func (u *commentUsecaseImpl) Get(ctx context.Context, id uuid.UUID) (Comment, error) {
 	comment, err := u.repository.get(ctx, id)
 	if err != nil {
@@ -76,7 +103,13 @@ func (u *commentUsecaseImpl) Update(ctx context.Context, comment Comment, id uui
 		return fmt.Errorf("comment text must not be empty")
 	}
 
-	err := u.repository.update(ctx, comment, id)
+	mentions, err := u.resolveMentions(ctx, comment.Message)
+	if err != nil {
+		return err
+	}
+	comment.Mentions = mentions
+
+	err = u.repository.update(ctx, comment, id)
 	if err != nil {
 		return err
 	}
@@ -84,11 +117,119 @@ func (u *commentUsecaseImpl) Update(ctx context.Context, comment Comment, id uui
 	return nil
 }
 
-func (u *commentUsecaseImpl) Delete(ctx context.Context, id uuid.UUID) error {
-	err := u.repository.delete(ctx, id)
+// Delete moves a comment to the trash of the user deleting it, from where only they can restore it
+func (u *commentUsecaseImpl) Delete(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	err := u.repository.delete(ctx, userId, id)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// Hide hides a comment from everyone but the author of the comment and the author of the post
+func (u *commentUsecaseImpl) Hide(ctx context.Context, id uuid.UUID) error {
+	err := u.repository.setHidden(ctx, id, true)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *commentUsecaseImpl) Unhide(ctx context.Context, id uuid.UUID) error {
+	err := u.repository.setHidden(ctx, id, false)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Pin pins a top-level comment to the top of its post, a post having a single pinned comment
+func (u *commentUsecaseImpl) Pin(ctx context.Context, id uuid.UUID) error {
+	err := u.repository.setPinned(ctx, id, true)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *commentUsecaseImpl) Unpin(ctx context.Context, id uuid.UUID) error {
+	err := u.repository.setPinned(ctx, id, false)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *commentUsecaseImpl) GetTrash(ctx context.Context, userId uuid.UUID) ([]Comment, error) {
+	comments, err := u.repository.getTrash(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return comments, nil
+}
+
+func (u *commentUsecaseImpl) Restore(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	err := u.repository.restore(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *commentUsecaseImpl) Like(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	err := u.repository.like(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *commentUsecaseImpl) GetLikes(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) ([]shared.User, error) {
+	likes, err := u.repository.getLikes(ctx, viewerId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return likes, nil
+}
+
+func (u *commentUsecaseImpl) Unlike(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	err := u.repository.unlike(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *commentUsecaseImpl) UserLikedComment(ctx context.Context, userId uuid.UUID, id uuid.UUID) (bool, error) {
+	liked, err := u.repository.userLikedComment(ctx, userId, id)
+	if err != nil {
+		return false, err
+	}
+
+	return liked, nil
+}
+
+// resolveMentions parses the @mentions of a comment message, keeping the ones that match a user
+func (u *commentUsecaseImpl) resolveMentions(ctx context.Context, message string) ([]shared.Mention, error) {
+	mentions := shared.ParseMentions(message)
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+
+	userIds, err := u.repository.getUserIdsByUsernames(ctx, shared.MentionedUsernames(mentions))
+	if err != nil {
+		return nil, err
+	}
+
+	return shared.ResolveMentions(mentions, userIds), nil
+}
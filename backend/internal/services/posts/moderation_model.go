@@ -0,0 +1,32 @@
+package posts
+
+import (
+	"time"
+	"y-net/internal/services/shared"
+
+	"github.com/google/uuid"
+)
+
+type BannedImage struct {
+	ID        uuid.UUID `json:"id,omitempty"`
+	Hash      string    `json:"hash,omitempty"`
+	Image     string    `json:"image,omitempty"`
+	Reason    *string   `json:"reason,omitempty"`
+	CreatedBy uuid.UUID `json:"createdBy,omitempty"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+}
+
+type SimilarImage struct {
+	PostID      uuid.UUID    `json:"postId,omitempty"`
+	MediaID     uuid.UUID    `json:"mediaId,omitempty"`
+	User        *shared.User `json:"user,omitempty"`
+	Image       string       `json:"image,omitempty"`
+	Distance    int          `json:"distance"`
+	DuplicateOf *uuid.UUID   `json:"duplicateOf,omitempty"`
+	CreatedAt   time.Time    `json:"createdAt,omitempty"`
+}
+
+type SensitiveFlag struct {
+	Sensitive      bool    `json:"sensitive"`
+	ContentWarning *string `json:"contentWarning,omitempty"`
+}
@@ -0,0 +1,7 @@
+package posts
+
+import "github.com/google/uuid"
+
+type PollVoteJson struct {
+	OptionIDs []uuid.UUID `json:"optionIds,omitempty"`
+}
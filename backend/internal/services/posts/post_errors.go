@@ -0,0 +1,170 @@
+package posts
+
+import (
+	"fmt"
+	"strings"
+	"y-net/internal/media"
+	"y-net/internal/services/shared"
+)
+
+type AltTextTooLongError struct{}
+
+func (m *AltTextTooLongError) Error() string {
+	return fmt.Sprintf("alt text must not be longer than %d characters", maxAltTextLength)
+}
+
+type BannedImageError struct{}
+
+func (m *BannedImageError) Error() string {
+	return "image is not allowed"
+}
+
+type PostNotFoundError struct{}
+
+func (m *PostNotFoundError) Error() string {
+	return "post not found"
+}
+
+type InvalidReactionError struct{}
+
+func (m *InvalidReactionError) Error() string {
+	return "reaction must be one of: " + strings.Join(ReactionTypes(), ", ")
+}
+
+type PostNotDraftError struct{}
+
+func (m *PostNotDraftError) Error() string {
+	return "post is not a draft"
+}
+
+type InvalidScheduleError struct{}
+
+func (m *InvalidScheduleError) Error() string {
+	return "scheduled time must be in the future"
+}
+
+type EditWindowExpiredError struct{}
+
+func (m *EditWindowExpiredError) Error() string {
+	return "post can no longer be edited"
+}
+
+type PostIsDraftError struct{}
+
+func (m *PostIsDraftError) Error() string {
+	return "post is a draft"
+}
+
+type PostIsArchivedError struct{}
+
+func (m *PostIsArchivedError) Error() string {
+	return "post is archived"
+}
+
+type TooManyPinnedPostsError struct{}
+
+func (m *TooManyPinnedPostsError) Error() string {
+	return fmt.Sprintf("at most %d posts can be pinned", maxPinnedPosts)
+}
+
+type InvalidPollOptionsError struct{}
+
+func (m *InvalidPollOptionsError) Error() string {
+	return fmt.Sprintf("poll must have between %d and %d options of at most %d characters", minPollOptions, maxPollOptions, maxPollOptionLength)
+}
+
+type InvalidPollCloseError struct{}
+
+func (m *InvalidPollCloseError) Error() string {
+	return fmt.Sprintf("poll must close within %d days of its publication", int(MaxPollDuration.Hours()/24))
+}
+
+type PollNotFoundError struct{}
+
+func (m *PollNotFoundError) Error() string {
+	return "poll not found"
+}
+
+type PollClosedError struct{}
+
+func (m *PollClosedError) Error() string {
+	return "poll is closed"
+}
+
+type InvalidVoteError struct{}
+
+func (m *InvalidVoteError) Error() string {
+	return "vote must pick options of the poll, a single one unless it is multiple choice"
+}
+
+type AlreadyVotedError struct{}
+
+func (m *AlreadyVotedError) Error() string {
+	return "user has already voted in this poll"
+}
+
+type InvalidPlaceError struct{}
+
+func (m *InvalidPlaceError) Error() string {
+	return fmt.Sprintf("place must have a name of at most %d characters and valid coordinates", maxPlaceNameLength)
+}
+
+type ContentWarningTooLongError struct{}
+
+func (m *ContentWarningTooLongError) Error() string {
+	return fmt.Sprintf("content warning must not be longer than %d characters", maxContentWarningLength)
+}
+
+type InvalidCommentPolicyError struct{}
+
+func (m *InvalidCommentPolicyError) Error() string {
+	return fmt.Sprintf("comment policy must be one of: %s, %s, %s, %s", shared.CommentPolicyEveryone, shared.CommentPolicyFollowers, shared.CommentPolicyMentioned, shared.CommentPolicyOff)
+}
+
+type InvalidVisibilityError struct{}
+
+func (m *InvalidVisibilityError) Error() string {
+	return fmt.Sprintf("post visibility must be one of: %s, %s, %s", shared.VisibilityPublic, shared.VisibilityFollowers, shared.VisibilityCloseFriends)
+}
+
+type EmptyMediaError struct{}
+
+func (m *EmptyMediaError) Error() string {
+	return "post image must not be empty"
+}
+
+type TooManyMediaError struct{}
+
+func (m *TooManyMediaError) Error() string {
+	return fmt.Sprintf("post must not have more than %d images", maxPostMedia)
+}
+
+type MissingDescriptionError struct{}
+
+func (m *MissingDescriptionError) Error() string {
+	return "quote and poll posts must have a description or an image"
+}
+
+// InvalidImageError reports an uploaded image that can not be decoded, along with the reason
+type InvalidImageError struct {
+	err error
+}
+
+func (m *InvalidImageError) Error() string {
+	return fmt.Sprintf("invalid post image: %v", m.err)
+}
+
+// InvalidVideoError reports an uploaded video that can not be decoded or is not in a supported format, along with the reason
+type InvalidVideoError struct {
+	err error
+}
+
+func (m *InvalidVideoError) Error() string {
+	return fmt.Sprintf("invalid post video: %v", m.err)
+}
+
+type VideoTooLargeError struct{}
+
+func (m *VideoTooLargeError) Error() string {
+	return fmt.Sprintf("post video must not be larger than %d MB", media.MaxVideoSize>>20)
+}
@@ -0,0 +1,201 @@
+package posts
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"time"
+
+	"y-net/internal/logger"
+	"y-net/internal/media"
+	"y-net/internal/services/shared"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// Time after which a video claimed by a crashed instance may be picked up again
+	videoLeaseDuration = 10 * time.Minute
+
+	// Number of scheduled posts published per transaction
+	scheduledPostsBatchSize = 100
+
+	// Number of deleted posts purged per transaction
+	purgeBatchSize = 100
+)
+
+// StartVideoProcessing processes uploaded videos in the background, polling every interval until ctx is cancelled
+func StartVideoProcessing(ctx context.Context, interval time.Duration) {
+	repository := &postRepositoryImpl{}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Drain every pending video before waiting for the next tick
+		for {
+			processed, err := processNextVideo(ctx, repository)
+			if err != nil {
+				logger.ServerLogger.Error(err.Error())
+				break
+			}
+			if !processed {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// StartPostScheduler publishes the scheduled posts that are due in the background, polling every interval until ctx is cancelled.
+// Due posts are read from the database on every tick, so the ones missed while no instance was running get published on startup
+func StartPostScheduler(ctx context.Context, interval time.Duration) {
+	repository := &postRepositoryImpl{}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Drain every due post before waiting for the next tick
+		for {
+			published, err := repository.publishDueDrafts(ctx, scheduledPostsBatchSize)
+			if err != nil {
+				logger.ServerLogger.Error(err.Error())
+				break
+			}
+			if published > 0 {
+				logger.ServerLogger.Info(fmt.Sprintf("published %d scheduled posts", published))
+			}
+			if published < scheduledPostsBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// StartTrashPurge permanently removes the posts that have been in the trash for longer than shared.TrashRetention,
+// polling every interval until ctx is cancelled
+func StartTrashPurge(ctx context.Context, interval time.Duration) {
+	repository := &postRepositoryImpl{}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		// Drain every expired post before waiting for the next tick
+		for {
+			purged, err := repository.purgeDeleted(ctx, shared.TrashRetention, purgeBatchSize)
+			if err != nil {
+				logger.ServerLogger.Error(err.Error())
+				break
+			}
+			if purged > 0 {
+				logger.ServerLogger.Info(fmt.Sprintf("purged %d deleted posts", purged))
+			}
+			if purged < purgeBatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// processNextVideo extracts the metadata and poster frame of one pending video, returning false if none is pending
+func processNextVideo(ctx context.Context, repository *postRepositoryImpl) (bool, error) {
+	item, postId, err := repository.claimVideo(ctx, videoLeaseDuration)
+	if err != nil {
+		return false, err
+	}
+	if item.ID == uuid.Nil {
+		return false, nil
+	}
+
+	// Only videos that can not be read are failed, other errors such as missing ffmpeg binaries being retried
+	// once the lease of the video expires
+	processed, err := processVideo(ctx, item)
+	if err != nil {
+		var invalid *InvalidVideoError
+		if !errors.As(err, &invalid) {
+			return false, fmt.Errorf("failed to process video %v: %w", item.ID, err)
+		}
+
+		logger.ServerLogger.Warn(fmt.Sprintf("failed to process video %v: %v", item.ID, err))
+
+		return true, repository.failVideo(ctx, postId, item.ID)
+	}
+
+	// Poster frames go through the same banned image check as uploaded images
+	banned, err := repository.matchBannedImage(ctx, []uint64{*processed.Hash}, BannedImageMaxDistance)
+	if err != nil {
+		return false, err
+	}
+	if banned {
+		logger.ServerLogger.Warn(fmt.Sprintf("banned poster frame in video %v", item.ID))
+
+		return true, repository.failVideo(ctx, postId, item.ID)
+	}
+
+	return true, repository.completeVideo(ctx, postId, processed)
+}
+
+func processVideo(ctx context.Context, item shared.PostMedia) (shared.PostMedia, error) {
+	path, err := media.FilePath(item.VideoPath)
+	if err != nil {
+		return shared.PostMedia{}, err
+	}
+
+	videoMeta, err := media.ProbeVideo(ctx, path)
+	if err != nil {
+		return shared.PostMedia{}, invalidVideo(err)
+	}
+	if videoMeta.Duration > media.MaxVideoDuration {
+		return shared.PostMedia{}, &InvalidVideoError{err: fmt.Errorf("video must not be longer than %v seconds", media.MaxVideoDuration)}
+	}
+
+	poster, err := media.ExtractPosterFrame(ctx, path)
+	if err != nil {
+		return shared.PostMedia{}, invalidVideo(err)
+	}
+
+	item.Image = base64.StdEncoding.EncodeToString(poster)
+	imageMeta, err := media.ExtractImageMetadata(item.Image)
+	if err != nil {
+		return shared.PostMedia{}, &InvalidVideoError{err: err}
+	}
+
+	item.Duration = &videoMeta.Duration
+	item.Width = &videoMeta.Width
+	item.Height = &videoMeta.Height
+	item.DominantColor = &imageMeta.DominantColor
+	item.BlurHash = &imageMeta.BlurHash
+	item.Hash = &imageMeta.Hash
+
+	return item, nil
+}
+
+// invalidVideo reports the videos ffprobe or ffmpeg could not read as invalid, keeping other errors as they are
+func invalidVideo(err error) error {
+	var unreadable *media.UnreadableVideoError
+	if errors.As(err, &unreadable) {
+		return &InvalidVideoError{err: err}
+	}
+
+	return err
+}
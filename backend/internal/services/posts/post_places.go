@@ -0,0 +1,64 @@
+package posts
+
+import (
+	"math"
+	"strings"
+	"unicode/utf8"
+	"y-net/internal/services/shared"
+)
+
+const (
+	maxPlaceNameLength = 100
+
+	// Place coordinates are rounded to 3 decimals, about 110 metres at the equator
+	placeCoordinateScale = 1000
+
+	DefaultNearbyRadius = 1000
+	MaxNearbyRadius     = 50000
+	maxNearbyResults    = 50
+
+	earthRadius = 6371000
+)
+
+// normalizePlace trims the name of the place a post is tagged with and rounds its coordinates,
+// the id of a place being ignored as places are matched by name and coordinates
+func normalizePlace(place *shared.Place) (*shared.Place, error) {
+	if place == nil {
+		return nil, nil
+	}
+
+	name := strings.TrimSpace(place.Name)
+	if name == "" || utf8.RuneCountInString(name) > maxPlaceNameLength || !ValidCoordinates(place.Latitude, place.Longitude) {
+		return nil, &InvalidPlaceError{}
+	}
+
+	return &shared.Place{Name: name, Latitude: roundCoordinate(place.Latitude), Longitude: roundCoordinate(place.Longitude)}, nil
+}
+
+// ValidCoordinates reports whether a latitude and longitude are within range
+func ValidCoordinates(latitude float64, longitude float64) bool {
+	return latitude >= -90 && latitude <= 90 && longitude >= -180 && longitude <= 180
+}
+
+func roundCoordinate(value float64) float64 {
+	return math.Round(value*placeCoordinateScale) / placeCoordinateScale
+}
+
+// nearbyBounds returns the box of coordinates holding every point within radius metres of a position, so that
+// the places index narrows down the places before their distances are computed. The box spans every longitude
+// when it would reach a pole or cross the antimeridian
+func nearbyBounds(latitude float64, longitude float64, radius float64) (minLatitude, minLongitude, maxLatitude, maxLongitude float64) {
+	deltaLatitude := radius / earthRadius * 180 / math.Pi
+	minLatitude = math.Max(latitude-deltaLatitude, -90)
+	maxLatitude = math.Min(latitude+deltaLatitude, 90)
+
+	minLongitude, maxLongitude = -180, 180
+	if minLatitude > -90 && maxLatitude < 90 {
+		deltaLongitude := deltaLatitude / math.Cos(latitude*math.Pi/180)
+		if longitude-deltaLongitude >= -180 && longitude+deltaLongitude <= 180 {
+			minLongitude, maxLongitude = longitude-deltaLongitude, longitude+deltaLongitude
+		}
+	}
+
+	return minLatitude, minLongitude, maxLatitude, maxLongitude
+}
@@ -0,0 +1,81 @@
+package posts
+
+import (
+	"strings"
+	"time"
+	"unicode/utf8"
+	"y-net/internal/services/shared"
+
+	"github.com/google/uuid"
+)
+
+const (
+	minPollOptions      = 2
+	maxPollOptions      = 4
+	maxPollOptionLength = 100
+
+	MaxPollDuration = 7 * 24 * time.Hour
+)
+
+// normalizePoll trims the options of the poll attached to a post and checks when it closes, polls of scheduled
+// posts having to stay open past their publication. The closing time is returned in UTC as stored in the database
+func normalizePoll(post shared.Post) (shared.Post, error) {
+	if post.Poll == nil {
+		return post, nil
+	}
+
+	if len(post.Poll.Options) < minPollOptions || len(post.Poll.Options) > maxPollOptions {
+		return shared.Post{}, &InvalidPollOptionsError{}
+	}
+
+	options := make([]shared.PollOption, len(post.Poll.Options))
+	for i, option := range post.Poll.Options {
+		text := strings.TrimSpace(option.Text)
+		if text == "" || utf8.RuneCountInString(text) > maxPollOptionLength {
+			return shared.Post{}, &InvalidPollOptionsError{}
+		}
+		options[i] = shared.PollOption{Position: i, Text: text}
+	}
+
+	opensAt := time.Now()
+	if post.ScheduledAt != nil {
+		opensAt = *post.ScheduledAt
+	}
+	if !post.Poll.ClosesAt.After(opensAt) || post.Poll.ClosesAt.Sub(opensAt) > MaxPollDuration {
+		return shared.Post{}, &InvalidPollCloseError{}
+	}
+
+	post.Poll = &shared.Poll{Options: options, MultipleChoice: post.Poll.MultipleChoice, ClosesAt: post.Poll.ClosesAt.UTC()}
+
+	return post, nil
+}
+
+// normalizeVote drops the options picked more than once, a vote having to pick at least one option
+func normalizeVote(optionIds []uuid.UUID) ([]uuid.UUID, error) {
+	seen := make(map[uuid.UUID]bool)
+	var unique []uuid.UUID
+	for _, id := range optionIds {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+
+	if len(unique) == 0 {
+		return nil, &InvalidVoteError{}
+	}
+
+	return unique, nil
+}
+
+// hidePollResults clears the vote counts of the options of a poll the viewer has not voted in while it is open
+func hidePollResults(poll *shared.Poll) {
+	if poll.Voted || poll.Closed {
+		return
+	}
+
+	for i := range poll.Options {
+		poll.Options[i].VoteCount = nil
+	}
+}
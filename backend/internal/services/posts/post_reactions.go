@@ -0,0 +1,49 @@
+package posts
+
+import (
+	"os"
+	"strings"
+)
+
+const (
+	// ReactionHeart is the reaction stored by the like endpoints, it is always allowed
+	ReactionHeart = "heart"
+
+	maxReactionLength = 32
+)
+
+var defaultReactionTypes = []string{ReactionHeart, "laugh", "wow", "sad", "angry", "fire"}
+
+// ReactionTypes returns the reactions users can leave on posts, read as a comma separated list
+// from POST_REACTIONS and falling back to the default set
+func ReactionTypes() []string {
+	value := os.Getenv("POST_REACTIONS")
+	if strings.TrimSpace(value) == "" {
+		return defaultReactionTypes
+	}
+
+	types := []string{ReactionHeart}
+	seen := map[string]bool{ReactionHeart: true}
+	for _, reaction := range strings.Split(value, ",") {
+		reaction = strings.ToLower(strings.TrimSpace(reaction))
+		if reaction == "" || len(reaction) > maxReactionLength || seen[reaction] {
+			continue
+		}
+
+		seen[reaction] = true
+		types = append(types, reaction)
+	}
+
+	return types
+}
+
+// IsReactionType reports whether a reaction is part of the configured set
+func IsReactionType(reaction string) bool {
+	for _, reactionType := range ReactionTypes() {
+		if reactionType == reaction {
+			return true
+		}
+	}
+
+	return false
+}
@@ -2,29 +2,89 @@ package posts
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"time"
 	database "y-net/internal/database/postgres"
+	"y-net/internal/logger"
+	"y-net/internal/media"
 	"y-net/internal/services/shared"
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 type iPostRepository interface {
 	create(ctx context.Context, post shared.Post) (uuid.UUID, error)
-	getPosts(ctx context.Context, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error)
-	getPost(ctx context.Context, id uuid.UUID) (shared.Post, error)
-	update(ctx context.Context, post shared.Post, id uuid.UUID) error
+	getPosts(ctx context.Context, viewerId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error)
+	getPost(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) (shared.Post, error)
+	getBySearch(ctx context.Context, viewerId uuid.UUID, searchStr string) ([]shared.Post, error)
+	update(ctx context.Context, post shared.Post, id uuid.UUID, editWindow time.Duration) error
 	delete(ctx context.Context, id uuid.UUID) error
 	like(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error
-	getLikes(ctx context.Context, id uuid.UUID) ([]shared.User, error)
+	getLikes(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) ([]shared.User, error)
 	unlike(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error
-	userLikedPost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) (bool, error)
+	userLikedPost(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID, postId uuid.UUID) (bool, error)
+	getVideoPath(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID, mediaId uuid.UUID) (string, error)
+	matchBannedImage(ctx context.Context, hashes []uint64, maxDistance int) (bool, error)
+	banImage(ctx context.Context, bannedImage BannedImage) (BannedImage, error)
+	getBannedImages(ctx context.Context) ([]BannedImage, error)
+	unbanImage(ctx context.Context, id uuid.UUID) error
+	getSimilarImages(ctx context.Context, postId uuid.UUID, maxDistance int, limit int) ([]SimilarImage, error)
+	getPostsByTag(ctx context.Context, viewerId uuid.UUID, tag string, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error)
+	getTrendingTags(ctx context.Context, window time.Duration, limit int) ([]TrendingTag, error)
+	getUserIdsByUsernames(ctx context.Context, usernames []string) (map[string]uuid.UUID, error)
+	save(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error
+	unsave(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error
+	userSavedPost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) (bool, error)
+	repost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) (uuid.UUID, error)
+	unrepost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error
+	react(ctx context.Context, userId uuid.UUID, postId uuid.UUID, reaction string) error
+	unreact(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error
+	getReactions(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID, reaction string) ([]Reaction, error)
+	getDrafts(ctx context.Context, userId uuid.UUID) ([]shared.Post, error)
+	publish(ctx context.Context, id uuid.UUID) error
+	schedule(ctx context.Context, id uuid.UUID, scheduledAt *time.Time) error
+	getRevisions(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID) ([]PostRevision, error)
+	getTrash(ctx context.Context, userId uuid.UUID) ([]shared.Post, error)
+	restore(ctx context.Context, userId uuid.UUID, id uuid.UUID) error
+	archive(ctx context.Context, id uuid.UUID) error
+	unarchive(ctx context.Context, id uuid.UUID) error
+	pin(ctx context.Context, id uuid.UUID) error
+	unpin(ctx context.Context, id uuid.UUID) error
+	vote(ctx context.Context, userId uuid.UUID, postId uuid.UUID, optionIds []uuid.UUID) error
+	getNearbyPosts(ctx context.Context, viewerId uuid.UUID, latitude float64, longitude float64, radius float64, limit int) ([]shared.Post, error)
+	getPostsByPlace(ctx context.Context, viewerId uuid.UUID, placeId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error)
+	flagSensitive(ctx context.Context, id uuid.UUID, sensitive bool, contentWarning *string) error
 }
 
 type postRepositoryImpl struct{}
 
+const maxSearchResults = 50
+
+// postColumns are the columns read by scanPost. Queries using them read from posts p joined by postJoins
+// and pass the viewer id as $1
+var postColumns = `p.id, p.user_id, u.username, u.avatar, COALESCE(m.image, ''), m.image_width, m.image_height, m.image_color, m.image_blurhash, m.alt_text,
+	p.status, p.visibility, p.comment_policy, p.draft, p.scheduled_at, p.edited_at, p.deleted_at, p.archived_at, p.pinned_at, p.description, p.repost_of, p.quote_of, p.like_count, p.comment_count, p.repost_count,
+	p.content_warning, ` + shared.PostSensitive("p") + `, ` + shared.PostBlurredFor("p", "$1") + `,
+	loc.id, loc.name, loc.latitude, loc.longitude,
+	EXISTS (SELECT 1 FROM saves s WHERE s.user_id = $1 AND s.post_id = p.id),
+	(SELECT l.reaction FROM likes l WHERE l.user_id = $1 AND l.post_id = p.id), p.created_at`
+
+// visibleToViewer restricts the posts p to the ones the viewer bound to $1 can read
+var visibleToViewer = shared.PostVisibleTo("p", "$1")
+
+// unblurredForViewer restricts the posts p of the explore surfaces to the ones whose media the viewer bound to $1 sees,
+// leaving out sensitive posts unless the viewer chose to reveal sensitive media
+var unblurredForViewer = "NOT " + shared.PostBlurredFor("p", "$1")
+
+// postJoins joins a post with its author, its cover and its place, reposts and quotes without media having no cover.
+// The places of the posts of users who hide their location are left out
+const postJoins = `INNER JOIN users u ON p.user_id = u.id
+	LEFT JOIN post_media m ON m.post_id = p.id AND m.position = 0
+	LEFT JOIN places loc ON loc.id = p.place_id AND NOT u.hide_location`
+
 func (r *postRepositoryImpl) create(ctx context.Context, post shared.Post) (uuid.UUID, error) {
 	tx, err := database.Postgres.Begin(ctx)
 	if err != nil {
@@ -35,20 +95,69 @@ func (r *postRepositoryImpl) create(ctx context.Context, post shared.Post) (uuid
 		database.HandleTransaction(ctx, tx, err)
 	}()
 
+	// Quoting a repost quotes the original post
+	var quoteOf *uuid.UUID
+	if post.QuoteOf != nil {
+		err = tx.QueryRow(ctx, "SELECT COALESCE(p.repost_of, p.id) FROM posts p WHERE p.id = $2 AND "+visibleToViewer, post.User.ID, post.QuoteOf.ID).Scan(&quoteOf)
+		if err != nil {
+			if err == pgx.ErrNoRows {
+				err = &PostNotFoundError{}
+			}
+			return uuid.Nil, err
+		}
+	}
+
+	placeId, err := upsertPlace(ctx, tx, post.Place)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
 	var id uuid.UUID
 	err = tx.QueryRow(
 		ctx,
-		"INSERT INTO posts (user_id, image, description) VALUES ($1, $2, $3) RETURNING id",
-		post.User.ID, post.Image, post.Description,
+		"INSERT INTO posts (user_id, description, status, visibility, quote_of, draft, scheduled_at, place_id, content_warning, sensitive, comment_policy) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11) RETURNING id",
+		post.User.ID, post.Description, post.Status, post.Visibility, quoteOf, post.Draft, post.ScheduledAt, placeId, post.ContentWarning, post.Sensitive, post.CommentPolicy,
 	).Scan(&id)
 	if err != nil {
 		return uuid.Nil, fmt.Errorf("failed to insert post: %w", err)
 	}
 
+	if quoteOf != nil {
+		err = updateRepostCount(ctx, tx, *quoteOf)
+		if err != nil {
+			return uuid.Nil, err
+		}
+	}
+
+	err = updatePostCount(ctx, tx, post.User.ID)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = insertPostMedia(ctx, tx, id, post.Media)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = insertPoll(ctx, tx, id, post.Poll)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = syncPostTags(ctx, tx, id, post.Tags)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = syncPostMentions(ctx, tx, id, post.Mentions)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
 	return id, nil
 }
 
-func (r *postRepositoryImpl) getPosts(ctx context.Context, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+func (r *postRepositoryImpl) getPosts(ctx context.Context, viewerId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
 	tx, err := database.Postgres.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -63,23 +172,25 @@ func (r *postRepositoryImpl) getPosts(ctx context.Context, limit int, lastCreate
 
 	if lastCreatedAt.IsZero() && lastId == uuid.Nil {
 		query = `
-			SELECT p.id, p.user_id, u.username, u.avatar, p.image, p.description, p.like_count, p.comment_count, p.created_at
+			SELECT ` + postColumns + `
 			FROM posts p
-			INNER JOIN users u ON p.user_id = u.id
+			` + postJoins + `
+			WHERE p.status = 'ready' AND p.archived_at IS NULL AND ` + visibleToViewer + `
 			ORDER BY p.created_at DESC, p.id DESC
-			LIMIT $1
+			LIMIT $2
 		`
-		args = append(args, limit)
+		args = append(args, viewerId, limit)
 	} else {
 		query = `
-			SELECT p.id, p.user_id, u.username, u.avatar, p.image, p.description, p.like_count, p.comment_count, p.created_at
+			SELECT ` + postColumns + `
 			FROM posts p
-			INNER JOIN users u ON p.user_id = u.id
-			WHERE (p.created_at < $1 OR (p.created_at = $1 AND p.id < $2))
+			` + postJoins + `
+			WHERE p.status = 'ready' AND p.archived_at IS NULL AND ` + visibleToViewer + `
+			AND (p.created_at < $2 OR (p.created_at = $2 AND p.id < $3))
 			ORDER BY p.created_at DESC, p.id DESC
-			LIMIT $3
+			LIMIT $4
 		`
-		args = append(args, lastCreatedAt, lastId, limit)
+		args = append(args, viewerId, lastCreatedAt, lastId, limit)
 	}
 
 	rows, err := tx.Query(ctx, query, args...)
@@ -94,9 +205,7 @@ func (r *postRepositoryImpl) getPosts(ctx context.Context, limit int, lastCreate
 
 	var posts []shared.Post
 	for rows.Next() {
-		var post shared.Post
-		post.User = &shared.User{}
-		err := rows.Scan(&post.ID, &post.User.ID, &post.User.Username, &post.User.Avatar, &post.Image, &post.Description, &post.LikeCount, &post.CommentCount, &post.CreatedAt)
+		post, err := scanPost(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan post: %w", err)
 		}
@@ -106,10 +215,15 @@ func (r *postRepositoryImpl) getPosts(ctx context.Context, limit int, lastCreate
 		return nil, fmt.Errorf("error reading rows: %w", err)
 	}
 
+	err = attachPostDetails(ctx, tx, viewerId, posts)
+	if err != nil {
+		return nil, err
+	}
+
 	return posts, nil
 }
 
-func (r *postRepositoryImpl) getPost(ctx context.Context, id uuid.UUID) (shared.Post, error) {
+func (r *postRepositoryImpl) getPost(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) (shared.Post, error) {
 	tx, err := database.Postgres.Begin(ctx)
 	if err != nil {
 		return shared.Post{}, fmt.Errorf("failed to begin transaction: %w", err)
@@ -120,45 +234,223 @@ func (r *postRepositoryImpl) getPost(ctx context.Context, id uuid.UUID) (shared.
 	}()
 
 	query := `
-		SELECT p.id, p.user_id, u.username, u.avatar, p.image, p.description, p.like_count, p.comment_count, p.created_at
+		SELECT ` + postColumns + `,
+			ARRAY(SELECT t.name FROM post_tags pt INNER JOIN tags t ON t.id = pt.tag_id WHERE pt.post_id = p.id ORDER BY t.name)
 		FROM posts p
-		INNER JOIN users u ON p.user_id = u.id
-		WHERE p.id = $1
+		` + postJoins + `
+		WHERE p.id = $2 AND ((p.draft AND p.user_id = $1 AND p.deleted_at IS NULL) OR ` + visibleToViewer + `)
 	`
 
-	var post shared.Post
-	post.User = &shared.User{}
-	err = tx.QueryRow(
-		ctx,
-		query,
-		id,
-	).Scan(&post.ID, &post.User.ID, &post.User.Username, &post.User.Avatar, &post.Image, &post.Description, &post.LikeCount, &post.CommentCount, &post.CreatedAt)
+	// Posts the viewer is not allowed to read are reported as missing so that their existence is not leaked
+	var tags []string
+	post, err := scanPost(tx.QueryRow(ctx, query, viewerId, id), &tags)
 	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = &PostNotFoundError{}
+			return shared.Post{}, err
+		}
 		return shared.Post{}, fmt.Errorf("failed to scan post: %w", err)
 	}
+	post.Tags = tags
 
-	return post, nil
+	posts := []shared.Post{post}
+	err = attachPostDetails(ctx, tx, viewerId, posts)
+	if err != nil {
+		return shared.Post{}, err
+	}
+
+	return posts[0], nil
+}
+
+func (r *postRepositoryImpl) getBySearch(ctx context.Context, viewerId uuid.UUID, searchStr string) ([]shared.Post, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	// Both expressions are backed by gin indexes so image descriptions are searchable alongside post descriptions
+	query := `
+		SELECT ` + postColumns + `
+		FROM posts p
+		` + postJoins + `
+		WHERE p.status = 'ready' AND p.archived_at IS NULL AND ` + visibleToViewer + ` AND ` + unblurredForViewer + `
+		AND (
+			to_tsvector('simple', coalesce(p.description, '')) @@ plainto_tsquery('simple', $2)
+			OR EXISTS (
+				SELECT 1 FROM post_media s
+				WHERE s.post_id = p.id
+				AND to_tsvector('simple', coalesce(s.alt_text, '')) @@ plainto_tsquery('simple', $2)
+			)
+		)
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT $3
+	`
+
+	rows, err := tx.Query(ctx, query, viewerId, searchStr, maxSearchResults)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select posts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []shared.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	err = attachPostDetails(ctx, tx, viewerId, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
 }
 
-func (r *postRepositoryImpl) update(ctx context.Context, post shared.Post, id uuid.UUID) error {
+func (r *postRepositoryImpl) update(ctx context.Context, post shared.Post, id uuid.UUID, editWindow time.Duration) error {
 	tx, err := database.Postgres.Begin(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
 	}
 
+	// Stored files are only removed once the transaction has been committed
+	var removedFiles []string
+	defer func() {
+		if err == nil {
+			removeMediaFiles(removedFiles)
+		}
+	}()
+
 	defer func() {
 		database.HandleTransaction(ctx, tx, err)
 	}()
 
+	// Drafts can be edited freely, published posts only within the edit window and keeping a revision of each edit
+	var draft, expired bool
+	err = tx.QueryRow(
+		ctx,
+		"SELECT draft, $2::float8 > 0 AND created_at < (NOW() AT TIME ZONE 'utc') - make_interval(secs => $2::float8) FROM posts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE",
+		id, editWindow.Seconds(),
+	).Scan(&draft, &expired)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = &PostNotFoundError{}
+			return err
+		}
+
+		return fmt.Errorf("failed to select post: %w", err)
+	}
+
+	if !draft {
+		if expired {
+			err = &EditWindowExpiredError{}
+			return err
+		}
+
+		_, err = tx.Exec(
+			ctx,
+			`INSERT INTO post_revisions (post_id, description, visibility, media)
+			SELECT p.id, p.description, p.visibility, COALESCE((
+				SELECT jsonb_agg(jsonb_build_object('id', m.id, 'position', m.position, 'type', m.type, 'altText', m.alt_text) ORDER BY m.position)
+				FROM post_media m WHERE m.post_id = p.id
+			), '[]')
+			FROM posts p WHERE p.id = $1`,
+			id,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert post revision: %w", err)
+		}
+	}
+
+	var keptIds []uuid.UUID
+	for _, item := range post.Media {
+		if item.ID != uuid.Nil {
+			keptIds = append(keptIds, item.ID)
+		}
+	}
+
+	rows, err := tx.Query(ctx, "DELETE FROM post_media WHERE post_id = $1 AND NOT (id = ANY($2)) RETURNING video_path", id, keptIds)
+	if err != nil {
+		return fmt.Errorf("failed to delete post media: %w", err)
+	}
+	removedFiles, err = scanVideoPaths(rows)
+	if err != nil {
+		return err
+	}
+
+	// Move kept media out of the way so positions can be rewritten without breaking uniqueness
+	_, err = tx.Exec(ctx, "UPDATE post_media SET position = -position - 1 WHERE post_id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to update post media: %w", err)
+	}
+
+	var newMedia []shared.PostMedia
+	for _, item := range post.Media {
+		if item.ID == uuid.Nil {
+			newMedia = append(newMedia, item)
+			continue
+		}
+
+		var tag pgconn.CommandTag
+		tag, err = tx.Exec(
+			ctx,
+			"UPDATE post_media SET position = $1, alt_text = $2 WHERE id = $3 AND post_id = $4",
+			item.Position, item.AltText, item.ID, id,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to update post media: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			err = fmt.Errorf("post media %v not found", item.ID)
+			return err
+		}
+	}
+
+	err = insertPostMedia(ctx, tx, id, newMedia)
+	if err != nil {
+		return err
+	}
+
+	placeId, err := upsertPlace(ctx, tx, post.Place)
+	if err != nil {
+		return err
+	}
+
 	_, err = tx.Exec(
 		ctx,
-		"UPDATE posts SET image = $1, description = $2 WHERE id = $3",
-		post.Image, post.Description, id,
+		`UPDATE posts SET description = $1, visibility = $3, place_id = $4, sensitive = $6, comment_policy = $7,
+		content_warning = COALESCE($5, CASE WHEN flagged_sensitive THEN content_warning END),
+		edited_at = CASE WHEN draft THEN edited_at ELSE (NOW() AT TIME ZONE 'utc') END,
+		status = CASE
+			WHEN EXISTS (SELECT 1 FROM post_media WHERE post_id = $2 AND status = 'failed') THEN 'failed'
+			WHEN EXISTS (SELECT 1 FROM post_media WHERE post_id = $2 AND status = 'processing') THEN 'processing'
+			ELSE 'ready'
+		END WHERE id = $2`,
+		post.Description, id, post.Visibility, placeId, post.ContentWarning, post.Sensitive, post.CommentPolicy,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to update post: %w", err)
 	}
 
+	err = syncPostTags(ctx, tx, id, post.Tags)
+	if err != nil {
+		return err
+	}
+
+	err = syncPostMentions(ctx, tx, id, post.Mentions)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
@@ -172,9 +464,122 @@ func (r *postRepositoryImpl) delete(ctx context.Context, id uuid.UUID) error {
 		database.HandleTransaction(ctx, tx, err)
 	}()
 
-	_, err = tx.Exec(ctx, "DELETE FROM posts WHERE id = $1", id)
+	// Reposts hold no content of their own and are removed right away, other posts are moved to the trash
+	var userId uuid.UUID
+	var originalId *uuid.UUID
+	err = tx.QueryRow(ctx, "DELETE FROM posts WHERE id = $1 AND repost_of IS NOT NULL RETURNING user_id, repost_of", id).Scan(&userId, &originalId)
+	if err == pgx.ErrNoRows {
+		err = tx.QueryRow(
+			ctx,
+			"UPDATE posts SET deleted_at = (NOW() AT TIME ZONE 'utc'), pinned_at = NULL WHERE id = $1 AND deleted_at IS NULL RETURNING user_id, quote_of",
+			id,
+		).Scan(&userId, &originalId)
+	}
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = nil
+			return nil
+		}
+		return fmt.Errorf("failed to delete post: %w", err)
+	}
+
+	if originalId != nil {
+		err = updateRepostCount(ctx, tx, *originalId)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = updatePostCount(ctx, tx, userId)
+	if err != nil {
+		return err
+	}
+
+	err = updateReposterPostCounts(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *postRepositoryImpl) repost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) (uuid.UUID, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	// Reposting a repost reposts the original post
+	var originalId uuid.UUID
+	err = tx.QueryRow(ctx, "SELECT COALESCE(p.repost_of, p.id) FROM posts p WHERE p.id = $2 AND "+visibleToViewer, userId, postId).Scan(&originalId)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = &PostNotFoundError{}
+		}
+		return uuid.Nil, err
+	}
+
+	var id uuid.UUID
+	err = tx.QueryRow(
+		ctx,
+		`INSERT INTO posts (user_id, repost_of, status) VALUES ($1, $2, 'ready')
+		ON CONFLICT (user_id, repost_of) WHERE repost_of IS NOT NULL DO UPDATE SET repost_of = EXCLUDED.repost_of
+		RETURNING id`,
+		userId, originalId,
+	).Scan(&id)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("failed to insert repost: %w", err)
+	}
+
+	err = updateRepostCount(ctx, tx, originalId)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	err = updatePostCount(ctx, tx, userId)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
+
+func (r *postRepositoryImpl) unrepost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	var originalId uuid.UUID
+	err = tx.QueryRow(ctx, "SELECT COALESCE(repost_of, id) FROM posts WHERE id = $1", postId).Scan(&originalId)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = &PostNotFoundError{}
+		}
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM posts WHERE user_id = $1 AND repost_of = $2", userId, originalId)
+	if err != nil {
+		return fmt.Errorf("failed to delete repost: %w", err)
+	}
+
+	err = updateRepostCount(ctx, tx, originalId)
 	if err != nil {
-		return fmt.Errorf("failed to delete user: %w", err)
+		return err
+	}
+
+	err = updatePostCount(ctx, tx, userId)
+	if err != nil {
+		return err
 	}
 
 	return nil
@@ -190,19 +595,31 @@ func (i *postRepositoryImpl) like(ctx context.Context, userId uuid.UUID, postId
 		database.HandleTransaction(ctx, tx, err)
 	}()
 
+	err = checkPostVisible(ctx, tx, userId, postId)
+	if err != nil {
+		return err
+	}
+
+	// A like is the heart reaction and replaces any other reaction of the user
 	_, err = tx.Exec(
 		ctx,
-		"INSERT INTO likes (user_id, post_id) VALUES ($1, $2)",
-		userId, postId,
+		`INSERT INTO likes (user_id, post_id, reaction) VALUES ($1, $2, $3)
+		ON CONFLICT (post_id, user_id) DO UPDATE SET reaction = EXCLUDED.reaction, created_at = EXCLUDED.created_at`,
+		userId, postId, ReactionHeart,
 	)
 	if err != nil {
 		return fmt.Errorf("failed to insert like: %w", err)
 	}
 
+	err = updateLikeCount(ctx, tx, postId)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func (i *postRepositoryImpl) getLikes(ctx context.Context, id uuid.UUID) ([]shared.User, error) {
+func (i *postRepositoryImpl) getLikes(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) ([]shared.User, error) {
 	tx, err := database.Postgres.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -212,7 +629,12 @@ func (i *postRepositoryImpl) getLikes(ctx context.Context, id uuid.UUID) ([]shar
 		database.HandleTransaction(ctx, tx, err)
 	}()
 
-	rows, err := tx.Query(ctx, "SELECT u.id, u.username, u.full_name, u.avatar FROM likes l JOIN users u ON l.user_id = u.id WHERE l.post_id = $1", id)
+	err = checkPostVisible(ctx, tx, viewerId, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(ctx, "SELECT u.id, u.username, u.full_name, u.avatar FROM likes l JOIN users u ON l.user_id = u.id WHERE l.post_id = $1 AND l.reaction = $2", id, ReactionHeart)
 	if err != nil {
 		return nil, fmt.Errorf("failed to select likes: %w", err)
 	}
@@ -243,15 +665,20 @@ func (i *postRepositoryImpl) unlike(ctx context.Context, userId uuid.UUID, postI
 		database.HandleTransaction(ctx, tx, err)
 	}()
 
-	_, err = tx.Exec(ctx, "DELETE FROM likes WHERE user_id = $1 AND post_id = $2", userId, postId)
+	_, err = tx.Exec(ctx, "DELETE FROM likes WHERE user_id = $1 AND post_id = $2 AND reaction = $3", userId, postId, ReactionHeart)
 	if err != nil {
 		return fmt.Errorf("failed to delete like: %w", err)
 	}
 
+	err = updateLikeCount(ctx, tx, postId)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
-func (i *postRepositoryImpl) userLikedPost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) (bool, error) {
+func (i *postRepositoryImpl) userLikedPost(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID, postId uuid.UUID) (bool, error) {
 	tx, err := database.Postgres.Begin(ctx)
 	if err != nil {
 		return false, fmt.Errorf("failed to begin transaction: %w", err)
@@ -261,11 +688,1927 @@ func (i *postRepositoryImpl) userLikedPost(ctx context.Context, userId uuid.UUID
 		database.HandleTransaction(ctx, tx, err)
 	}()
 
+	err = checkPostVisible(ctx, tx, viewerId, postId)
+	if err != nil {
+		return false, err
+	}
+
 	var exists bool
-	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM likes WHERE user_id = $1 AND post_id = $2)", userId, postId).Scan(&exists)
+	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM likes WHERE user_id = $1 AND post_id = $2 AND reaction = $3)", userId, postId, ReactionHeart).Scan(&exists)
 	if err != nil {
 		return false, fmt.Errorf("failed to check if user liked post: %w", err)
 	}
 
 	return exists, nil
 }
+
+func (r *postRepositoryImpl) react(ctx context.Context, userId uuid.UUID, postId uuid.UUID, reaction string) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	err = checkPostVisible(ctx, tx, userId, postId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		`INSERT INTO likes (user_id, post_id, reaction) VALUES ($1, $2, $3)
+		ON CONFLICT (post_id, user_id) DO UPDATE SET reaction = EXCLUDED.reaction, created_at = EXCLUDED.created_at`,
+		userId, postId, reaction,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert reaction: %w", err)
+	}
+
+	// Switching between a heart and another reaction changes the like count
+	err = updateLikeCount(ctx, tx, postId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *postRepositoryImpl) unreact(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	_, err = tx.Exec(ctx, "DELETE FROM likes WHERE user_id = $1 AND post_id = $2", userId, postId)
+	if err != nil {
+		return fmt.Errorf("failed to delete reaction: %w", err)
+	}
+
+	err = updateLikeCount(ctx, tx, postId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *postRepositoryImpl) getReactions(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID, reaction string) ([]Reaction, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	err = checkPostVisible(ctx, tx, viewerId, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT u.id, u.username, u.full_name, u.avatar, l.reaction, l.created_at
+		FROM likes l
+		INNER JOIN users u ON l.user_id = u.id
+		WHERE l.post_id = $1 AND ($2 = '' OR l.reaction = $2)
+		ORDER BY l.created_at DESC
+	`
+
+	rows, err := tx.Query(ctx, query, postId, reaction)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select reactions: %w", err)
+	}
+	defer rows.Close()
+
+	var reactions []Reaction
+	for rows.Next() {
+		reaction := Reaction{User: &shared.User{}}
+		if err := rows.Scan(&reaction.User.ID, &reaction.User.Username, &reaction.User.FullName, &reaction.User.Avatar, &reaction.Type, &reaction.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan reaction: %w", err)
+		}
+		reactions = append(reactions, reaction)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return reactions, nil
+}
+
+func (r *postRepositoryImpl) getDrafts(ctx context.Context, userId uuid.UUID) ([]shared.Post, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	// Scheduled drafts come first, in the order they will be published
+	query := `
+		SELECT ` + postColumns + `
+		FROM posts p
+		` + postJoins + `
+		WHERE p.user_id = $1 AND p.draft AND p.deleted_at IS NULL
+		ORDER BY p.scheduled_at ASC NULLS LAST, p.created_at DESC, p.id DESC
+	`
+
+	rows, err := tx.Query(ctx, query, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select drafts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []shared.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	err = attachPostDetails(ctx, tx, userId, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+func (r *postRepositoryImpl) publish(ctx context.Context, id uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	// Published drafts are dated from their publication so that they show up at the top of the feed
+	var userId uuid.UUID
+	var quoteOf *uuid.UUID
+	err = tx.QueryRow(
+		ctx,
+		"UPDATE posts SET draft = false, scheduled_at = NULL, created_at = (NOW() AT TIME ZONE 'utc') WHERE id = $1 AND draft AND deleted_at IS NULL RETURNING user_id, quote_of",
+		id,
+	).Scan(&userId, &quoteOf)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = &PostNotDraftError{}
+			return err
+		}
+
+		return fmt.Errorf("failed to publish post: %w", err)
+	}
+
+	if quoteOf != nil {
+		err = updateRepostCount(ctx, tx, *quoteOf)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = updatePostCount(ctx, tx, userId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *postRepositoryImpl) schedule(ctx context.Context, id uuid.UUID, scheduledAt *time.Time) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	tag, err := tx.Exec(ctx, "UPDATE posts SET scheduled_at = $2 WHERE id = $1 AND draft AND deleted_at IS NULL", id, scheduledAt)
+	if err != nil {
+		return fmt.Errorf("failed to schedule post: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		err = &PostNotDraftError{}
+		return err
+	}
+
+	return nil
+}
+
+func (r *postRepositoryImpl) getRevisions(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID) ([]PostRevision, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	err = checkPostVisible(ctx, tx, viewerId, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(
+		ctx,
+		"SELECT id, post_id, description, visibility, media, created_at FROM post_revisions WHERE post_id = $1 ORDER BY created_at DESC, id DESC",
+		postId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select post revisions: %w", err)
+	}
+	defer rows.Close()
+
+	var revisions []PostRevision
+	for rows.Next() {
+		var revision PostRevision
+		var media []byte
+		err := rows.Scan(&revision.ID, &revision.PostID, &revision.Description, &revision.Visibility, &media, &revision.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post revision: %w", err)
+		}
+		if err := json.Unmarshal(media, &revision.Media); err != nil {
+			return nil, fmt.Errorf("failed to decode post revision media: %w", err)
+		}
+		revisions = append(revisions, revision)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return revisions, nil
+}
+
+func (r *postRepositoryImpl) getTrash(ctx context.Context, userId uuid.UUID) ([]shared.Post, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	query := `
+		SELECT ` + postColumns + `
+		FROM posts p
+		` + postJoins + `
+		WHERE p.user_id = $1 AND p.deleted_at >= (NOW() AT TIME ZONE 'utc') - make_interval(secs => $2::float8)
+		ORDER BY p.deleted_at DESC, p.id DESC
+	`
+
+	rows, err := tx.Query(ctx, query, userId, shared.TrashRetention.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to select deleted posts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []shared.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	err = attachPostDetails(ctx, tx, userId, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+func (r *postRepositoryImpl) restore(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	var quoteOf *uuid.UUID
+	err = tx.QueryRow(
+		ctx,
+		`UPDATE posts SET deleted_at = NULL
+		WHERE id = $1 AND user_id = $2 AND deleted_at >= (NOW() AT TIME ZONE 'utc') - make_interval(secs => $3::float8)
+		RETURNING quote_of`,
+		id, userId, shared.TrashRetention.Seconds(),
+	).Scan(&quoteOf)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = &PostNotFoundError{}
+			return err
+		}
+
+		return fmt.Errorf("failed to restore post: %w", err)
+	}
+
+	if quoteOf != nil {
+		err = updateRepostCount(ctx, tx, *quoteOf)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = updatePostCount(ctx, tx, userId)
+	if err != nil {
+		return err
+	}
+
+	err = updateReposterPostCounts(ctx, tx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *postRepositoryImpl) archive(ctx context.Context, id uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	var userId uuid.UUID
+	var draft bool
+	err = tx.QueryRow(ctx, "SELECT user_id, draft FROM posts WHERE id = $1 AND deleted_at IS NULL FOR UPDATE", id).Scan(&userId, &draft)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = &PostNotFoundError{}
+			return err
+		}
+
+		return fmt.Errorf("failed to select post: %w", err)
+	}
+
+	if draft {
+		err = &PostIsDraftError{}
+		return err
+	}
+
+	// Archiving an archived post keeps the time it was first archived, archived posts leaving the profile are unpinned
+	_, err = tx.Exec(ctx, "UPDATE posts SET archived_at = COALESCE(archived_at, (NOW() AT TIME ZONE 'utc')), pinned_at = NULL WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to archive post: %w", err)
+	}
+
+	err = updatePostCount(ctx, tx, userId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *postRepositoryImpl) unarchive(ctx context.Context, id uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	var userId uuid.UUID
+	err = tx.QueryRow(ctx, "UPDATE posts SET archived_at = NULL WHERE id = $1 AND deleted_at IS NULL RETURNING user_id", id).Scan(&userId)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = &PostNotFoundError{}
+			return err
+		}
+
+		return fmt.Errorf("failed to unarchive post: %w", err)
+	}
+
+	err = updatePostCount(ctx, tx, userId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *postRepositoryImpl) pin(ctx context.Context, id uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	var userId uuid.UUID
+	var draft, archived, pinned bool
+	err = tx.QueryRow(
+		ctx,
+		"SELECT user_id, draft, archived_at IS NOT NULL, pinned_at IS NOT NULL FROM posts WHERE id = $1 AND deleted_at IS NULL",
+		id,
+	).Scan(&userId, &draft, &archived, &pinned)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = &PostNotFoundError{}
+			return err
+		}
+
+		return fmt.Errorf("failed to select post: %w", err)
+	}
+
+	switch {
+	case draft:
+		err = &PostIsDraftError{}
+		return err
+	case archived:
+		err = &PostIsArchivedError{}
+		return err
+	case pinned:
+		return nil
+	}
+
+	// The row of the user is locked so that concurrent pins can not go over the limit
+	_, err = tx.Exec(ctx, "SELECT 1 FROM users WHERE id = $1 FOR UPDATE", userId)
+	if err != nil {
+		return fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	var pinnedCount int
+	err = tx.QueryRow(ctx, "SELECT COUNT(*) FROM posts WHERE user_id = $1 AND pinned_at IS NOT NULL", userId).Scan(&pinnedCount)
+	if err != nil {
+		return fmt.Errorf("failed to count pinned posts: %w", err)
+	}
+
+	if pinnedCount >= maxPinnedPosts {
+		err = &TooManyPinnedPostsError{}
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE posts SET pinned_at = (NOW() AT TIME ZONE 'utc') WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to pin post: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postRepositoryImpl) unpin(ctx context.Context, id uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	tag, err := tx.Exec(ctx, "UPDATE posts SET pinned_at = NULL WHERE id = $1 AND deleted_at IS NULL", id)
+	if err != nil {
+		return fmt.Errorf("failed to unpin post: %w", err)
+	}
+
+	if tag.RowsAffected() == 0 {
+		err = &PostNotFoundError{}
+		return err
+	}
+
+	return nil
+}
+
+func (r *postRepositoryImpl) vote(ctx context.Context, userId uuid.UUID, postId uuid.UUID, optionIds []uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	var multipleChoice, closed bool
+	err = tx.QueryRow(
+		ctx,
+		`SELECT pl.multiple_choice, pl.closes_at <= (NOW() AT TIME ZONE 'utc')
+		FROM polls pl
+		INNER JOIN posts p ON p.id = pl.post_id
+		WHERE pl.post_id = $2 AND `+visibleToViewer,
+		userId, postId,
+	).Scan(&multipleChoice, &closed)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = &PollNotFoundError{}
+			return err
+		}
+
+		return fmt.Errorf("failed to select poll: %w", err)
+	}
+
+	if closed {
+		err = &PollClosedError{}
+		return err
+	}
+
+	var optionCount int
+	err = tx.QueryRow(ctx, "SELECT COUNT(*) FROM poll_options WHERE post_id = $1 AND id = ANY($2)", postId, optionIds).Scan(&optionCount)
+	if err != nil {
+		return fmt.Errorf("failed to count poll options: %w", err)
+	}
+
+	if optionCount != len(optionIds) || (!multipleChoice && len(optionIds) > 1) {
+		err = &InvalidVoteError{}
+		return err
+	}
+
+	// The voter row is unique per poll, a user who already voted failing to vote again
+	_, err = tx.Exec(ctx, "INSERT INTO poll_voters (post_id, user_id) VALUES ($1, $2)", postId, userId)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			err = &AlreadyVotedError{}
+			return err
+		}
+
+		return fmt.Errorf("failed to insert poll voter: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO poll_votes (option_id, post_id, user_id) SELECT unnest($3::uuid[]), $1, $2", postId, userId, optionIds)
+	if err != nil {
+		return fmt.Errorf("failed to insert poll votes: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postRepositoryImpl) save(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	err = checkPostVisible(ctx, tx, userId, postId)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO saves (user_id, post_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", userId, postId)
+	if err != nil {
+		return fmt.Errorf("failed to insert save: %w", err)
+	}
+
+	return nil
+}
+
+// unsave removes a saved post, along with it from the collections of the user
+func (r *postRepositoryImpl) unsave(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	_, err = tx.Exec(
+		ctx,
+		"DELETE FROM collection_posts cp USING collections c WHERE cp.collection_id = c.id AND c.user_id = $1 AND cp.post_id = $2",
+		userId, postId,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection posts: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM saves WHERE user_id = $1 AND post_id = $2", userId, postId)
+	if err != nil {
+		return fmt.Errorf("failed to delete save: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postRepositoryImpl) userSavedPost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) (bool, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	err = checkPostVisible(ctx, tx, userId, postId)
+	if err != nil {
+		return false, err
+	}
+
+	var exists bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM saves WHERE user_id = $1 AND post_id = $2)", userId, postId).Scan(&exists)
+	if err != nil {
+		return false, fmt.Errorf("failed to check if user saved post: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (r *postRepositoryImpl) getVideoPath(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID, mediaId uuid.UUID) (string, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	var path string
+	err = tx.QueryRow(
+		ctx,
+		`SELECT m.video_path FROM post_media m
+		INNER JOIN posts p ON p.id = m.post_id
+		WHERE m.id = $2 AND m.post_id = $3 AND m.type = 'video' AND m.status = 'ready' AND `+visibleToViewer,
+		viewerId, mediaId, postId,
+	).Scan(&path)
+	if err != nil {
+		return "", fmt.Errorf("failed to scan post video: %w", err)
+	}
+
+	return path, nil
+}
+
+func (r *postRepositoryImpl) matchBannedImage(ctx context.Context, hashes []uint64, maxDistance int) (bool, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	params := make([]int64, len(hashes))
+	for i, hash := range hashes {
+		params[i] = int64(hash)
+	}
+
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM banned_images b, unnest($1::bigint[]) h
+			WHERE bit_count((b.hash # h)::bit(64)) <= $2
+		)
+	`
+
+	var matched bool
+	err = tx.QueryRow(ctx, query, params, maxDistance).Scan(&matched)
+	if err != nil {
+		return false, fmt.Errorf("failed to check banned images: %w", err)
+	}
+
+	return matched, nil
+}
+
+func (r *postRepositoryImpl) banImage(ctx context.Context, bannedImage BannedImage) (BannedImage, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return BannedImage{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	hash, err := media.ParseHash(bannedImage.Hash)
+	if err != nil {
+		return BannedImage{}, err
+	}
+
+	err = tx.QueryRow(
+		ctx,
+		"INSERT INTO banned_images (hash, reason, created_by) VALUES ($1, $2, $3) RETURNING id, created_at",
+		int64(hash), bannedImage.Reason, bannedImage.CreatedBy,
+	).Scan(&bannedImage.ID, &bannedImage.CreatedAt)
+	if err != nil {
+		return BannedImage{}, fmt.Errorf("failed to insert banned image: %w", err)
+	}
+	bannedImage.Image = ""
+
+	return bannedImage, nil
+}
+
+func (r *postRepositoryImpl) getBannedImages(ctx context.Context) ([]BannedImage, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	rows, err := tx.Query(ctx, "SELECT id, hash, reason, created_by, created_at FROM banned_images ORDER BY created_at DESC")
+	if err != nil {
+		return nil, fmt.Errorf("failed to select banned images: %w", err)
+	}
+	defer rows.Close()
+
+	var bannedImages []BannedImage
+	for rows.Next() {
+		var bannedImage BannedImage
+		var hash int64
+		var createdBy *uuid.UUID
+		if err := rows.Scan(&bannedImage.ID, &hash, &bannedImage.Reason, &createdBy, &bannedImage.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan banned image: %w", err)
+		}
+		bannedImage.Hash = media.FormatHash(uint64(hash))
+		if createdBy != nil {
+			bannedImage.CreatedBy = *createdBy
+		}
+		bannedImages = append(bannedImages, bannedImage)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return bannedImages, nil
+}
+
+func (r *postRepositoryImpl) unbanImage(ctx context.Context, id uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	_, err = tx.Exec(ctx, "DELETE FROM banned_images WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete banned image: %w", err)
+	}
+
+	return nil
+}
+
+func (r *postRepositoryImpl) getSimilarImages(ctx context.Context, postId uuid.UUID, maxDistance int, limit int) ([]SimilarImage, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	query := `
+		SELECT DISTINCT ON (o.id) o.post_id, o.id, u.id, u.username, u.avatar, o.image,
+			bit_count((o.phash # m.phash)::bit(64)) AS distance, o.duplicate_of, o.created_at
+		FROM post_media m
+		INNER JOIN post_media o ON o.post_id <> m.post_id AND o.phash IS NOT NULL
+			AND bit_count((o.phash # m.phash)::bit(64)) <= $2
+		INNER JOIN posts p ON p.id = o.post_id
+		INNER JOIN users u ON u.id = p.user_id
+		WHERE m.post_id = $1 AND m.phash IS NOT NULL
+		ORDER BY o.id, distance
+	`
+
+	rows, err := tx.Query(ctx, "SELECT * FROM ("+query+") s ORDER BY distance, created_at LIMIT $3", postId, maxDistance, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select similar images: %w", err)
+	}
+	defer rows.Close()
+
+	var images []SimilarImage
+	for rows.Next() {
+		var image SimilarImage
+		image.User = &shared.User{}
+		if err := rows.Scan(&image.PostID, &image.MediaID, &image.User.ID, &image.User.Username, &image.User.Avatar, &image.Image, &image.Distance, &image.DuplicateOf, &image.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan similar image: %w", err)
+		}
+		images = append(images, image)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return images, nil
+}
+
+func (r *postRepositoryImpl) getPostsByTag(ctx context.Context, viewerId uuid.UUID, tag string, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	var query string
+	var args []interface{}
+
+	if lastCreatedAt.IsZero() && lastId == uuid.Nil {
+		query = `
+			SELECT ` + postColumns + `
+			FROM posts p
+			` + postJoins + `
+			INNER JOIN post_tags pt ON pt.post_id = p.id
+			INNER JOIN tags t ON t.id = pt.tag_id
+			WHERE t.name = $2 AND p.status = 'ready' AND p.archived_at IS NULL AND ` + visibleToViewer + ` AND ` + unblurredForViewer + `
+			ORDER BY p.created_at DESC, p.id DESC
+			LIMIT $3
+		`
+		args = append(args, viewerId, tag, limit)
+	} else {
+		query = `
+			SELECT ` + postColumns + `
+			FROM posts p
+			` + postJoins + `
+			INNER JOIN post_tags pt ON pt.post_id = p.id
+			INNER JOIN tags t ON t.id = pt.tag_id
+			WHERE t.name = $2 AND p.status = 'ready' AND p.archived_at IS NULL AND ` + visibleToViewer + ` AND ` + unblurredForViewer + `
+			AND (p.created_at < $3 OR (p.created_at = $3 AND p.id < $4))
+			ORDER BY p.created_at DESC, p.id DESC
+			LIMIT $5
+		`
+		args = append(args, viewerId, tag, lastCreatedAt, lastId, limit)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select posts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []shared.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	err = attachPostDetails(ctx, tx, viewerId, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// getNearbyPosts lists the most recent posts tagged with a place within radius metres of a position. The places are
+// narrowed down to a box around the position by their index before their great-circle distances are computed
+func (r *postRepositoryImpl) getNearbyPosts(ctx context.Context, viewerId uuid.UUID, latitude float64, longitude float64, radius float64, limit int) ([]shared.Post, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	minLatitude, minLongitude, maxLatitude, maxLongitude := nearbyBounds(latitude, longitude, radius)
+
+	query := `
+		SELECT ` + postColumns + `
+		FROM posts p
+		` + postJoins + `
+		WHERE loc.id IS NOT NULL AND p.status = 'ready' AND p.archived_at IS NULL AND ` + visibleToViewer + ` AND ` + unblurredForViewer + `
+		AND point(loc.longitude, loc.latitude) <@ box(point($2, $3), point($4, $5))
+		AND 2 * $6::float8 * asin(least(1, sqrt(
+			power(sin(radians(loc.latitude - $7::float8) / 2), 2)
+			+ cos(radians($7::float8)) * cos(radians(loc.latitude)) * power(sin(radians(loc.longitude - $8::float8) / 2), 2)
+		))) <= $9
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT $10
+	`
+
+	rows, err := tx.Query(ctx, query, viewerId, minLongitude, minLatitude, maxLongitude, maxLatitude, earthRadius, latitude, longitude, radius, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select nearby posts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []shared.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	err = attachPostDetails(ctx, tx, viewerId, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+func (r *postRepositoryImpl) getPostsByPlace(ctx context.Context, viewerId uuid.UUID, placeId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	var query string
+	var args []interface{}
+
+	if lastCreatedAt.IsZero() && lastId == uuid.Nil {
+		query = `
+			SELECT ` + postColumns + `
+			FROM posts p
+			` + postJoins + `
+			WHERE loc.id = $2 AND p.status = 'ready' AND p.archived_at IS NULL AND ` + visibleToViewer + ` AND ` + unblurredForViewer + `
+			ORDER BY p.created_at DESC, p.id DESC
+			LIMIT $3
+		`
+		args = append(args, viewerId, placeId, limit)
+	} else {
+		query = `
+			SELECT ` + postColumns + `
+			FROM posts p
+			` + postJoins + `
+			WHERE loc.id = $2 AND p.status = 'ready' AND p.archived_at IS NULL AND ` + visibleToViewer + ` AND ` + unblurredForViewer + `
+			AND (p.created_at < $3 OR (p.created_at = $3 AND p.id < $4))
+			ORDER BY p.created_at DESC, p.id DESC
+			LIMIT $5
+		`
+		args = append(args, viewerId, placeId, lastCreatedAt, lastId, limit)
+	}
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select posts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []shared.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	err = attachPostDetails(ctx, tx, viewerId, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+// flagSensitive marks a post as sensitive on behalf of the moderators, or lifts their flag. The content warning given
+// replaces the one of the post, which is kept when none is given and cleared along with the flag unless the author
+// marked the post as sensitive
+func (r *postRepositoryImpl) flagSensitive(ctx context.Context, id uuid.UUID, sensitive bool, contentWarning *string) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	tag, err := tx.Exec(
+		ctx,
+		`UPDATE posts SET flagged_sensitive = $2,
+		content_warning = CASE WHEN $2 THEN COALESCE($3, content_warning) WHEN sensitive THEN content_warning END
+		WHERE id = $1 AND deleted_at IS NULL`,
+		id, sensitive, contentWarning,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update post sensitivity: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		err = &PostNotFoundError{}
+		return err
+	}
+
+	return nil
+}
+
+// getTrendingTags ranks the tags used by the most posts created within the given window, the most recently used first on ties.
+// Sensitive posts are left out as trending tags are shown to everyone
+func (r *postRepositoryImpl) getTrendingTags(ctx context.Context, window time.Duration, limit int) ([]TrendingTag, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	query := `
+		SELECT t.name, COUNT(*) AS post_count
+		FROM post_tags pt
+		INNER JOIN tags t ON t.id = pt.tag_id
+		INNER JOIN posts p ON p.id = pt.post_id
+		WHERE p.status = 'ready' AND p.visibility = 'public' AND NOT p.draft AND p.deleted_at IS NULL AND p.archived_at IS NULL AND NOT ` + shared.PostSensitive("p") + `
+		AND p.created_at >= (NOW() AT TIME ZONE 'utc') - make_interval(secs => $1)
+		GROUP BY t.id, t.name
+		ORDER BY post_count DESC, MAX(p.created_at) DESC
+		LIMIT $2
+	`
+
+	rows, err := tx.Query(ctx, query, window.Seconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select trending tags: %w", err)
+	}
+	defer rows.Close()
+
+	tags := []TrendingTag{}
+	for rows.Next() {
+		var tag TrendingTag
+		err := rows.Scan(&tag.Name, &tag.PostCount)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan trending tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (r *postRepositoryImpl) getUserIdsByUsernames(ctx context.Context, usernames []string) (map[string]uuid.UUID, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	userIds, err := shared.GetUserIdsByUsernames(ctx, tx, usernames)
+	if err != nil {
+		return nil, err
+	}
+
+	return userIds, nil
+}
+
+// claimVideo leases the oldest video waiting to be processed, skipping rows another instance is working on
+func (r *postRepositoryImpl) claimVideo(ctx context.Context, leaseDuration time.Duration) (shared.PostMedia, uuid.UUID, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return shared.PostMedia{}, uuid.Nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	query := `
+		UPDATE post_media SET processing_started_at = (NOW() AT TIME ZONE 'utc')
+		WHERE id = (
+			SELECT id FROM post_media
+			WHERE status = 'processing'
+			AND (processing_started_at IS NULL OR processing_started_at < (NOW() AT TIME ZONE 'utc') - $1::interval)
+			ORDER BY created_at
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING id, post_id, video_path
+	`
+
+	var item shared.PostMedia
+	var postId uuid.UUID
+	err = tx.QueryRow(ctx, query, leaseDuration).Scan(&item.ID, &postId, &item.VideoPath)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = nil
+			return shared.PostMedia{}, uuid.Nil, nil
+		}
+
+		return shared.PostMedia{}, uuid.Nil, fmt.Errorf("failed to claim post video: %w", err)
+	}
+
+	return item, postId, nil
+}
+
+// completeVideo stores the extracted metadata of a processed video and marks its post ready once nothing else is pending
+func (r *postRepositoryImpl) completeVideo(ctx context.Context, postId uuid.UUID, item shared.PostMedia) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	_, err = tx.Exec(
+		ctx,
+		`UPDATE post_media SET status = 'ready', image = $1, video_duration = $2, image_width = $3, image_height = $4, image_color = $5, image_blurhash = $6, phash = $7,
+			duplicate_of = (SELECT post_id FROM post_media WHERE bit_count((phash # $7)::bit(64)) <= $10 AND post_id <> $8 ORDER BY created_at LIMIT 1)
+		WHERE id = $9`,
+		item.Image, item.Duration, item.Width, item.Height, item.DominantColor, item.BlurHash, hashToInt64(item.Hash), postId, item.ID, BannedImageMaxDistance,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update post media: %w", err)
+	}
+
+	_, err = tx.Exec(
+		ctx,
+		"UPDATE posts SET status = 'ready' WHERE id = $1 AND status = 'processing' AND NOT EXISTS (SELECT 1 FROM post_media WHERE post_id = $1 AND status <> 'ready')",
+		postId,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update post status: %w", err)
+	}
+
+	return nil
+}
+
+// failVideo marks a video that could not be processed, and its post, as failed, removing the stored file
+// once the transaction has been committed
+func (r *postRepositoryImpl) failVideo(ctx context.Context, postId uuid.UUID, mediaId uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	var videoPath *string
+	defer func() {
+		if err == nil && videoPath != nil {
+			removeMediaFiles([]string{*videoPath})
+		}
+	}()
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	err = tx.QueryRow(
+		ctx,
+		`UPDATE post_media m SET status = 'failed', video_path = NULL
+		FROM (SELECT id, video_path FROM post_media WHERE id = $1 FOR UPDATE) old
+		WHERE m.id = old.id
+		RETURNING old.video_path`,
+		mediaId,
+	).Scan(&videoPath)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			// The post has been purged while its video was being processed
+			err = nil
+			return nil
+		}
+
+		return fmt.Errorf("failed to update post media: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, "UPDATE posts SET status = 'failed' WHERE id = $1", postId)
+	if err != nil {
+		return fmt.Errorf("failed to update post status: %w", err)
+	}
+
+	return nil
+}
+
+// publishDueDrafts publishes up to limit drafts whose scheduled time has passed, returning how many were published.
+// Rows locked by another instance are skipped and published drafts no longer match, so no draft is published twice
+func (r *postRepositoryImpl) publishDueDrafts(ctx context.Context, limit int) (int, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	query := `
+		UPDATE posts SET draft = false, scheduled_at = NULL, created_at = (NOW() AT TIME ZONE 'utc')
+		WHERE id IN (
+			SELECT id FROM posts
+			WHERE draft AND deleted_at IS NULL AND scheduled_at <= (NOW() AT TIME ZONE 'utc')
+			ORDER BY scheduled_at
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING user_id, quote_of
+	`
+
+	rows, err := tx.Query(ctx, query, limit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to publish scheduled posts: %w", err)
+	}
+
+	published := 0
+	var quoted []uuid.UUID
+	authors := make(map[uuid.UUID]bool)
+	for rows.Next() {
+		var userId uuid.UUID
+		var quoteOf *uuid.UUID
+		err = rows.Scan(&userId, &quoteOf)
+		if err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan published post: %w", err)
+		}
+		published++
+		authors[userId] = true
+		if quoteOf != nil {
+			quoted = append(quoted, *quoteOf)
+		}
+	}
+	rows.Close()
+	err = rows.Err()
+	if err != nil {
+		return 0, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	for _, postId := range quoted {
+		err = updateRepostCount(ctx, tx, postId)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	for userId := range authors {
+		err = updatePostCount(ctx, tx, userId)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return published, nil
+}
+
+// purgeDeleted permanently removes up to limit posts that have been in the trash for longer than the retention,
+// returning how many were removed. Their stored files are only removed once the transaction has been committed
+func (r *postRepositoryImpl) purgeDeleted(ctx context.Context, retention time.Duration, limit int) (int, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	var removedFiles []string
+	defer func() {
+		if err == nil {
+			removeMediaFiles(removedFiles)
+		}
+	}()
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	rows, err := tx.Query(
+		ctx,
+		`SELECT id FROM posts
+		WHERE deleted_at < (NOW() AT TIME ZONE 'utc') - make_interval(secs => $1::float8)
+		ORDER BY deleted_at
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED`,
+		retention.Seconds(), limit,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select deleted posts: %w", err)
+	}
+	ids, err := scanIds(rows)
+	if err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	rows, err = tx.Query(ctx, "SELECT video_path FROM post_media WHERE post_id = ANY($1) AND video_path IS NOT NULL", ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select post media: %w", err)
+	}
+	removedFiles, err = scanVideoPaths(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	// Reposts of the purged posts are removed along with them, changing the post counts of their authors
+	rows, err = tx.Query(ctx, "SELECT DISTINCT user_id FROM posts WHERE repost_of = ANY($1)", ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to select reposts: %w", err)
+	}
+	reposters, err := scanIds(rows)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = tx.Exec(ctx, "DELETE FROM posts WHERE id = ANY($1)", ids)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge posts: %w", err)
+	}
+
+	for _, userId := range reposters {
+		err = updatePostCount(ctx, tx, userId)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(ids), nil
+}
+
+// insertPostMedia inserts the media items of a post in order, position 0 being the cover.
+// Items are flagged as duplicates of the oldest post with an image close enough to be reported as similar
+func insertPostMedia(ctx context.Context, tx pgx.Tx, postId uuid.UUID, media []shared.PostMedia) error {
+	for _, item := range media {
+		_, err := tx.Exec(
+			ctx,
+			`INSERT INTO post_media (post_id, position, type, status, image, video_path, alt_text, image_width, image_height, image_color, image_blurhash, phash, duplicate_of)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12,
+				(SELECT post_id FROM post_media WHERE bit_count((phash # $12)::bit(64)) <= $13 AND post_id <> $1 ORDER BY created_at LIMIT 1))`,
+			postId, item.Position, item.Type, item.Status, item.Image, nullIfEmpty(item.VideoPath), item.AltText, item.Width, item.Height, item.DominantColor, item.BlurHash, hashToInt64(item.Hash),
+			BannedImageMaxDistance,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert post media: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// scanPost reads a row selected with postColumns, followed by the extra destinations given
+func scanPost(row pgx.Row, extra ...interface{}) (shared.Post, error) {
+	var post shared.Post
+	var repostOf, quoteOf, placeId *uuid.UUID
+	var placeName *string
+	var latitude, longitude *float64
+	post.User = &shared.User{}
+
+	dest := []interface{}{
+		&post.ID, &post.User.ID, &post.User.Username, &post.User.Avatar, &post.Image, &post.Width, &post.Height, &post.DominantColor, &post.BlurHash, &post.AltText,
+		&post.Status, &post.Visibility, &post.CommentPolicy, &post.Draft, &post.ScheduledAt, &post.EditedAt, &post.DeletedAt, &post.ArchivedAt, &post.PinnedAt, &post.Description, &repostOf, &quoteOf, &post.LikeCount, &post.CommentCount, &post.RepostCount,
+		&post.ContentWarning, &post.Sensitive, &post.Blurred,
+		&placeId, &placeName, &latitude, &longitude,
+		&post.Saved, &post.Reaction, &post.CreatedAt,
+	}
+	err := row.Scan(append(dest, extra...)...)
+	if err != nil {
+		return shared.Post{}, err
+	}
+
+	if repostOf != nil {
+		post.RepostOf = &shared.Post{ID: *repostOf}
+	}
+	if quoteOf != nil {
+		post.QuoteOf = &shared.Post{ID: *quoteOf}
+	}
+	if placeId != nil {
+		post.Place = &shared.Place{ID: *placeId, Name: *placeName, Latitude: *latitude, Longitude: *longitude}
+	}
+
+	return post, nil
+}
+
+// attachPostDetails reads the media, mentions, reactions and polls of the given posts, along with the posts they repost or quote
+func attachPostDetails(ctx context.Context, tx pgx.Tx, viewerId uuid.UUID, posts []shared.Post) error {
+	err := attachPostRelations(ctx, tx, posts)
+	if err != nil {
+		return err
+	}
+
+	err = attachPolls(ctx, tx, viewerId, posts)
+	if err != nil {
+		return err
+	}
+
+	var embeddedIds []uuid.UUID
+	for _, post := range posts {
+		if post.RepostOf != nil {
+			embeddedIds = append(embeddedIds, post.RepostOf.ID)
+		}
+		if post.QuoteOf != nil {
+			embeddedIds = append(embeddedIds, post.QuoteOf.ID)
+		}
+	}
+	if len(embeddedIds) == 0 {
+		return nil
+	}
+
+	rows, err := tx.Query(ctx, `SELECT `+postColumns+` FROM posts p `+postJoins+` WHERE p.id = ANY($2) AND `+visibleToViewer, viewerId, embeddedIds)
+	if err != nil {
+		return fmt.Errorf("failed to select embedded posts: %w", err)
+	}
+	defer rows.Close()
+
+	var embedded []shared.Post
+	for rows.Next() {
+		post, err := scanPost(rows)
+		if err != nil {
+			return fmt.Errorf("failed to scan embedded post: %w", err)
+		}
+		embedded = append(embedded, post)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading rows: %w", err)
+	}
+
+	err = attachPostRelations(ctx, tx, embedded)
+	if err != nil {
+		return err
+	}
+
+	err = attachPolls(ctx, tx, viewerId, embedded)
+	if err != nil {
+		return err
+	}
+
+	embeddedById := make(map[uuid.UUID]shared.Post)
+	for _, post := range embedded {
+		embeddedById[post.ID] = post
+	}
+
+	for i := range posts {
+		if posts[i].RepostOf != nil {
+			if post, ok := embeddedById[posts[i].RepostOf.ID]; ok {
+				posts[i].RepostOf = &post
+			}
+		}
+		// Quoted posts the viewer can not read are left out, as if they had been deleted
+		if posts[i].QuoteOf != nil {
+			if post, ok := embeddedById[posts[i].QuoteOf.ID]; ok {
+				posts[i].QuoteOf = &post
+			} else {
+				posts[i].QuoteOf = nil
+			}
+		}
+	}
+
+	return nil
+}
+
+// attachPostRelations reads the media, mentions and reaction counts of the given posts
+func attachPostRelations(ctx context.Context, tx pgx.Tx, posts []shared.Post) error {
+	ids := make([]uuid.UUID, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	media, err := selectPostMedia(ctx, tx, ids)
+	if err != nil {
+		return err
+	}
+
+	mentions, err := selectPostMentions(ctx, tx, ids)
+	if err != nil {
+		return err
+	}
+
+	reactions, err := selectPostReactionCounts(ctx, tx, ids)
+	if err != nil {
+		return err
+	}
+
+	for i := range posts {
+		posts[i].Media = media[posts[i].ID]
+		posts[i].MediaCount = len(posts[i].Media)
+		posts[i].Mentions = mentions[posts[i].ID]
+		posts[i].Reactions = reactions[posts[i].ID]
+	}
+
+	return nil
+}
+
+// selectPostReactionCounts counts the reactions of each of the given posts by type
+func selectPostReactionCounts(ctx context.Context, tx pgx.Tx, postIds []uuid.UUID) (map[uuid.UUID]map[string]int, error) {
+	rows, err := tx.Query(ctx, "SELECT post_id, reaction, COUNT(*) FROM likes WHERE post_id = ANY($1) GROUP BY post_id, reaction", postIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select post reactions: %w", err)
+	}
+	defer rows.Close()
+
+	reactions := make(map[uuid.UUID]map[string]int)
+	for rows.Next() {
+		var postId uuid.UUID
+		var reaction string
+		var count int
+		if err := rows.Scan(&postId, &reaction, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan post reaction: %w", err)
+		}
+		if reactions[postId] == nil {
+			reactions[postId] = make(map[string]int)
+		}
+		reactions[postId][reaction] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return reactions, nil
+}
+
+// insertPoll stores the poll attached to a new post along with its options
+func insertPoll(ctx context.Context, tx pgx.Tx, postId uuid.UUID, poll *shared.Poll) error {
+	if poll == nil {
+		return nil
+	}
+
+	_, err := tx.Exec(ctx, "INSERT INTO polls (post_id, multiple_choice, closes_at) VALUES ($1, $2, $3)", postId, poll.MultipleChoice, poll.ClosesAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert poll: %w", err)
+	}
+
+	for _, option := range poll.Options {
+		_, err = tx.Exec(ctx, "INSERT INTO poll_options (post_id, position, text) VALUES ($1, $2, $3)", postId, option.Position, option.Text)
+		if err != nil {
+			return fmt.Errorf("failed to insert poll option: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// attachPolls reads the state of the polls attached to the given posts for the viewer
+func attachPolls(ctx context.Context, tx pgx.Tx, viewerId uuid.UUID, posts []shared.Post) error {
+	ids := make([]uuid.UUID, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	rows, err := tx.Query(
+		ctx,
+		`SELECT pl.post_id, pl.multiple_choice, pl.closes_at, pl.closes_at <= (NOW() AT TIME ZONE 'utc'),
+			(SELECT COUNT(*) FROM poll_voters v WHERE v.post_id = pl.post_id),
+			EXISTS (SELECT 1 FROM poll_voters v WHERE v.post_id = pl.post_id AND v.user_id = $1)
+		FROM polls pl
+		WHERE pl.post_id = ANY($2)`,
+		viewerId, ids,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to select polls: %w", err)
+	}
+	defer rows.Close()
+
+	polls := make(map[uuid.UUID]*shared.Poll)
+	for rows.Next() {
+		var postId uuid.UUID
+		var poll shared.Poll
+		if err := rows.Scan(&postId, &poll.MultipleChoice, &poll.ClosesAt, &poll.Closed, &poll.VoterCount, &poll.Voted); err != nil {
+			return fmt.Errorf("failed to scan poll: %w", err)
+		}
+		polls[postId] = &poll
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error reading rows: %w", err)
+	}
+	if len(polls) == 0 {
+		return nil
+	}
+
+	optionRows, err := tx.Query(
+		ctx,
+		`SELECT o.post_id, o.id, o.position, o.text,
+			(SELECT COUNT(*) FROM poll_votes pv WHERE pv.option_id = o.id),
+			EXISTS (SELECT 1 FROM poll_votes pv WHERE pv.option_id = o.id AND pv.user_id = $1)
+		FROM poll_options o
+		WHERE o.post_id = ANY($2)
+		ORDER BY o.position`,
+		viewerId, ids,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to select poll options: %w", err)
+	}
+	defer optionRows.Close()
+
+	for optionRows.Next() {
+		var postId uuid.UUID
+		var option shared.PollOption
+		var voteCount int
+		if err := optionRows.Scan(&postId, &option.ID, &option.Position, &option.Text, &voteCount, &option.Voted); err != nil {
+			return fmt.Errorf("failed to scan poll option: %w", err)
+		}
+		option.VoteCount = &voteCount
+		polls[postId].Options = append(polls[postId].Options, option)
+	}
+	if err := optionRows.Err(); err != nil {
+		return fmt.Errorf("error reading rows: %w", err)
+	}
+
+	for i := range posts {
+		if poll, ok := polls[posts[i].ID]; ok {
+			hidePollResults(poll)
+			posts[i].Poll = poll
+		}
+	}
+
+	return nil
+}
+
+// upsertPlace returns the id of the place a post is tagged with, creating it the first time it is used
+func upsertPlace(ctx context.Context, tx pgx.Tx, place *shared.Place) (*uuid.UUID, error) {
+	if place == nil {
+		return nil, nil
+	}
+
+	var id uuid.UUID
+	err := tx.QueryRow(
+		ctx,
+		`INSERT INTO places (name, latitude, longitude) VALUES ($1, $2, $3)
+		ON CONFLICT (name, latitude, longitude) DO UPDATE SET name = EXCLUDED.name
+		RETURNING id`,
+		place.Name, place.Latitude, place.Longitude,
+	).Scan(&id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to insert place: %w", err)
+	}
+
+	return &id, nil
+}
+
+// checkPostVisible reports a post the viewer can not read as missing
+func checkPostVisible(ctx context.Context, tx pgx.Tx, viewerId uuid.UUID, postId uuid.UUID) error {
+	var visible bool
+	err := tx.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM posts p WHERE p.id = $2 AND "+visibleToViewer+")", viewerId, postId).Scan(&visible)
+	if err != nil {
+		return fmt.Errorf("failed to check post visibility: %w", err)
+	}
+	if !visible {
+		return &PostNotFoundError{}
+	}
+
+	return nil
+}
+
+// updateLikeCount recounts the likes of a post, which only count hearts, other reactions being counted by type
+func updateLikeCount(ctx context.Context, tx pgx.Tx, postId uuid.UUID) error {
+	_, err := tx.Exec(
+		ctx,
+		"UPDATE posts p SET like_count = (SELECT COUNT(*) FROM likes l WHERE l.post_id = p.id AND l.reaction = $2) WHERE p.id = $1",
+		postId, ReactionHeart,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update like count: %w", err)
+	}
+
+	return nil
+}
+
+// updateRepostCount recounts the reposts and quotes of a post
+func updateRepostCount(ctx context.Context, tx pgx.Tx, postId uuid.UUID) error {
+	_, err := tx.Exec(
+		ctx,
+		"UPDATE posts p SET repost_count = (SELECT COUNT(*) FROM posts r WHERE (r.repost_of = p.id OR r.quote_of = p.id) AND NOT r.draft AND r.deleted_at IS NULL) WHERE p.id = $1",
+		postId,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to update repost count: %w", err)
+	}
+
+	return nil
+}
+
+// postCount counts the published posts of the user u that are not in the trash, reposts of trashed posts
+// being hidden along with them
+const postCount = `(SELECT COUNT(*) FROM posts p WHERE p.user_id = u.id AND NOT p.draft AND p.deleted_at IS NULL AND p.archived_at IS NULL
+	AND NOT EXISTS (SELECT 1 FROM posts o WHERE o.id = p.repost_of AND o.deleted_at IS NOT NULL))`
+
+// updatePostCount recounts the published posts of a user that are not in the trash
+func updatePostCount(ctx context.Context, tx pgx.Tx, userId uuid.UUID) error {
+	_, err := tx.Exec(ctx, "UPDATE users u SET post_count = "+postCount+" WHERE u.id = $1", userId)
+	if err != nil {
+		return fmt.Errorf("failed to update post count: %w", err)
+	}
+
+	return nil
+}
+
+// updateReposterPostCounts recounts the published posts of the users who reposted a post,
+// after it has been moved to or restored from the trash
+func updateReposterPostCounts(ctx context.Context, tx pgx.Tx, postId uuid.UUID) error {
+	_, err := tx.Exec(ctx, "UPDATE users u SET post_count = "+postCount+" WHERE u.id IN (SELECT user_id FROM posts WHERE repost_of = $1)", postId)
+	if err != nil {
+		return fmt.Errorf("failed to update reposter post counts: %w", err)
+	}
+
+	return nil
+}
+
+// syncPostTags replaces the tags of a post, creating the ones used for the first time
+func syncPostTags(ctx context.Context, tx pgx.Tx, postId uuid.UUID, tags []string) error {
+	_, err := tx.Exec(ctx, "DELETE FROM post_tags WHERE post_id = $1", postId)
+	if err != nil {
+		return fmt.Errorf("failed to delete post tags: %w", err)
+	}
+	if len(tags) == 0 {
+		return nil
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO tags (name) SELECT unnest($1::text[]) ON CONFLICT (name) DO NOTHING", tags)
+	if err != nil {
+		return fmt.Errorf("failed to insert tags: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO post_tags (post_id, tag_id) SELECT $1, id FROM tags WHERE name = ANY($2)", postId, tags)
+	if err != nil {
+		return fmt.Errorf("failed to insert post tags: %w", err)
+	}
+
+	return nil
+}
+
+// syncPostMentions replaces the resolved mentions of a post description
+func syncPostMentions(ctx context.Context, tx pgx.Tx, postId uuid.UUID, mentions []shared.Mention) error {
+	_, err := tx.Exec(ctx, "DELETE FROM post_mentions WHERE post_id = $1", postId)
+	if err != nil {
+		return fmt.Errorf("failed to delete post mentions: %w", err)
+	}
+
+	for _, mention := range mentions {
+		_, err = tx.Exec(
+			ctx,
+			"INSERT INTO post_mentions (post_id, user_id, text_offset, text_length) VALUES ($1, $2, $3, $4)",
+			postId, mention.UserID, mention.Offset, mention.Length,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to insert post mention: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// selectPostMentions reads the mentions of the given posts, grouped by post id.
+// Usernames are read from the mentioned users so links keep working after a rename
+func selectPostMentions(ctx context.Context, tx pgx.Tx, postIds []uuid.UUID) (map[uuid.UUID][]shared.Mention, error) {
+	rows, err := tx.Query(
+		ctx,
+		`SELECT pm.post_id, pm.user_id, u.username, pm.text_offset, pm.text_length
+		FROM post_mentions pm
+		INNER JOIN users u ON u.id = pm.user_id
+		WHERE pm.post_id = ANY($1)
+		ORDER BY pm.post_id, pm.text_offset`,
+		postIds,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select post mentions: %w", err)
+	}
+	defer rows.Close()
+
+	mentions := make(map[uuid.UUID][]shared.Mention)
+	for rows.Next() {
+		var postId uuid.UUID
+		var mention shared.Mention
+		err := rows.Scan(&postId, &mention.UserID, &mention.Username, &mention.Offset, &mention.Length)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post mention: %w", err)
+		}
+		mentions[postId] = append(mentions[postId], mention)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return mentions, nil
+}
+
+// selectPostMedia reads the ordered media items of the given posts, grouped by post id
+func selectPostMedia(ctx context.Context, tx pgx.Tx, postIds []uuid.UUID) (map[uuid.UUID][]shared.PostMedia, error) {
+	query := `
+		SELECT post_id, id, position, type, status, image, video_duration, alt_text, image_width, image_height, image_color, image_blurhash
+		FROM post_media
+		WHERE post_id = ANY($1)
+		ORDER BY post_id, position
+	`
+
+	rows, err := tx.Query(ctx, query, postIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select post media: %w", err)
+	}
+	defer rows.Close()
+
+	media := make(map[uuid.UUID][]shared.PostMedia)
+	for rows.Next() {
+		var postId uuid.UUID
+		var item shared.PostMedia
+		err := rows.Scan(&postId, &item.ID, &item.Position, &item.Type, &item.Status, &item.Image, &item.Duration, &item.AltText, &item.Width, &item.Height, &item.DominantColor, &item.BlurHash)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan post media: %w", err)
+		}
+		media[postId] = append(media[postId], item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return media, nil
+}
+
+// scanVideoPaths reads the stored video file names returned by a post media query
+func scanVideoPaths(rows pgx.Rows) ([]string, error) {
+	defer rows.Close()
+
+	var paths []string
+	for rows.Next() {
+		var path *string
+		if err := rows.Scan(&path); err != nil {
+			return nil, fmt.Errorf("failed to scan post media: %w", err)
+		}
+		if path != nil {
+			paths = append(paths, *path)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return paths, nil
+}
+
+func scanIds(rows pgx.Rows) ([]uuid.UUID, error) {
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, fmt.Errorf("failed to scan id: %w", err)
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return ids, nil
+}
+
+// removeMediaFiles deletes stored media files, logging instead of failing as their rows are already gone
+func removeMediaFiles(names []string) {
+	for _, name := range names {
+		if err := media.RemoveFile(name); err != nil {
+			logger.ServerLogger.Error(err.Error())
+		}
+	}
+}
+
+func nullIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
+
+// hashToInt64 stores an unsigned perceptual hash in a signed bigint column, keeping its bits as they are
+func hashToInt64(hash *uint64) *int64 {
+	if hash == nil {
+		return nil
+	}
+
+	v := int64(*hash)
+	return &v
+}
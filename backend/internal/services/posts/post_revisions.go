@@ -0,0 +1,23 @@
+package posts
+
+import (
+	"os"
+	"strings"
+	"time"
+)
+
+// EditWindow returns how long after their publication posts can be edited, read as a duration such as "48h"
+// from POST_EDIT_WINDOW. Posts can be edited at any time when it is unset or invalid
+func EditWindow() time.Duration {
+	value := strings.TrimSpace(os.Getenv("POST_EDIT_WINDOW"))
+	if value == "" {
+		return 0
+	}
+
+	window, err := time.ParseDuration(value)
+	if err != nil || window < 0 {
+		return 0
+	}
+
+	return window
+}
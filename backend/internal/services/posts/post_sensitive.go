@@ -0,0 +1,41 @@
+package posts
+
+import (
+	"strings"
+	"unicode/utf8"
+	"y-net/internal/services/shared"
+)
+
+const maxContentWarningLength = 200
+
+// normalizeSensitive trims the content warning of a post, a post with a warning being always sensitive.
+// Whether the post is blurred depends on the viewer and is never taken from the client
+func normalizeSensitive(post shared.Post) (shared.Post, error) {
+	contentWarning, err := NormalizeContentWarning(post.ContentWarning)
+	if err != nil {
+		return shared.Post{}, err
+	}
+
+	post.ContentWarning = contentWarning
+	post.Sensitive = post.Sensitive || contentWarning != nil
+	post.Blurred = false
+
+	return post, nil
+}
+
+// NormalizeContentWarning trims a content warning, returning nil when it is blank
+func NormalizeContentWarning(contentWarning *string) (*string, error) {
+	if contentWarning == nil {
+		return nil, nil
+	}
+
+	trimmed := strings.TrimSpace(*contentWarning)
+	if trimmed == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(trimmed) > maxContentWarningLength {
+		return nil, &ContentWarningTooLongError{}
+	}
+
+	return &trimmed, nil
+}
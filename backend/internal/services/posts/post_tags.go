@@ -0,0 +1,61 @@
+package posts
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	maxPostTags  = 30
+	maxTagLength = 100
+)
+
+// A hashtag must not follow a word character, & or / so that html entities and url fragments are not picked up
+var hashtagRegexp = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_&/])#([\p{L}\p{N}_]+)`)
+
+// ParseHashtags returns the distinct, lowercased hashtags of a post description in order of appearance.
+// Tags made only of digits or longer than the allowed length are ignored
+func ParseHashtags(description *string) []string {
+	if description == nil {
+		return nil
+	}
+
+	var tags []string
+	seen := make(map[string]bool)
+	for _, match := range hashtagRegexp.FindAllStringSubmatch(*description, -1) {
+		tag, ok := NormalizeTag(match[1])
+		if !ok || seen[tag] {
+			continue
+		}
+
+		seen[tag] = true
+		tags = append(tags, tag)
+		if len(tags) == maxPostTags {
+			break
+		}
+	}
+
+	return tags
+}
+
+// NormalizeTag lowercases a tag, dropping its leading #, and reports whether it is a valid tag
+func NormalizeTag(tag string) (string, bool) {
+	tag = strings.ToLower(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+	if tag == "" || utf8.RuneCountInString(tag) > maxTagLength {
+		return "", false
+	}
+
+	hasLetter := false
+	for _, r := range tag {
+		if !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '_' {
+			return "", false
+		}
+		if unicode.IsLetter(r) {
+			hasLetter = true
+		}
+	}
+
+	return tag, hasLetter
+}
@@ -1,14 +1,24 @@
 package posts
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"fmt"
+	"image"
+	"image/png"
+	"math"
+	"os"
+	"sort"
+	"strings"
 	"testing"
 	"time"
+	"y-net/internal/media"
 	"y-net/internal/services/shared"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type TestSetup struct {
@@ -22,13 +32,29 @@ func setup() *TestSetup {
 
 	return &TestSetup{usecase: usecase, repo: repo}
 }
+
+// newTestImage returns a base64 encoded png with the given dimensions
+// createPost creates a post through the usecase, returning its id
+func (ts *TestSetup) createPost(ctx context.Context, post shared.Post) (uuid.UUID, error) {
+	newPost, err := ts.usecase.Create(ctx, post)
+	return newPost.ID, err
+}
+
+func newTestImage(t *testing.T, width int, height int) string {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)))
+	require.NoError(t, err)
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
 func TestCreatePost(t *testing.T) {
 	ts := setup()
 
 	user := shared.User{ID: uuid.New(), Username: "testuser"}
-	post := shared.Post{User: &user, Image: "image_url.jpg"}
+	post := shared.Post{User: &user, Image: newTestImage(t, 10, 10)}
 
-	id, err := ts.usecase.Create(context.Background(), post)
+	id, err := ts.createPost(context.Background(), post)
 	assert.NoError(t, err)
 	assert.NotEqual(t, uuid.Nil, id)
 }
@@ -36,9 +62,9 @@ func TestCreatePost(t *testing.T) {
 func TestCreatePostEmptyUser(t *testing.T) {
 	ts := setup()
 
-	post := shared.Post{User: &shared.User{}, Image: "image_url.jpg"}
+	post := shared.Post{User: &shared.User{}, Image: newTestImage(t, 10, 10)}
 
-	id, err := ts.usecase.Create(context.Background(), post)
+	id, err := ts.createPost(context.Background(), post)
 	assert.Error(t, err)
 	assert.Equal(t, uuid.Nil, id)
 }
@@ -49,276 +75,2133 @@ func TestCreatePostEmptyImage(t *testing.T) {
 	user := shared.User{ID: uuid.New(), Username: "testuser"}
 	post := shared.Post{User: &user, Image: ""}
 
-	id, err := ts.usecase.Create(context.Background(), post)
+	id, err := ts.createPost(context.Background(), post)
 	assert.Error(t, err)
 	assert.Equal(t, uuid.Nil, id)
 }
 
-func TestGetPost(t *testing.T) {
+func TestCreatePostImageMetadata(t *testing.T) {
 	ts := setup()
 
 	user := shared.User{ID: uuid.New(), Username: "testuser"}
-	post := shared.Post{User: &user, Image: "image.jpg"}
-	id, _ := ts.usecase.Create(context.Background(), post)
+	post := shared.Post{User: &user, Image: newTestImage(t, 40, 30)}
 
-	retrievedPost, err := ts.usecase.GetPost(context.Background(), id)
+	id, err := ts.createPost(context.Background(), post)
 	assert.NoError(t, err)
-	assert.Equal(t, post.Image, retrievedPost.Image)
+
+	createdPost := ts.repo.posts[id]
+	assert.Equal(t, 40, *createdPost.Width)
+	assert.Equal(t, 30, *createdPost.Height)
+	assert.Equal(t, "#000000", *createdPost.DominantColor)
+	assert.NotEmpty(t, *createdPost.BlurHash)
 }
 
-func TestGetPostNotFound(t *testing.T) {
+func TestCreatePostInvalidImage(t *testing.T) {
 	ts := setup()
 
-	id := uuid.New()
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	post := shared.Post{User: &user, Image: "image_url.jpg"}
 
-	_, err := ts.usecase.GetPost(context.Background(), id)
+	id, err := ts.createPost(context.Background(), post)
 	assert.Error(t, err)
+	assert.Equal(t, uuid.Nil, id)
 }
 
-func TestUpdatePost(t *testing.T) {
+func TestCreatePostCarousel(t *testing.T) {
 	ts := setup()
 
 	user := shared.User{ID: uuid.New(), Username: "testuser"}
-	post := shared.Post{User: &user, Image: "image.jpg"}
-	id, _ := ts.usecase.Create(context.Background(), post)
+	altText := "a wide picture"
+	post := shared.Post{User: &user, Media: []shared.PostMedia{
+		{Image: newTestImage(t, 30, 10), AltText: &altText},
+		{Image: newTestImage(t, 10, 10)},
+	}}
 
-	post.Image = "updated_image.jpg"
-	err := ts.usecase.Update(context.Background(), post, id)
+	id, err := ts.createPost(context.Background(), post)
 	assert.NoError(t, err)
 
-	updatedPost, err := ts.usecase.GetPost(context.Background(), id)
+	createdPost := ts.repo.posts[id]
+	assert.Len(t, createdPost.Media, 2)
+	assert.Equal(t, 1, createdPost.Media[1].Position)
+	assert.Equal(t, &altText, createdPost.Media[0].AltText)
+	assert.Equal(t, createdPost.Media[0].Image, createdPost.Image)
+	assert.Equal(t, 30, *createdPost.Width)
+}
+
+func TestCreatePostTooManyMedia(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	post := shared.Post{User: &user}
+	for i := 0; i <= maxPostMedia; i++ {
+		post.Media = append(post.Media, shared.PostMedia{Image: newTestImage(t, 10, 10)})
+	}
+
+	id, err := ts.createPost(context.Background(), post)
+	assert.Error(t, err)
+	assert.Equal(t, uuid.Nil, id)
+}
+
+func TestCreatePostInvalidVideo(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	video := base64.StdEncoding.EncodeToString([]byte("definitely not a video"))
+	post := shared.Post{User: &user, Media: []shared.PostMedia{{Video: video}}}
+
+	id, err := ts.createPost(context.Background(), post)
+	assert.Error(t, err)
+	assert.Equal(t, uuid.Nil, id)
+}
+
+func TestCreatePostVideoProcessing(t *testing.T) {
+	ts := setup()
+	t.Setenv("MEDIA_DIR", t.TempDir())
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	mp4 := append([]byte{0x00, 0x00, 0x00, 0x18}, []byte("ftypisom\x00\x00\x02\x00")...)
+	post := shared.Post{User: &user, Media: []shared.PostMedia{{Video: base64.StdEncoding.EncodeToString(mp4)}}}
+
+	newPost, err := ts.usecase.Create(context.Background(), post)
 	assert.NoError(t, err)
-	assert.Equal(t, "updated_image.jpg", updatedPost.Image)
+	assert.Equal(t, shared.StatusProcessing, newPost.Status)
+
+	createdPost := ts.repo.posts[newPost.ID]
+	assert.Equal(t, shared.StatusProcessing, createdPost.Status)
+	assert.Equal(t, shared.MediaTypeVideo, createdPost.Media[0].Type)
+	assert.Equal(t, shared.StatusProcessing, createdPost.Media[0].Status)
+	assert.NotEmpty(t, createdPost.Media[0].VideoPath)
+	assert.Empty(t, createdPost.Media[0].Video)
 }
 
-func TestUpdatePostEmptyImage(t *testing.T) {
+func TestCreatePostVideoNotStoredOnError(t *testing.T) {
 	ts := setup()
+	mediaDir := t.TempDir()
+	t.Setenv("MEDIA_DIR", mediaDir)
 
 	user := shared.User{ID: uuid.New(), Username: "testuser"}
-	post := shared.Post{User: &user, Image: "image.jpg"}
-	id, _ := ts.usecase.Create(context.Background(), post)
+	mp4 := append([]byte{0x00, 0x00, 0x00, 0x18}, []byte("ftypisom\x00\x00\x02\x00")...)
+	post := shared.Post{User: &user, Visibility: "everyone", Media: []shared.PostMedia{{Video: base64.StdEncoding.EncodeToString(mp4)}}}
 
-	post.Image = ""
-	err := ts.usecase.Update(context.Background(), post, id)
+	_, err := ts.createPost(context.Background(), post)
 	assert.Error(t, err)
+
+	// Posts failing validation after their video was read leave no file behind
+	files, err := os.ReadDir(mediaDir)
+	assert.NoError(t, err)
+	assert.Empty(t, files)
 }
 
-func TestDeletePost(t *testing.T) {
+func TestCreatePostAltTextTooLong(t *testing.T) {
 	ts := setup()
 
 	user := shared.User{ID: uuid.New(), Username: "testuser"}
-	post := shared.Post{User: &user, Image: "image.jpg"}
-	id, _ := ts.usecase.Create(context.Background(), post)
+	altText := strings.Repeat("a", maxAltTextLength+1)
+	post := shared.Post{User: &user, Image: newTestImage(t, 10, 10), AltText: &altText}
 
-	err := ts.usecase.Delete(context.Background(), id)
+	id, err := ts.createPost(context.Background(), post)
+	var altTextErr *AltTextTooLongError
+	assert.ErrorAs(t, err, &altTextErr)
+	assert.Equal(t, uuid.Nil, id)
+}
+
+func TestGetBySearchAltText(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	altText := "  a golden retriever on the beach  "
+	post := shared.Post{User: &user, Image: newTestImage(t, 10, 10), AltText: &altText}
+	id, _ := ts.createPost(context.Background(), post)
+
+	assert.Equal(t, "a golden retriever on the beach", *ts.repo.posts[id].Media[0].AltText)
+
+	posts, err := ts.usecase.GetBySearch(context.Background(), uuid.New(), "retriever")
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+	assert.Equal(t, id, posts[0].ID)
+}
+
+func TestGetBySearchArchived(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	description := "an archived #sunset"
+	id, _ := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), Description: &description})
+
+	err := ts.usecase.Archive(context.Background(), id)
 	assert.NoError(t, err)
 
-	posts, err := ts.usecase.GetPosts(context.Background(), 10, time.Now(), uuid.Nil)
+	posts, err := ts.usecase.GetBySearch(context.Background(), uuid.New(), "archived")
 	assert.NoError(t, err)
-	assert.NotContains(t, posts, post)
+	assert.Empty(t, posts)
+
+	posts, err = ts.usecase.GetPostsByTag(context.Background(), uuid.New(), "sunset", 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Empty(t, posts)
+
+	tags, err := ts.usecase.GetTrendingTags(context.Background(), 24*time.Hour, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, tags)
 }
 
-func TestDeletePostNotFound(t *testing.T) {
+func TestGetPost(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	post := shared.Post{User: &user, Image: newTestImage(t, 10, 10)}
+	id, _ := ts.createPost(context.Background(), post)
+
+	retrievedPost, err := ts.usecase.GetPost(context.Background(), uuid.Nil, id)
+	assert.NoError(t, err)
+	assert.Equal(t, post.Image, retrievedPost.Image)
+}
+
+func TestGetPostNotFound(t *testing.T) {
 	ts := setup()
 
 	id := uuid.New()
 
-	err := ts.usecase.Delete(context.Background(), id)
+	_, err := ts.usecase.GetPost(context.Background(), uuid.Nil, id)
 	assert.Error(t, err)
-	assert.Equal(t, "post not found", err.Error())
 }
 
-func TestLike(t *testing.T) {
+func TestUpdatePost(t *testing.T) {
 	ts := setup()
 
-	userId := uuid.New()
-	postId := uuid.New()
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	post := shared.Post{User: &user, Image: newTestImage(t, 10, 10)}
+	id, _ := ts.createPost(context.Background(), post)
 
-	err := ts.usecase.Like(context.Background(), userId, postId)
+	post.Image = newTestImage(t, 20, 10)
+	err := ts.usecase.Update(context.Background(), post, id)
 	assert.NoError(t, err)
 
-	likedUsers, err := ts.usecase.GetLikes(context.Background(), postId)
+	updatedPost, err := ts.usecase.GetPost(context.Background(), uuid.Nil, id)
 	assert.NoError(t, err)
-	assert.Len(t, likedUsers, 1)
-	assert.Equal(t, likedUsers[0].ID, userId)
+	assert.Equal(t, post.Image, updatedPost.Image)
+	assert.Equal(t, 20, *updatedPost.Width)
 }
 
-func TestUnlike(t *testing.T) {
+func TestUpdatePoll(t *testing.T) {
 	ts := setup()
 
-	userId := uuid.New()
-	postId := uuid.New()
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	question := "Cats or dogs?"
+	id, err := ts.createPost(context.Background(), shared.Post{User: &user, Description: &question, Poll: newTestPoll(false, "Cats", "Dogs")})
+	assert.NoError(t, err)
 
-	err := ts.usecase.Like(context.Background(), userId, postId)
+	ogPost, err := ts.usecase.GetPost(context.Background(), user.ID, id)
 	assert.NoError(t, err)
 
-	err = ts.usecase.Unlike(context.Background(), userId, postId)
+	// Poll posts without media can only be edited along with their poll
+	edited := "Cats or dogs, really?"
+	err = ts.usecase.Update(context.Background(), shared.Post{User: &user, Description: &edited}, id)
+	assert.Error(t, err)
+
+	err = ts.usecase.Update(context.Background(), shared.Post{User: &user, Description: &edited, Poll: ogPost.Poll}, id)
 	assert.NoError(t, err)
+	assert.Equal(t, edited, *ts.repo.posts[id].Description)
 
-	likedUsers, err := ts.usecase.GetLikes(context.Background(), postId)
+	poll, err := ts.usecase.GetPoll(context.Background(), user.ID, id)
 	assert.NoError(t, err)
-	assert.Len(t, likedUsers, 0)
+	assert.Len(t, poll.Options, 2)
 }
 
-func TestUnlikeUserNotLiked(t *testing.T) {
+func TestCreatePostInvalidMedia(t *testing.T) {
 	ts := setup()
 
-	userId := uuid.New()
-	postId := uuid.New()
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	_, err := ts.createPost(context.Background(), shared.Post{User: &user})
+	assert.IsType(t, &EmptyMediaError{}, err)
 
-	err := ts.usecase.Unlike(context.Background(), userId, postId)
+	_, err = ts.createPost(context.Background(), shared.Post{User: &user, Image: "not an image"})
+	assert.IsType(t, &InvalidImageError{}, err)
+
+	_, err = ts.createPost(context.Background(), shared.Post{User: &user, Media: []shared.PostMedia{{Video: "bm90IGEgdmlkZW8="}}})
+	assert.IsType(t, &InvalidVideoError{}, err)
+
+	items := make([]shared.PostMedia, maxPostMedia+1)
+	for i := range items {
+		items[i] = shared.PostMedia{Image: newTestImage(t, 10, 10)}
+	}
+	_, err = ts.createPost(context.Background(), shared.Post{User: &user, Media: items})
+	assert.IsType(t, &TooManyMediaError{}, err)
+
+	_, err = ts.createPost(context.Background(), shared.Post{User: &user, Poll: newTestPoll(false, "Cats", "Dogs")})
+	assert.IsType(t, &MissingDescriptionError{}, err)
+
+	_, err = ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), Visibility: "friends"})
+	assert.IsType(t, &InvalidVisibilityError{}, err)
+}
+
+func TestUpdatePostEmptyImage(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	post := shared.Post{User: &user, Image: newTestImage(t, 10, 10)}
+	id, _ := ts.createPost(context.Background(), post)
+
+	post.Image = ""
+	err := ts.usecase.Update(context.Background(), post, id)
 	assert.Error(t, err)
+}
+
+func TestDeletePost(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	post := shared.Post{User: &user, Image: newTestImage(t, 10, 10)}
+	id, _ := ts.createPost(context.Background(), post)
 
-	likedUsers, err := ts.usecase.GetLikes(context.Background(), postId)
+	err := ts.usecase.Delete(context.Background(), id)
 	assert.NoError(t, err)
-	assert.Len(t, likedUsers, 0)
+
+	posts, err := ts.usecase.GetPosts(context.Background(), uuid.Nil, 10, time.Now(), uuid.Nil)
+	assert.NoError(t, err)
+	assert.NotContains(t, posts, post)
 }
 
-func TestUserLikedPost(t *testing.T) {
+func TestTrashRestorePost(t *testing.T) {
 	ts := setup()
 
-	userId := uuid.New()
-	postId := uuid.New()
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	id, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10)})
+	assert.NoError(t, err)
 
-	err := ts.usecase.Like(context.Background(), userId, postId)
+	err = ts.usecase.Delete(context.Background(), id)
 	assert.NoError(t, err)
 
-	liked, err := ts.usecase.UserLikedPost(context.Background(), userId, postId)
+	_, err = ts.usecase.GetPost(context.Background(), user.ID, id)
+	assert.IsType(t, &PostNotFoundError{}, err)
+
+	trash, err := ts.usecase.GetTrash(context.Background(), user.ID)
 	assert.NoError(t, err)
-	assert.True(t, liked)
+	assert.Len(t, trash, 1)
+	assert.Equal(t, id, trash[0].ID)
 
-	anotherUserId := uuid.New()
-	liked, err = ts.usecase.UserLikedPost(context.Background(), anotherUserId, postId)
+	// Only the author can restore the post
+	err = ts.usecase.Restore(context.Background(), uuid.New(), id)
+	assert.IsType(t, &PostNotFoundError{}, err)
+
+	err = ts.usecase.Restore(context.Background(), user.ID, id)
 	assert.NoError(t, err)
-	assert.False(t, liked)
 
-	anotherPostId := uuid.New()
-	liked, err = ts.usecase.UserLikedPost(context.Background(), userId, anotherPostId)
-	assert.Error(t, err)
-	assert.False(t, liked)
+	post, err := ts.usecase.GetPost(context.Background(), user.ID, id)
+	assert.NoError(t, err)
+	assert.Nil(t, post.DeletedAt)
+
+	trash, err = ts.usecase.GetTrash(context.Background(), user.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, trash)
 }
 
-func TestGetLikes(t *testing.T) {
+func TestRestoreExpiredPost(t *testing.T) {
 	ts := setup()
 
-	postId := uuid.New()
-	userId1 := uuid.New()
-	userId2 := uuid.New()
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	id, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10)})
+	assert.NoError(t, err)
 
-	err := ts.usecase.Like(context.Background(), userId1, postId)
+	deletedAt := time.Now().Add(-shared.TrashRetention - time.Hour)
+	post := ts.repo.posts[id]
+	post.DeletedAt = &deletedAt
+	ts.repo.posts[id] = post
+
+	trash, err := ts.usecase.GetTrash(context.Background(), user.ID)
 	assert.NoError(t, err)
+	assert.Empty(t, trash)
 
-	err = ts.usecase.Like(context.Background(), userId2, postId)
+	err = ts.usecase.Restore(context.Background(), user.ID, id)
+	assert.IsType(t, &PostNotFoundError{}, err)
+}
+
+func TestArchivePost(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	id, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10)})
+	assert.NoError(t, err)
+	err = ts.usecase.Like(context.Background(), uuid.New(), id)
 	assert.NoError(t, err)
 
-	likedUsers, err := ts.usecase.GetLikes(context.Background(), postId)
+	err = ts.usecase.Archive(context.Background(), id)
 	assert.NoError(t, err)
-	assert.Len(t, likedUsers, 2)
-	assert.Contains(t, likedUsers, shared.User{ID: userId1})
-	assert.Contains(t, likedUsers, shared.User{ID: userId2})
-}
 
-// mockPostRepository is a mock implementation of iPostRepository for testing
-type mockPostRepository struct {
-	posts map[uuid.UUID]shared.Post
-	likes map[uuid.UUID][]uuid.UUID
-}
+	posts, err := ts.usecase.GetPosts(context.Background(), user.ID, 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Empty(t, posts)
 
-func newMockPostRepository() *mockPostRepository {
-	return &mockPostRepository{
-		posts: make(map[uuid.UUID]shared.Post),
-		likes: make(map[uuid.UUID][]uuid.UUID),
-	}
-}
+	// Archived posts keep their likes and can still be read by id
+	post, err := ts.usecase.GetPost(context.Background(), user.ID, id)
+	assert.NoError(t, err)
+	assert.NotNil(t, post.ArchivedAt)
+	likes, err := ts.usecase.GetLikes(context.Background(), user.ID, id)
+	assert.NoError(t, err)
+	assert.Len(t, likes, 1)
 
-func (m *mockPostRepository) create(ctx context.Context, post shared.Post) (uuid.UUID, error) {
-	id := uuid.New()
-	m.posts[id] = post
+	err = ts.usecase.Unarchive(context.Background(), id)
+	assert.NoError(t, err)
 
-	return id, nil
+	posts, err = ts.usecase.GetPosts(context.Background(), user.ID, 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+
+	draftId, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), Draft: true})
+	assert.NoError(t, err)
+	err = ts.usecase.Archive(context.Background(), draftId)
+	assert.IsType(t, &PostIsDraftError{}, err)
 }
 
-func (m *mockPostRepository) getPosts(ctx context.Context, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
-	var result []shared.Post
-	for id, post := range m.posts {
-		if len(result) >= limit {
-			break
-		}
-		if id != lastId && post.CreatedAt.After(lastCreatedAt) {
-			result = append(result, post)
-		}
-	}
+func TestPinPost(t *testing.T) {
+	ts := setup()
 
-	return result, nil
-}
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	var ids []uuid.UUID
+	for i := 0; i <= maxPinnedPosts; i++ {
+		id, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10)})
+		assert.NoError(t, err)
+		ids = append(ids, id)
+	}
 
-func (m *mockPostRepository) getPost(ctx context.Context, id uuid.UUID) (shared.Post, error) {
-	post, exists := m.posts[id]
-	if !exists {
-		return shared.Post{}, fmt.Errorf("post not found")
+	for _, id := range ids[:maxPinnedPosts] {
+		err := ts.usecase.Pin(context.Background(), id)
+		assert.NoError(t, err)
 	}
 
-	return post, nil
-}
+	// Pinning a pinned post again is a no-op, but no more than maxPinnedPosts can be pinned
+	err := ts.usecase.Pin(context.Background(), ids[0])
+	assert.NoError(t, err)
+	err = ts.usecase.Pin(context.Background(), ids[maxPinnedPosts])
+	assert.IsType(t, &TooManyPinnedPostsError{}, err)
 
-func (m *mockPostRepository) update(ctx context.Context, post shared.Post, id uuid.UUID) error {
-	if _, exists := m.posts[id]; !exists {
-		return fmt.Errorf("post not found")
-	}
-	m.posts[id] = post
+	err = ts.usecase.Unpin(context.Background(), ids[0])
+	assert.NoError(t, err)
+	err = ts.usecase.Pin(context.Background(), ids[maxPinnedPosts])
+	assert.NoError(t, err)
 
-	return nil
+	// Archived posts leave the profile and are unpinned
+	err = ts.usecase.Archive(context.Background(), ids[1])
+	assert.NoError(t, err)
+	assert.Nil(t, ts.repo.posts[ids[1]].PinnedAt)
+	err = ts.usecase.Pin(context.Background(), ids[1])
+	assert.IsType(t, &PostIsArchivedError{}, err)
+
+	draftId, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), Draft: true})
+	assert.NoError(t, err)
+	err = ts.usecase.Pin(context.Background(), draftId)
+	assert.IsType(t, &PostIsDraftError{}, err)
 }
 
-func (m *mockPostRepository) delete(ctx context.Context, id uuid.UUID) error {
-	if _, exists := m.posts[id]; !exists {
-		return fmt.Errorf("post not found")
+func newTestPoll(multipleChoice bool, options ...string) *shared.Poll {
+	poll := &shared.Poll{MultipleChoice: multipleChoice, ClosesAt: time.Now().Add(24 * time.Hour)}
+	for _, text := range options {
+		poll.Options = append(poll.Options, shared.PollOption{Text: text})
 	}
-	delete(m.posts, id)
 
-	return nil
+	return poll
 }
 
-func (m *mockPostRepository) like(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error {
-	if m.likes[postId] == nil {
-		m.likes[postId] = []uuid.UUID{}
-	}
-	m.likes[postId] = append(m.likes[postId], userId)
+func TestCreatePoll(t *testing.T) {
+	ts := setup()
 
-	return nil
-}
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	question := "Cats or dogs?"
 
-func (m *mockPostRepository) getLikes(ctx context.Context, id uuid.UUID) ([]shared.User, error) {
-	var userList []shared.User
-	for _, userId := range m.likes[id] {
-		userList = append(userList, shared.User{ID: userId})
-	}
+	// Polls need a question when no image is attached
+	_, err := ts.createPost(context.Background(), shared.Post{User: &user, Poll: newTestPoll(false, "Cats", "Dogs")})
+	assert.Error(t, err)
 
-	return userList, nil
+	_, err = ts.createPost(context.Background(), shared.Post{User: &user, Description: &question, Poll: newTestPoll(false, "Cats")})
+	assert.IsType(t, &InvalidPollOptionsError{}, err)
+	_, err = ts.createPost(context.Background(), shared.Post{User: &user, Description: &question, Poll: newTestPoll(false, "A", "B", "C", "D", "E")})
+	assert.IsType(t, &InvalidPollOptionsError{}, err)
+	_, err = ts.createPost(context.Background(), shared.Post{User: &user, Description: &question, Poll: newTestPoll(false, "Cats", "  ")})
+	assert.IsType(t, &InvalidPollOptionsError{}, err)
+
+	poll := newTestPoll(false, "Cats", "Dogs")
+	poll.ClosesAt = time.Now().Add(MaxPollDuration + time.Hour)
+	_, err = ts.createPost(context.Background(), shared.Post{User: &user, Description: &question, Poll: poll})
+	assert.IsType(t, &InvalidPollCloseError{}, err)
+	poll.ClosesAt = time.Now().Add(-time.Minute)
+	_, err = ts.createPost(context.Background(), shared.Post{User: &user, Description: &question, Poll: poll})
+	assert.IsType(t, &InvalidPollCloseError{}, err)
+
+	id, err := ts.createPost(context.Background(), shared.Post{User: &user, Description: &question, Poll: newTestPoll(false, " Cats ", "Dogs")})
+	assert.NoError(t, err)
+	assert.Equal(t, shared.StatusReady, ts.repo.posts[id].Status)
+
+	created, err := ts.usecase.GetPoll(context.Background(), user.ID, id)
+	assert.NoError(t, err)
+	assert.Len(t, created.Options, 2)
+	assert.Equal(t, "Cats", created.Options[0].Text)
+	assert.Equal(t, 1, created.Options[1].Position)
+
+	otherId, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10)})
+	assert.NoError(t, err)
+	_, err = ts.usecase.GetPoll(context.Background(), user.ID, otherId)
+	assert.IsType(t, &PollNotFoundError{}, err)
 }
 
-func (m *mockPostRepository) unlike(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error {
-	users := m.likes[postId]
-	for i, id := range users {
-		if id == userId {
-			m.likes[postId] = append(users[:i], users[i+1:]...)
-			return nil
-		}
-	}
+func TestVote(t *testing.T) {
+	ts := setup()
 
-	return fmt.Errorf("user has not liked this post")
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	question := "Cats or dogs?"
+	id, err := ts.createPost(context.Background(), shared.Post{User: &user, Description: &question, Poll: newTestPoll(false, "Cats", "Dogs")})
+	assert.NoError(t, err)
+	options := ts.repo.posts[id].Poll.Options
+
+	voterId := uuid.New()
+	err = ts.usecase.Vote(context.Background(), voterId, id, []uuid.UUID{options[0].ID})
+	assert.NoError(t, err)
+
+	// Results are hidden from the viewers who have not voted while the poll is open
+	poll, err := ts.usecase.GetPoll(context.Background(), user.ID, id)
+	assert.NoError(t, err)
+	assert.False(t, poll.Voted)
+	assert.Equal(t, 1, poll.VoterCount)
+	assert.Nil(t, poll.Options[0].VoteCount)
+
+	poll, err = ts.usecase.GetPoll(context.Background(), voterId, id)
+	assert.NoError(t, err)
+	assert.True(t, poll.Voted)
+	assert.True(t, poll.Options[0].Voted)
+	assert.Equal(t, 1, *poll.Options[0].VoteCount)
+	assert.Equal(t, 0, *poll.Options[1].VoteCount)
+
+	err = ts.usecase.Vote(context.Background(), voterId, id, []uuid.UUID{options[1].ID})
+	assert.IsType(t, &AlreadyVotedError{}, err)
+
+	err = ts.usecase.Vote(context.Background(), uuid.New(), id, []uuid.UUID{options[0].ID, options[1].ID})
+	assert.IsType(t, &InvalidVoteError{}, err)
+	err = ts.usecase.Vote(context.Background(), uuid.New(), id, []uuid.UUID{uuid.New()})
+	assert.IsType(t, &InvalidVoteError{}, err)
+	err = ts.usecase.Vote(context.Background(), uuid.New(), id, nil)
+	assert.IsType(t, &InvalidVoteError{}, err)
+
+	// Closed polls show their results to everyone and take no more votes
+	post := ts.repo.posts[id]
+	post.Poll.ClosesAt = time.Now().Add(-time.Minute)
+	ts.repo.posts[id] = post
+
+	err = ts.usecase.Vote(context.Background(), user.ID, id, []uuid.UUID{options[1].ID})
+	assert.IsType(t, &PollClosedError{}, err)
+
+	poll, err = ts.usecase.GetPoll(context.Background(), user.ID, id)
+	assert.NoError(t, err)
+	assert.True(t, poll.Closed)
+	assert.Equal(t, 1, *poll.Options[0].VoteCount)
 }
 
-func (m *mockPostRepository) userLikedPost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) (bool, error) {
-	users, exists := m.likes[postId]
-	if !exists {
-		return false, fmt.Errorf("post not found")
-	}
+func TestVoteMultipleChoice(t *testing.T) {
+	ts := setup()
 
-	for _, id := range users {
-		if id == userId {
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	question := "Favourite seasons?"
+	id, err := ts.createPost(context.Background(), shared.Post{User: &user, Description: &question, Poll: newTestPoll(true, "Spring", "Summer", "Autumn")})
+	assert.NoError(t, err)
+	options := ts.repo.posts[id].Poll.Options
+
+	// Options picked twice count once
+	err = ts.usecase.Vote(context.Background(), user.ID, id, []uuid.UUID{options[0].ID, options[2].ID, options[0].ID})
+	assert.NoError(t, err)
+
+	poll, err := ts.usecase.GetPoll(context.Background(), user.ID, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, *poll.Options[0].VoteCount)
+	assert.Equal(t, 0, *poll.Options[1].VoteCount)
+	assert.Equal(t, 1, *poll.Options[2].VoteCount)
+}
+
+func TestCreatePostWithPlace(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+
+	_, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), Place: &shared.Place{Name: " ", Latitude: 10, Longitude: 10}})
+	assert.IsType(t, &InvalidPlaceError{}, err)
+	_, err = ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), Place: &shared.Place{Name: "Nowhere", Latitude: 91, Longitude: 10}})
+	assert.IsType(t, &InvalidPlaceError{}, err)
+
+	// Coordinates are rounded and places with the same name and rounded coordinates are shared
+	place := &shared.Place{Name: " Praça da Sé ", Latitude: -23.550520, Longitude: -46.633308}
+	id, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), Place: place})
+	assert.NoError(t, err)
+	otherId, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), Place: &shared.Place{Name: "Praça da Sé", Latitude: -23.5509, Longitude: -46.6329}})
+	assert.NoError(t, err)
+
+	post, err := ts.usecase.GetPost(context.Background(), user.ID, id)
+	assert.NoError(t, err)
+	assert.Equal(t, "Praça da Sé", post.Place.Name)
+	assert.Equal(t, -23.551, post.Place.Latitude)
+	assert.Equal(t, -46.633, post.Place.Longitude)
+	assert.Equal(t, ts.repo.posts[otherId].Place.ID, post.Place.ID)
+
+	posts, err := ts.usecase.GetPostsByPlace(context.Background(), uuid.New(), post.Place.ID, 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 2)
+
+	// Posts lose their place when edited without one
+	err = ts.usecase.Update(context.Background(), shared.Post{User: &user, Media: []shared.PostMedia{{ID: uuid.New()}}}, otherId)
+	assert.NoError(t, err)
+	post, err = ts.usecase.GetPost(context.Background(), user.ID, otherId)
+	assert.NoError(t, err)
+	assert.Nil(t, post.Place)
+}
+
+func TestGetNearbyPosts(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	nearId, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), Place: &shared.Place{Name: "Near", Latitude: 48.8584, Longitude: 2.2945}})
+	assert.NoError(t, err)
+	_, err = ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), Place: &shared.Place{Name: "Far", Latitude: 48.8606, Longitude: 2.3376}})
+	assert.NoError(t, err)
+	_, err = ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10)})
+	assert.NoError(t, err)
+
+	posts, err := ts.usecase.GetNearbyPosts(context.Background(), uuid.New(), 48.8580, 2.2950, 1000)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+	assert.Equal(t, nearId, posts[0].ID)
+
+	posts, err = ts.usecase.GetNearbyPosts(context.Background(), uuid.New(), 48.8580, 2.2950, 5000)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 2)
+
+	_, err = ts.usecase.GetNearbyPosts(context.Background(), uuid.New(), 48.8580, 200, 1000)
+	assert.Error(t, err)
+	_, err = ts.usecase.GetNearbyPosts(context.Background(), uuid.New(), 48.8580, 2.2950, MaxNearbyRadius+1)
+	assert.Error(t, err)
+
+	// Users who hide their location have their posts left out of nearby searches and lose their places
+	ts.repo.hideLocation[user.ID] = true
+
+	posts, err = ts.usecase.GetNearbyPosts(context.Background(), uuid.New(), 48.8580, 2.2950, 5000)
+	assert.NoError(t, err)
+	assert.Empty(t, posts)
+
+	post, err := ts.usecase.GetPost(context.Background(), uuid.New(), nearId)
+	assert.NoError(t, err)
+	assert.Nil(t, post.Place)
+}
+
+func TestNearbyBounds(t *testing.T) {
+	minLatitude, minLongitude, maxLatitude, maxLongitude := nearbyBounds(0, 0, 1000)
+	assert.InDelta(t, -0.009, minLatitude, 0.0001)
+	assert.InDelta(t, 0.009, maxLatitude, 0.0001)
+	assert.InDelta(t, -0.009, minLongitude, 0.0001)
+	assert.InDelta(t, 0.009, maxLongitude, 0.0001)
+
+	// Boxes reaching a pole or crossing the antimeridian span every longitude
+	_, minLongitude, maxLatitude, maxLongitude = nearbyBounds(89.999, 10, 1000)
+	assert.Equal(t, 90.0, maxLatitude)
+	assert.Equal(t, -180.0, minLongitude)
+	assert.Equal(t, 180.0, maxLongitude)
+
+	_, minLongitude, _, maxLongitude = nearbyBounds(0, 179.999, 1000)
+	assert.Equal(t, -180.0, minLongitude)
+	assert.Equal(t, 180.0, maxLongitude)
+}
+
+func TestCreatePostContentWarning(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+
+	contentWarning := strings.Repeat("a", maxContentWarningLength+1)
+	_, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), ContentWarning: &contentWarning})
+	assert.IsType(t, &ContentWarningTooLongError{}, err)
+
+	// A content warning makes the post sensitive, blank ones being dropped
+	contentWarning = " spoilers "
+	id, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), ContentWarning: &contentWarning})
+	assert.NoError(t, err)
+	assert.Equal(t, "spoilers", *ts.repo.posts[id].ContentWarning)
+	assert.True(t, ts.repo.posts[id].Sensitive)
+
+	blank := " "
+	id, err = ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), ContentWarning: &blank, Blurred: true})
+	assert.NoError(t, err)
+	assert.Nil(t, ts.repo.posts[id].ContentWarning)
+	assert.False(t, ts.repo.posts[id].Sensitive)
+	assert.False(t, ts.repo.posts[id].Blurred)
+}
+
+func TestSensitivePostBlurred(t *testing.T) {
+	ts := setup()
+
+	author := shared.User{ID: uuid.New(), Username: "author"}
+	viewerId := uuid.New()
+
+	id, err := ts.createPost(context.Background(), shared.Post{User: &author, Image: newTestImage(t, 10, 10), Sensitive: true})
+	assert.NoError(t, err)
+
+	post, err := ts.usecase.GetPost(context.Background(), author.ID, id)
+	assert.NoError(t, err)
+	assert.True(t, post.Sensitive)
+	assert.False(t, post.Blurred)
+
+	post, err = ts.usecase.GetPost(context.Background(), viewerId, id)
+	assert.NoError(t, err)
+	assert.True(t, post.Sensitive)
+	assert.True(t, post.Blurred)
+
+	// Viewers who reveal sensitive media see it unblurred
+	ts.repo.showSensitive[viewerId] = true
+
+	post, err = ts.usecase.GetPost(context.Background(), viewerId, id)
+	assert.NoError(t, err)
+	assert.True(t, post.Sensitive)
+	assert.False(t, post.Blurred)
+}
+
+func TestFlagSensitive(t *testing.T) {
+	ts := setup()
+
+	author := shared.User{ID: uuid.New(), Username: "author"}
+	description := "a post #sunset"
+	id, err := ts.createPost(context.Background(), shared.Post{User: &author, Image: newTestImage(t, 10, 10), Description: &description})
+	assert.NoError(t, err)
+
+	err = ts.usecase.FlagSensitive(context.Background(), uuid.New(), SensitiveFlag{Sensitive: true})
+	assert.IsType(t, &PostNotFoundError{}, err)
+
+	contentWarning := " graphic content "
+	err = ts.usecase.FlagSensitive(context.Background(), id, SensitiveFlag{Sensitive: true, ContentWarning: &contentWarning})
+	assert.NoError(t, err)
+
+	post, err := ts.usecase.GetPost(context.Background(), uuid.New(), id)
+	assert.NoError(t, err)
+	assert.True(t, post.Sensitive)
+	assert.True(t, post.Blurred)
+	assert.Equal(t, "graphic content", *post.ContentWarning)
+
+	// The author cannot lift the flag of a moderator by editing the post
+	err = ts.usecase.Update(context.Background(), shared.Post{User: &author, Media: []shared.PostMedia{{ID: uuid.New()}}, Description: &description}, id)
+	assert.NoError(t, err)
+
+	post, err = ts.usecase.GetPost(context.Background(), uuid.New(), id)
+	assert.NoError(t, err)
+	assert.True(t, post.Sensitive)
+	assert.Equal(t, "graphic content", *post.ContentWarning)
+
+	// Sensitive posts are left out of explore surfaces unless the viewer reveals sensitive media
+	viewerId := uuid.New()
+	posts, err := ts.usecase.GetPostsByTag(context.Background(), viewerId, "sunset", 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Empty(t, posts)
+
+	tags, err := ts.usecase.GetTrendingTags(context.Background(), time.Hour, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, tags)
+
+	ts.repo.showSensitive[viewerId] = true
+	posts, err = ts.usecase.GetPostsByTag(context.Background(), viewerId, "sunset", 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+
+	err = ts.usecase.FlagSensitive(context.Background(), id, SensitiveFlag{Sensitive: false})
+	assert.NoError(t, err)
+
+	post, err = ts.usecase.GetPost(context.Background(), uuid.New(), id)
+	assert.NoError(t, err)
+	assert.False(t, post.Sensitive)
+	assert.False(t, post.Blurred)
+	assert.Nil(t, post.ContentWarning)
+}
+
+func TestDeletePostNotFound(t *testing.T) {
+	ts := setup()
+
+	id := uuid.New()
+
+	err := ts.usecase.Delete(context.Background(), id)
+	assert.Error(t, err)
+	assert.Equal(t, "post not found", err.Error())
+}
+
+func TestLike(t *testing.T) {
+	ts := setup()
+
+	userId := uuid.New()
+	postId := uuid.New()
+
+	err := ts.usecase.Like(context.Background(), userId, postId)
+	assert.NoError(t, err)
+
+	likedUsers, err := ts.usecase.GetLikes(context.Background(), uuid.New(), postId)
+	assert.NoError(t, err)
+	assert.Len(t, likedUsers, 1)
+	assert.Equal(t, likedUsers[0].ID, userId)
+}
+
+func TestUnlike(t *testing.T) {
+	ts := setup()
+
+	userId := uuid.New()
+	postId := uuid.New()
+
+	err := ts.usecase.Like(context.Background(), userId, postId)
+	assert.NoError(t, err)
+
+	err = ts.usecase.Unlike(context.Background(), userId, postId)
+	assert.NoError(t, err)
+
+	likedUsers, err := ts.usecase.GetLikes(context.Background(), uuid.New(), postId)
+	assert.NoError(t, err)
+	assert.Len(t, likedUsers, 0)
+}
+
+func TestUnlikeUserNotLiked(t *testing.T) {
+	ts := setup()
+
+	userId := uuid.New()
+	postId := uuid.New()
+
+	err := ts.usecase.Unlike(context.Background(), userId, postId)
+	assert.Error(t, err)
+
+	likedUsers, err := ts.usecase.GetLikes(context.Background(), uuid.New(), postId)
+	assert.NoError(t, err)
+	assert.Len(t, likedUsers, 0)
+}
+
+func TestUserLikedPost(t *testing.T) {
+	ts := setup()
+
+	author := shared.User{ID: uuid.New(), Username: "author"}
+	postId, err := ts.createPost(context.Background(), shared.Post{User: &author, Image: newTestImage(t, 10, 10)})
+	assert.NoError(t, err)
+
+	userId := uuid.New()
+	err = ts.usecase.Like(context.Background(), userId, postId)
+	assert.NoError(t, err)
+
+	liked, err := ts.usecase.UserLikedPost(context.Background(), userId, userId, postId)
+	assert.NoError(t, err)
+	assert.True(t, liked)
+
+	anotherUserId := uuid.New()
+	liked, err = ts.usecase.UserLikedPost(context.Background(), userId, anotherUserId, postId)
+	assert.NoError(t, err)
+	assert.False(t, liked)
+
+	anotherPostId := uuid.New()
+	liked, err = ts.usecase.UserLikedPost(context.Background(), userId, userId, anotherPostId)
+	assert.Error(t, err)
+	assert.False(t, liked)
+}
+
+func TestUserLikedPostNotVisible(t *testing.T) {
+	ts := setup()
+
+	author := shared.User{ID: uuid.New(), Username: "author"}
+	followerId := uuid.New()
+	ts.repo.followed[followerId] = []uuid.UUID{author.ID}
+	postId, err := ts.createPost(context.Background(), shared.Post{User: &author, Image: newTestImage(t, 10, 10), Visibility: shared.VisibilityFollowers})
+	assert.NoError(t, err)
+
+	err = ts.usecase.Like(context.Background(), followerId, postId)
+	assert.NoError(t, err)
+	err = ts.usecase.Save(context.Background(), followerId, postId)
+	assert.NoError(t, err)
+
+	// Strangers can not learn whether a followers only post exists, nor who liked it
+	strangerId := uuid.New()
+	_, err = ts.usecase.UserLikedPost(context.Background(), strangerId, followerId, postId)
+	assert.IsType(t, &PostNotFoundError{}, err)
+	_, err = ts.usecase.UserSavedPost(context.Background(), strangerId, postId)
+	assert.IsType(t, &PostNotFoundError{}, err)
+
+	liked, err := ts.usecase.UserLikedPost(context.Background(), followerId, followerId, postId)
+	assert.NoError(t, err)
+	assert.True(t, liked)
+}
+
+func TestGetLikes(t *testing.T) {
+	ts := setup()
+
+	postId := uuid.New()
+	userId1 := uuid.New()
+	userId2 := uuid.New()
+
+	err := ts.usecase.Like(context.Background(), userId1, postId)
+	assert.NoError(t, err)
+
+	err = ts.usecase.Like(context.Background(), userId2, postId)
+	assert.NoError(t, err)
+
+	likedUsers, err := ts.usecase.GetLikes(context.Background(), uuid.New(), postId)
+	assert.NoError(t, err)
+	assert.Len(t, likedUsers, 2)
+	assert.Contains(t, likedUsers, shared.User{ID: userId1})
+	assert.Contains(t, likedUsers, shared.User{ID: userId2})
+}
+
+func TestCreatePostBannedImage(t *testing.T) {
+	ts := setup()
+
+	bannedImage, err := ts.usecase.BanImage(context.Background(), BannedImage{Image: newTestImage(t, 10, 10)})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, bannedImage.Hash)
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	post := shared.Post{User: &user, Image: newTestImage(t, 20, 20)}
+
+	id, err := ts.createPost(context.Background(), post)
+	var bannedErr *BannedImageError
+	assert.ErrorAs(t, err, &bannedErr)
+	assert.Equal(t, uuid.Nil, id)
+
+	err = ts.usecase.UnbanImage(context.Background(), bannedImage.ID)
+	assert.NoError(t, err)
+
+	id, err = ts.createPost(context.Background(), post)
+	assert.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, id)
+}
+
+func TestGetSimilarImages(t *testing.T) {
+	ts := setup()
+
+	// Hashes a few bits apart are similar, an inverted hash is not
+	hashes := map[string]uint64{"original": 0x0f0f0f0f0f0f0f0f, "copy": 0x0f0f0f0f0f0f0f08, "other": 0xf0f0f0f0f0f0f0f0}
+	ids := make(map[string]uuid.UUID)
+	for name, hash := range hashes {
+		hash := hash
+		ids[name] = uuid.New()
+		ts.repo.posts[ids[name]] = shared.Post{ID: ids[name], Media: []shared.PostMedia{{ID: uuid.New(), Hash: &hash}}, CreatedAt: time.Now()}
+	}
+
+	images, err := ts.usecase.GetSimilarImages(context.Background(), ids["original"], BannedImageMaxDistance)
+	assert.NoError(t, err)
+	assert.Len(t, images, 1)
+	assert.Equal(t, ids["copy"], images[0].PostID)
+	assert.Equal(t, 3, images[0].Distance)
+
+	images, err = ts.usecase.GetSimilarImages(context.Background(), ids["original"], 2)
+	assert.NoError(t, err)
+	assert.Empty(t, images)
+
+	_, err = ts.usecase.GetSimilarImages(context.Background(), ids["original"], MaxSimilarDistance+1)
+	assert.Error(t, err)
+}
+
+func TestBanImageEmpty(t *testing.T) {
+	ts := setup()
+
+	_, err := ts.usecase.BanImage(context.Background(), BannedImage{})
+	assert.Error(t, err)
+
+	_, err = ts.usecase.BanImage(context.Background(), BannedImage{Image: "invalid"})
+	assert.Error(t, err)
+}
+
+func TestParseHashtags(t *testing.T) {
+	description := "Sunset at the #Beach #beach with #friends_2024, see example.com/#anchor &#38; #2024 #café"
+
+	tags := ParseHashtags(&description)
+	assert.Equal(t, []string{"beach", "friends_2024", "café"}, tags)
+	assert.Nil(t, ParseHashtags(nil))
+}
+
+func TestCreatePostTags(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	description := "Morning run #Running #morning"
+	post := shared.Post{User: &user, Image: newTestImage(t, 10, 10), Description: &description}
+
+	id, err := ts.createPost(context.Background(), post)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"running", "morning"}, ts.repo.posts[id].Tags)
+
+	tagPosts, err := ts.usecase.GetPostsByTag(context.Background(), uuid.Nil, "#RUNNING", 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, tagPosts, 1)
+
+	description = "Evening run #evening"
+	post.Description = &description
+	err = ts.usecase.Update(context.Background(), post, id)
+	assert.NoError(t, err)
+
+	tagPosts, err = ts.usecase.GetPostsByTag(context.Background(), uuid.Nil, "running", 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, tagPosts, 0)
+
+	_, err = ts.usecase.GetPostsByTag(context.Background(), uuid.Nil, "not a tag", 10, time.Time{}, uuid.Nil)
+	assert.Error(t, err)
+}
+
+func TestGetTrendingTags(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	for _, description := range []string{"#go #pgx", "#go", "#go #chi"} {
+		description := description
+		_, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), Description: &description})
+		assert.NoError(t, err)
+	}
+
+	tags, err := ts.usecase.GetTrendingTags(context.Background(), 24*time.Hour, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []TrendingTag{{Name: "go", PostCount: 3}}, tags)
+
+	_, err = ts.usecase.GetTrendingTags(context.Background(), 0, 10)
+	assert.Error(t, err)
+
+	_, err = ts.usecase.GetTrendingTags(context.Background(), 30*time.Minute, 10)
+	assert.Error(t, err)
+
+	_, err = ts.usecase.GetTrendingTags(context.Background(), 24*time.Hour, MaxTrendingTags+1)
+	assert.Error(t, err)
+}
+
+func TestCreatePostMentions(t *testing.T) {
+	ts := setup()
+
+	aliceId := uuid.New()
+	ts.repo.users["alice"] = aliceId
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	description := "Hiking with @alice and @nobody, mail me at test@example.com"
+	post := shared.Post{User: &user, Image: newTestImage(t, 10, 10), Description: &description}
+
+	id, err := ts.createPost(context.Background(), post)
+	assert.NoError(t, err)
+	assert.Equal(t, []shared.Mention{{UserID: aliceId, Username: "alice", Offset: 12, Length: 6}}, ts.repo.posts[id].Mentions)
+}
+
+func TestSave(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	postId, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10)})
+	assert.NoError(t, err)
+
+	viewerId := uuid.New()
+	err = ts.usecase.Save(context.Background(), viewerId, postId)
+	assert.NoError(t, err)
+
+	saved, err := ts.usecase.UserSavedPost(context.Background(), viewerId, postId)
+	assert.NoError(t, err)
+	assert.True(t, saved)
+
+	post, err := ts.usecase.GetPost(context.Background(), viewerId, postId)
+	assert.NoError(t, err)
+	assert.True(t, post.Saved)
+
+	post, err = ts.usecase.GetPost(context.Background(), user.ID, postId)
+	assert.NoError(t, err)
+	assert.False(t, post.Saved)
+
+	err = ts.usecase.Unsave(context.Background(), viewerId, postId)
+	assert.NoError(t, err)
+
+	saved, err = ts.usecase.UserSavedPost(context.Background(), viewerId, postId)
+	assert.NoError(t, err)
+	assert.False(t, saved)
+}
+
+func TestRepost(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	postId, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10)})
+	assert.NoError(t, err)
+
+	viewerId := uuid.New()
+	repostId, err := ts.usecase.Repost(context.Background(), viewerId, postId)
+	assert.NoError(t, err)
+
+	// Reposting twice, or reposting the repost, keeps a single repost of the original
+	id, err := ts.usecase.Repost(context.Background(), viewerId, repostId)
+	assert.NoError(t, err)
+	assert.Equal(t, repostId, id)
+
+	post, err := ts.usecase.GetPost(context.Background(), viewerId, postId)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, post.RepostCount)
+
+	repost, err := ts.usecase.GetPost(context.Background(), viewerId, repostId)
+	assert.NoError(t, err)
+	assert.Equal(t, postId, repost.RepostOf.ID)
+
+	err = ts.usecase.Unrepost(context.Background(), viewerId, postId)
+	assert.NoError(t, err)
+
+	post, err = ts.usecase.GetPost(context.Background(), viewerId, postId)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, post.RepostCount)
+
+	_, err = ts.usecase.Repost(context.Background(), viewerId, uuid.New())
+	assert.IsType(t, &PostNotFoundError{}, err)
+}
+
+func TestCreateQuotePost(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	postId, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10)})
+	assert.NoError(t, err)
+
+	quoter := shared.User{ID: uuid.New(), Username: "quoter"}
+	_, err = ts.createPost(context.Background(), shared.Post{User: &quoter, QuoteOf: &shared.Post{ID: postId}})
+	assert.Error(t, err)
+
+	description := "Look at this"
+	quoteId, err := ts.createPost(context.Background(), shared.Post{User: &quoter, QuoteOf: &shared.Post{ID: postId}, Description: &description})
+	assert.NoError(t, err)
+	assert.Equal(t, shared.StatusReady, ts.repo.posts[quoteId].Status)
+	assert.Empty(t, ts.repo.posts[quoteId].Media)
+
+	post, err := ts.usecase.GetPost(context.Background(), user.ID, postId)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, post.RepostCount)
+
+	// Deleting the original keeps the quote, the embedded post no longer being readable
+	err = ts.usecase.Delete(context.Background(), postId)
+	assert.NoError(t, err)
+	_, err = ts.usecase.GetPost(context.Background(), quoter.ID, quoteId)
+	assert.NoError(t, err)
+	_, err = ts.usecase.GetPost(context.Background(), quoter.ID, postId)
+	assert.IsType(t, &PostNotFoundError{}, err)
+}
+
+func TestReact(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	postId, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10)})
+	assert.NoError(t, err)
+
+	viewerId := uuid.New()
+	err = ts.usecase.React(context.Background(), viewerId, postId, "Fire")
+	assert.NoError(t, err)
+	err = ts.usecase.React(context.Background(), user.ID, postId, ReactionHeart)
+	assert.NoError(t, err)
+
+	// A new reaction replaces the previous one of the user
+	err = ts.usecase.React(context.Background(), viewerId, postId, "laugh")
+	assert.NoError(t, err)
+
+	post, err := ts.usecase.GetPost(context.Background(), viewerId, postId)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"laugh": 1, ReactionHeart: 1}, post.Reactions)
+	assert.Equal(t, "laugh", *post.Reaction)
+
+	reactions, err := ts.usecase.GetReactions(context.Background(), viewerId, postId, "laugh")
+	assert.NoError(t, err)
+	assert.Len(t, reactions, 1)
+	assert.Equal(t, viewerId, reactions[0].User.ID)
+
+	err = ts.usecase.React(context.Background(), viewerId, postId, "unknown")
+	assert.IsType(t, &InvalidReactionError{}, err)
+
+	_, err = ts.usecase.GetReactions(context.Background(), viewerId, postId, "unknown")
+	assert.IsType(t, &InvalidReactionError{}, err)
+
+	err = ts.usecase.Unreact(context.Background(), viewerId, postId)
+	assert.NoError(t, err)
+
+	reactions, err = ts.usecase.GetReactions(context.Background(), viewerId, postId, "")
+	assert.NoError(t, err)
+	assert.Len(t, reactions, 1)
+}
+
+func TestReactionTypes(t *testing.T) {
+	assert.Equal(t, defaultReactionTypes, ReactionTypes())
+
+	t.Setenv("POST_REACTIONS", " Clap, fire,,clap ")
+	assert.Equal(t, []string{ReactionHeart, "clap", "fire"}, ReactionTypes())
+	assert.True(t, IsReactionType(ReactionHeart))
+	assert.False(t, IsReactionType("laugh"))
+}
+
+func TestPostVisibility(t *testing.T) {
+	ts := setup()
+
+	author := shared.User{ID: uuid.New(), Username: "author"}
+	followerId := uuid.New()
+	friendId := uuid.New()
+	strangerId := uuid.New()
+	ts.repo.followed[followerId] = []uuid.UUID{author.ID}
+	ts.repo.closeFriends[author.ID] = []uuid.UUID{friendId}
+
+	publicId, err := ts.createPost(context.Background(), shared.Post{User: &author, Image: newTestImage(t, 10, 10)})
+	assert.NoError(t, err)
+	assert.Equal(t, shared.VisibilityPublic, ts.repo.posts[publicId].Visibility)
+
+	_, err = ts.createPost(context.Background(), shared.Post{User: &author, Image: newTestImage(t, 10, 10), Visibility: "friends"})
+	assert.Error(t, err)
+
+	followersId, err := ts.createPost(context.Background(), shared.Post{User: &author, Image: newTestImage(t, 10, 10), Visibility: shared.VisibilityFollowers})
+	assert.NoError(t, err)
+	closeFriendsId, err := ts.createPost(context.Background(), shared.Post{User: &author, Image: newTestImage(t, 10, 10), Visibility: shared.VisibilityCloseFriends})
+	assert.NoError(t, err)
+
+	visible := map[uuid.UUID][]uuid.UUID{
+		author.ID:  {publicId, followersId, closeFriendsId},
+		followerId: {publicId, followersId},
+		friendId:   {publicId, closeFriendsId},
+		strangerId: {publicId},
+	}
+	for viewerId, ids := range visible {
+		for _, id := range []uuid.UUID{publicId, followersId, closeFriendsId} {
+			_, err := ts.usecase.GetPost(context.Background(), viewerId, id)
+			if containsId(ids, id) {
+				assert.NoError(t, err)
+			} else {
+				assert.IsType(t, &PostNotFoundError{}, err)
+			}
+		}
+	}
+}
+
+func TestCreatePostCommentPolicy(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+
+	_, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), CommentPolicy: "friends"})
+	assert.IsType(t, &InvalidCommentPolicyError{}, err)
+
+	id, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10)})
+	assert.NoError(t, err)
+	assert.Equal(t, shared.CommentPolicyEveryone, ts.repo.posts[id].CommentPolicy)
+
+	err = ts.usecase.Update(context.Background(), shared.Post{User: &user, Media: []shared.PostMedia{{ID: uuid.New()}}, CommentPolicy: shared.CommentPolicyOff}, id)
+	assert.NoError(t, err)
+	assert.Equal(t, shared.CommentPolicyOff, ts.repo.posts[id].CommentPolicy)
+}
+
+func TestDrafts(t *testing.T) {
+	ts := setup()
+
+	author := shared.User{ID: uuid.New(), Username: "author"}
+	viewerId := uuid.New()
+
+	draftId, err := ts.createPost(context.Background(), shared.Post{User: &author, Image: newTestImage(t, 10, 10), Draft: true})
+	assert.NoError(t, err)
+
+	// Drafts are only readable by their author
+	_, err = ts.usecase.GetPost(context.Background(), author.ID, draftId)
+	assert.NoError(t, err)
+	_, err = ts.usecase.GetPost(context.Background(), viewerId, draftId)
+	assert.IsType(t, &PostNotFoundError{}, err)
+
+	drafts, err := ts.usecase.GetDrafts(context.Background(), author.ID)
+	assert.NoError(t, err)
+	assert.Len(t, drafts, 1)
+
+	err = ts.usecase.Schedule(context.Background(), draftId, time.Now().Add(-time.Minute))
+	assert.IsType(t, &InvalidScheduleError{}, err)
+
+	err = ts.usecase.Publish(context.Background(), draftId)
+	assert.NoError(t, err)
+	assert.False(t, ts.repo.posts[draftId].Draft)
+
+	_, err = ts.usecase.GetPost(context.Background(), viewerId, draftId)
+	assert.NoError(t, err)
+
+	err = ts.usecase.Publish(context.Background(), draftId)
+	assert.IsType(t, &PostNotDraftError{}, err)
+	err = ts.usecase.Schedule(context.Background(), draftId, time.Now().Add(time.Hour))
+	assert.IsType(t, &PostNotDraftError{}, err)
+}
+
+func TestScheduledPost(t *testing.T) {
+	ts := setup()
+
+	author := shared.User{ID: uuid.New(), Username: "author"}
+
+	past := time.Now().Add(-time.Minute)
+	_, err := ts.createPost(context.Background(), shared.Post{User: &author, Image: newTestImage(t, 10, 10), ScheduledAt: &past})
+	assert.IsType(t, &InvalidScheduleError{}, err)
+
+	// Scheduled posts are kept as drafts, their time being stored in UTC
+	future := time.Now().Add(time.Hour).In(time.FixedZone("UTC+2", 2*60*60))
+	newPost, err := ts.usecase.Create(context.Background(), shared.Post{User: &author, Image: newTestImage(t, 10, 10), ScheduledAt: &future})
+	assert.NoError(t, err)
+	assert.True(t, newPost.Draft)
+	assert.Equal(t, shared.StatusReady, newPost.Status)
+	id := newPost.ID
+	assert.True(t, ts.repo.posts[id].Draft)
+	assert.Equal(t, time.UTC, ts.repo.posts[id].ScheduledAt.Location())
+	assert.True(t, future.Equal(*ts.repo.posts[id].ScheduledAt))
+
+	err = ts.usecase.Unschedule(context.Background(), id)
+	assert.NoError(t, err)
+	assert.True(t, ts.repo.posts[id].Draft)
+	assert.Nil(t, ts.repo.posts[id].ScheduledAt)
+}
+
+func TestPostRevisions(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	first := "first version"
+	id, err := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), Description: &first})
+	assert.NoError(t, err)
+	assert.Nil(t, ts.repo.posts[id].EditedAt)
+
+	second := "second version"
+	err = ts.usecase.Update(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), Description: &second}, id)
+	assert.NoError(t, err)
+
+	post, err := ts.usecase.GetPost(context.Background(), user.ID, id)
+	assert.NoError(t, err)
+	assert.Equal(t, second, *post.Description)
+	assert.NotNil(t, post.EditedAt)
+
+	revisions, err := ts.usecase.GetRevisions(context.Background(), user.ID, id)
+	assert.NoError(t, err)
+	assert.Len(t, revisions, 1)
+	assert.Equal(t, first, *revisions[0].Description)
+
+	// Drafts are edited without keeping revisions
+	draftId, _ := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), Draft: true})
+	err = ts.usecase.Update(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10), Description: &second}, draftId)
+	assert.NoError(t, err)
+	assert.Empty(t, ts.repo.revisions[draftId])
+	assert.Nil(t, ts.repo.posts[draftId].EditedAt)
+}
+
+func TestUpdatePostEditWindow(t *testing.T) {
+	ts := setup()
+	t.Setenv("POST_EDIT_WINDOW", "1h")
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	id, _ := ts.createPost(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10)})
+
+	err := ts.usecase.Update(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10)}, id)
+	assert.NoError(t, err)
+
+	post := ts.repo.posts[id]
+	post.CreatedAt = time.Now().Add(-2 * time.Hour)
+	ts.repo.posts[id] = post
+
+	err = ts.usecase.Update(context.Background(), shared.Post{User: &user, Image: newTestImage(t, 10, 10)}, id)
+	assert.IsType(t, &EditWindowExpiredError{}, err)
+
+	t.Setenv("POST_EDIT_WINDOW", "")
+	assert.Equal(t, time.Duration(0), EditWindow())
+	t.Setenv("POST_EDIT_WINDOW", "soon")
+	assert.Equal(t, time.Duration(0), EditWindow())
+}
+
+// mockPostRepository is a mock implementation of iPostRepository for testing
+type mockPostRepository struct {
+	posts         map[uuid.UUID]shared.Post
+	likes         map[uuid.UUID][]uuid.UUID
+	bannedImages  []BannedImage
+	users         map[string]uuid.UUID
+	saves         map[uuid.UUID][]uuid.UUID
+	reactions     map[uuid.UUID]map[uuid.UUID]string
+	followed      map[uuid.UUID][]uuid.UUID
+	closeFriends  map[uuid.UUID][]uuid.UUID
+	revisions     map[uuid.UUID][]PostRevision
+	pollVotes     map[uuid.UUID]map[uuid.UUID][]uuid.UUID
+	places        map[shared.Place]uuid.UUID
+	hideLocation  map[uuid.UUID]bool
+	flagged       map[uuid.UUID]bool
+	showSensitive map[uuid.UUID]bool
+}
+
+func newMockPostRepository() *mockPostRepository {
+	return &mockPostRepository{
+		posts:         make(map[uuid.UUID]shared.Post),
+		likes:         make(map[uuid.UUID][]uuid.UUID),
+		users:         make(map[string]uuid.UUID),
+		saves:         make(map[uuid.UUID][]uuid.UUID),
+		reactions:     make(map[uuid.UUID]map[uuid.UUID]string),
+		followed:      make(map[uuid.UUID][]uuid.UUID),
+		closeFriends:  make(map[uuid.UUID][]uuid.UUID),
+		revisions:     make(map[uuid.UUID][]PostRevision),
+		pollVotes:     make(map[uuid.UUID]map[uuid.UUID][]uuid.UUID),
+		places:        make(map[shared.Place]uuid.UUID),
+		hideLocation:  make(map[uuid.UUID]bool),
+		flagged:       make(map[uuid.UUID]bool),
+		showSensitive: make(map[uuid.UUID]bool),
+	}
+}
+
+func (m *mockPostRepository) create(ctx context.Context, post shared.Post) (uuid.UUID, error) {
+	if post.QuoteOf != nil {
+		originalId, err := m.originalId(post.QuoteOf.ID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		post.QuoteOf = &shared.Post{ID: originalId}
+	}
+
+	id := uuid.New()
+	post.ID = id
+	post.CreatedAt = time.Now()
+	if post.Poll != nil {
+		poll := *post.Poll
+		poll.Options = make([]shared.PollOption, len(post.Poll.Options))
+		for i, option := range post.Poll.Options {
+			option.ID = uuid.New()
+			poll.Options[i] = option
+		}
+		post.Poll = &poll
+	}
+	post.Place = m.upsertPlace(post.Place)
+	m.posts[id] = post
+
+	if post.QuoteOf != nil {
+		m.updateRepostCount(post.QuoteOf.ID)
+	}
+
+	return id, nil
+}
+
+func (m *mockPostRepository) getPosts(ctx context.Context, viewerId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+	var result []shared.Post
+	for id, post := range m.posts {
+		if len(result) >= limit {
+			break
+		}
+		if id != lastId && !post.Draft && post.DeletedAt == nil && post.ArchivedAt == nil && post.CreatedAt.After(lastCreatedAt) {
+			result = append(result, post)
+		}
+	}
+
+	return result, nil
+}
+
+func (m *mockPostRepository) getPost(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) (shared.Post, error) {
+	post, exists := m.posts[id]
+	if !exists || !(m.visibleTo(viewerId, post) || (post.Draft && post.DeletedAt == nil && post.User.ID == viewerId)) {
+		return shared.Post{}, &PostNotFoundError{}
+	}
+	post.Saved = m.hasSaved(viewerId, id)
+	post.Poll = m.pollFor(viewerId, post)
+	post.Place = m.placeOf(post)
+	post.Sensitive = m.sensitive(post)
+	post.Blurred = m.blurredFor(viewerId, post)
+
+	for userId, reaction := range m.reactions[id] {
+		if post.Reactions == nil {
+			post.Reactions = make(map[string]int)
+		}
+		post.Reactions[reaction]++
+		if userId == viewerId {
+			post.Reaction = &reaction
+		}
+	}
+
+	return post, nil
+}
+
+func (m *mockPostRepository) getBySearch(ctx context.Context, viewerId uuid.UUID, searchStr string) ([]shared.Post, error) {
+	var result []shared.Post
+	for id, post := range m.posts {
+		if !m.visibleTo(viewerId, post) || m.blurredFor(viewerId, post) || post.ArchivedAt != nil {
+			continue
+		}
+
+		found := post.Description != nil && strings.Contains(*post.Description, searchStr)
+		for _, item := range post.Media {
+			if item.AltText != nil && strings.Contains(*item.AltText, searchStr) {
+				found = true
+			}
+		}
+
+		if found {
+			post.ID = id
+			result = append(result, post)
+		}
+	}
+
+	return result, nil
+}
+
+func (m *mockPostRepository) update(ctx context.Context, post shared.Post, id uuid.UUID, editWindow time.Duration) error {
+	stored, exists := m.posts[id]
+	if !exists {
+		return fmt.Errorf("post not found")
+	}
+
+	if !stored.Draft {
+		if editWindow > 0 && time.Since(stored.CreatedAt) > editWindow {
+			return &EditWindowExpiredError{}
+		}
+
+		m.revisions[id] = append([]PostRevision{{ID: uuid.New(), PostID: id, Description: stored.Description, Visibility: stored.Visibility, Media: stored.Media}}, m.revisions[id]...)
+		editedAt := time.Now()
+		post.EditedAt = &editedAt
+	}
+	post.ID = id
+	post.Draft = stored.Draft
+	post.CreatedAt = stored.CreatedAt
+	post.Place = m.upsertPlace(post.Place)
+	if post.ContentWarning == nil && m.flagged[id] {
+		post.ContentWarning = stored.ContentWarning
+	}
+	m.posts[id] = post
+
+	return nil
+}
+
+func (m *mockPostRepository) delete(ctx context.Context, id uuid.UUID) error {
+	post, exists := m.posts[id]
+	if !exists || post.DeletedAt != nil {
+		return fmt.Errorf("post not found")
+	}
+
+	if post.RepostOf != nil {
+		delete(m.posts, id)
+		m.updateRepostCount(post.RepostOf.ID)
+		return nil
+	}
+
+	deletedAt := time.Now()
+	post.DeletedAt = &deletedAt
+	post.PinnedAt = nil
+	m.posts[id] = post
+
+	if post.QuoteOf != nil {
+		m.updateRepostCount(post.QuoteOf.ID)
+	}
+
+	return nil
+}
+
+func (m *mockPostRepository) like(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error {
+	if m.likes[postId] == nil {
+		m.likes[postId] = []uuid.UUID{}
+	}
+	m.likes[postId] = append(m.likes[postId], userId)
+
+	return nil
+}
+
+func (m *mockPostRepository) getLikes(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) ([]shared.User, error) {
+	var userList []shared.User
+	for _, userId := range m.likes[id] {
+		userList = append(userList, shared.User{ID: userId})
+	}
+
+	return userList, nil
+}
+
+func (m *mockPostRepository) unlike(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error {
+	users := m.likes[postId]
+	for i, id := range users {
+		if id == userId {
+			m.likes[postId] = append(users[:i], users[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("user has not liked this post")
+}
+
+func (m *mockPostRepository) userLikedPost(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID, postId uuid.UUID) (bool, error) {
+	post, exists := m.posts[postId]
+	if !exists || !m.visibleTo(viewerId, post) {
+		return false, &PostNotFoundError{}
+	}
+
+	for _, id := range m.likes[postId] {
+		if id == userId {
 			return true, nil
 		}
 	}
-	return false, nil
+	return false, nil
+}
+
+func (m *mockPostRepository) getVideoPath(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID, mediaId uuid.UUID) (string, error) {
+	post, exists := m.posts[postId]
+	if !exists {
+		return "", fmt.Errorf("post not found")
+	}
+
+	for _, item := range post.Media {
+		if item.ID == mediaId && item.Type == shared.MediaTypeVideo {
+			return item.VideoPath, nil
+		}
+	}
+
+	return "", fmt.Errorf("post video not found")
+}
+
+func (m *mockPostRepository) matchBannedImage(ctx context.Context, hashes []uint64, maxDistance int) (bool, error) {
+	for _, bannedImage := range m.bannedImages {
+		bannedHash, err := media.ParseHash(bannedImage.Hash)
+		if err != nil {
+			return false, err
+		}
+		for _, hash := range hashes {
+			if media.HammingDistance(hash, bannedHash) <= maxDistance {
+				return true, nil
+			}
+		}
+	}
+
+	return false, nil
+}
+
+func (m *mockPostRepository) banImage(ctx context.Context, bannedImage BannedImage) (BannedImage, error) {
+	bannedImage.ID = uuid.New()
+	bannedImage.Image = ""
+	m.bannedImages = append(m.bannedImages, bannedImage)
+
+	return bannedImage, nil
+}
+
+func (m *mockPostRepository) getBannedImages(ctx context.Context) ([]BannedImage, error) {
+	return m.bannedImages, nil
+}
+
+func (m *mockPostRepository) unbanImage(ctx context.Context, id uuid.UUID) error {
+	for i, bannedImage := range m.bannedImages {
+		if bannedImage.ID == id {
+			m.bannedImages = append(m.bannedImages[:i], m.bannedImages[i+1:]...)
+			return nil
+		}
+	}
+
+	return fmt.Errorf("banned image not found")
+}
+
+func (m *mockPostRepository) getSimilarImages(ctx context.Context, postId uuid.UUID, maxDistance int, limit int) ([]SimilarImage, error) {
+	post := m.posts[postId]
+
+	var images []SimilarImage
+	for id, other := range m.posts {
+		if id == postId {
+			continue
+		}
+		for _, item := range other.Media {
+			distance := -1
+			for _, own := range post.Media {
+				if own.Hash == nil || item.Hash == nil {
+					continue
+				}
+				if d := media.HammingDistance(*own.Hash, *item.Hash); distance < 0 || d < distance {
+					distance = d
+				}
+			}
+			if distance >= 0 && distance <= maxDistance {
+				images = append(images, SimilarImage{PostID: id, MediaID: item.ID, Distance: distance, CreatedAt: other.CreatedAt})
+			}
+		}
+	}
+	sort.Slice(images, func(i, j int) bool {
+		if images[i].Distance != images[j].Distance {
+			return images[i].Distance < images[j].Distance
+		}
+		return images[i].CreatedAt.Before(images[j].CreatedAt)
+	})
+	if len(images) > limit {
+		images = images[:limit]
+	}
+
+	return images, nil
+}
+
+func (m *mockPostRepository) getPostsByTag(ctx context.Context, viewerId uuid.UUID, tag string, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+	var result []shared.Post
+	for id, post := range m.posts {
+		if m.blurredFor(viewerId, post) || post.ArchivedAt != nil {
+			continue
+		}
+		for _, postTag := range post.Tags {
+			if postTag == tag && len(result) < limit {
+				post.ID = id
+				result = append(result, post)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (m *mockPostRepository) getTrendingTags(ctx context.Context, window time.Duration, limit int) ([]TrendingTag, error) {
+	counts := make(map[string]int)
+	for _, post := range m.posts {
+		if m.sensitive(post) || post.ArchivedAt != nil {
+			continue
+		}
+		for _, tag := range post.Tags {
+			counts[tag]++
+		}
+	}
+
+	tags := []TrendingTag{}
+	for name, count := range counts {
+		tags = append(tags, TrendingTag{Name: name, PostCount: count})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if tags[i].PostCount != tags[j].PostCount {
+			return tags[i].PostCount > tags[j].PostCount
+		}
+		return tags[i].Name < tags[j].Name
+	})
+	if len(tags) > limit {
+		tags = tags[:limit]
+	}
+
+	return tags, nil
+}
+
+func (m *mockPostRepository) getUserIdsByUsernames(ctx context.Context, usernames []string) (map[string]uuid.UUID, error) {
+	userIds := make(map[string]uuid.UUID)
+	for _, username := range usernames {
+		if id, exists := m.users[username]; exists {
+			userIds[username] = id
+		}
+	}
+
+	return userIds, nil
+}
+
+func (m *mockPostRepository) save(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error {
+	if !m.hasSaved(userId, postId) {
+		m.saves[postId] = append(m.saves[postId], userId)
+	}
+
+	return nil
+}
+
+func (m *mockPostRepository) unsave(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error {
+	users := m.saves[postId]
+	for i, id := range users {
+		if id == userId {
+			m.saves[postId] = append(users[:i], users[i+1:]...)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (m *mockPostRepository) userSavedPost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) (bool, error) {
+	post, exists := m.posts[postId]
+	if !exists || !m.visibleTo(userId, post) {
+		return false, &PostNotFoundError{}
+	}
+
+	return m.hasSaved(userId, postId), nil
+}
+
+func (m *mockPostRepository) hasSaved(userId uuid.UUID, postId uuid.UUID) bool {
+	for _, id := range m.saves[postId] {
+		if id == userId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *mockPostRepository) repost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) (uuid.UUID, error) {
+	originalId, err := m.originalId(postId)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	for id, post := range m.posts {
+		if post.RepostOf != nil && post.RepostOf.ID == originalId && post.User.ID == userId {
+			return id, nil
+		}
+	}
+
+	id := uuid.New()
+	m.posts[id] = shared.Post{ID: id, User: &shared.User{ID: userId}, RepostOf: &shared.Post{ID: originalId}, Status: shared.StatusReady}
+	m.updateRepostCount(originalId)
+
+	return id, nil
+}
+
+func (m *mockPostRepository) unrepost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error {
+	originalId, err := m.originalId(postId)
+	if err != nil {
+		return err
+	}
+
+	for id, post := range m.posts {
+		if post.RepostOf != nil && post.RepostOf.ID == originalId && post.User.ID == userId {
+			delete(m.posts, id)
+		}
+	}
+	m.updateRepostCount(originalId)
+
+	return nil
+}
+
+// originalId resolves a repost to the post it reposts
+func (m *mockPostRepository) originalId(id uuid.UUID) (uuid.UUID, error) {
+	post, exists := m.posts[id]
+	if !exists {
+		return uuid.Nil, &PostNotFoundError{}
+	}
+	if post.RepostOf != nil {
+		return post.RepostOf.ID, nil
+	}
+
+	return id, nil
+}
+
+func (m *mockPostRepository) updateRepostCount(id uuid.UUID) {
+	original, exists := m.posts[id]
+	if !exists {
+		return
+	}
+
+	original.RepostCount = 0
+	for _, post := range m.posts {
+		if post.DeletedAt == nil && ((post.RepostOf != nil && post.RepostOf.ID == id) || (post.QuoteOf != nil && post.QuoteOf.ID == id)) {
+			original.RepostCount++
+		}
+	}
+	m.posts[id] = original
+}
+
+func (m *mockPostRepository) react(ctx context.Context, userId uuid.UUID, postId uuid.UUID, reaction string) error {
+	if m.reactions[postId] == nil {
+		m.reactions[postId] = make(map[uuid.UUID]string)
+	}
+	m.reactions[postId][userId] = reaction
+
+	return nil
+}
+
+func (m *mockPostRepository) unreact(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error {
+	delete(m.reactions[postId], userId)
+
+	return nil
+}
+
+func (m *mockPostRepository) getReactions(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID, reaction string) ([]Reaction, error) {
+	var result []Reaction
+	for userId, userReaction := range m.reactions[postId] {
+		if reaction == "" || userReaction == reaction {
+			result = append(result, Reaction{User: &shared.User{ID: userId}, Type: userReaction})
+		}
+	}
+
+	return result, nil
+}
+
+func (m *mockPostRepository) getDrafts(ctx context.Context, userId uuid.UUID) ([]shared.Post, error) {
+	var result []shared.Post
+	for _, post := range m.posts {
+		if post.Draft && post.User.ID == userId {
+			result = append(result, post)
+		}
+	}
+
+	return result, nil
+}
+
+func (m *mockPostRepository) publish(ctx context.Context, id uuid.UUID) error {
+	post, exists := m.posts[id]
+	if !exists || !post.Draft {
+		return &PostNotDraftError{}
+	}
+	post.Draft = false
+	post.ScheduledAt = nil
+	post.CreatedAt = time.Now()
+	m.posts[id] = post
+
+	return nil
+}
+
+func (m *mockPostRepository) schedule(ctx context.Context, id uuid.UUID, scheduledAt *time.Time) error {
+	post, exists := m.posts[id]
+	if !exists || !post.Draft {
+		return &PostNotDraftError{}
+	}
+	post.ScheduledAt = scheduledAt
+	m.posts[id] = post
+
+	return nil
+}
+
+func (m *mockPostRepository) getRevisions(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID) ([]PostRevision, error) {
+	post, exists := m.posts[postId]
+	if !exists || !m.visibleTo(viewerId, post) {
+		return nil, &PostNotFoundError{}
+	}
+
+	return m.revisions[postId], nil
+}
+
+func (m *mockPostRepository) getTrash(ctx context.Context, userId uuid.UUID) ([]shared.Post, error) {
+	var result []shared.Post
+	for _, post := range m.posts {
+		if post.DeletedAt != nil && post.User.ID == userId && time.Since(*post.DeletedAt) <= shared.TrashRetention {
+			result = append(result, post)
+		}
+	}
+
+	return result, nil
+}
+
+func (m *mockPostRepository) restore(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	post, exists := m.posts[id]
+	if !exists || post.DeletedAt == nil || post.User.ID != userId || time.Since(*post.DeletedAt) > shared.TrashRetention {
+		return &PostNotFoundError{}
+	}
+	post.DeletedAt = nil
+	m.posts[id] = post
+
+	if post.QuoteOf != nil {
+		m.updateRepostCount(post.QuoteOf.ID)
+	}
+
+	return nil
+}
+
+func (m *mockPostRepository) archive(ctx context.Context, id uuid.UUID) error {
+	post, exists := m.posts[id]
+	if !exists || post.DeletedAt != nil {
+		return &PostNotFoundError{}
+	}
+	if post.Draft {
+		return &PostIsDraftError{}
+	}
+	if post.ArchivedAt == nil {
+		archivedAt := time.Now()
+		post.ArchivedAt = &archivedAt
+	}
+	post.PinnedAt = nil
+	m.posts[id] = post
+
+	return nil
+}
+
+func (m *mockPostRepository) unarchive(ctx context.Context, id uuid.UUID) error {
+	post, exists := m.posts[id]
+	if !exists || post.DeletedAt != nil {
+		return &PostNotFoundError{}
+	}
+	post.ArchivedAt = nil
+	m.posts[id] = post
+
+	return nil
+}
+
+func (m *mockPostRepository) pin(ctx context.Context, id uuid.UUID) error {
+	post, exists := m.posts[id]
+	switch {
+	case !exists || post.DeletedAt != nil:
+		return &PostNotFoundError{}
+	case post.Draft:
+		return &PostIsDraftError{}
+	case post.ArchivedAt != nil:
+		return &PostIsArchivedError{}
+	case post.PinnedAt != nil:
+		return nil
+	}
+
+	pinnedCount := 0
+	for _, other := range m.posts {
+		if other.User.ID == post.User.ID && other.PinnedAt != nil {
+			pinnedCount++
+		}
+	}
+	if pinnedCount >= maxPinnedPosts {
+		return &TooManyPinnedPostsError{}
+	}
+
+	pinnedAt := time.Now()
+	post.PinnedAt = &pinnedAt
+	m.posts[id] = post
+
+	return nil
+}
+
+func (m *mockPostRepository) unpin(ctx context.Context, id uuid.UUID) error {
+	post, exists := m.posts[id]
+	if !exists || post.DeletedAt != nil {
+		return &PostNotFoundError{}
+	}
+	post.PinnedAt = nil
+	m.posts[id] = post
+
+	return nil
+}
+
+func (m *mockPostRepository) vote(ctx context.Context, userId uuid.UUID, postId uuid.UUID, optionIds []uuid.UUID) error {
+	post, exists := m.posts[postId]
+	if !exists || post.Poll == nil || !m.visibleTo(userId, post) {
+		return &PollNotFoundError{}
+	}
+	if !post.Poll.ClosesAt.After(time.Now()) {
+		return &PollClosedError{}
+	}
+
+	for _, id := range optionIds {
+		found := false
+		for _, option := range post.Poll.Options {
+			found = found || option.ID == id
+		}
+		if !found {
+			return &InvalidVoteError{}
+		}
+	}
+	if !post.Poll.MultipleChoice && len(optionIds) > 1 {
+		return &InvalidVoteError{}
+	}
+
+	if m.pollVotes[postId] == nil {
+		m.pollVotes[postId] = make(map[uuid.UUID][]uuid.UUID)
+	}
+	if _, voted := m.pollVotes[postId][userId]; voted {
+		return &AlreadyVotedError{}
+	}
+	m.pollVotes[postId][userId] = optionIds
+
+	return nil
+}
+
+// pollFor mirrors attachPolls, reading the state of the poll of a post for the viewer
+func (m *mockPostRepository) pollFor(viewerId uuid.UUID, post shared.Post) *shared.Poll {
+	if post.Poll == nil {
+		return nil
+	}
+
+	poll := *post.Poll
+	poll.Closed = !poll.ClosesAt.After(time.Now())
+	poll.VoterCount = len(m.pollVotes[post.ID])
+	_, poll.Voted = m.pollVotes[post.ID][viewerId]
+
+	poll.Options = make([]shared.PollOption, len(post.Poll.Options))
+	for i, option := range post.Poll.Options {
+		voteCount := 0
+		for userId, optionIds := range m.pollVotes[post.ID] {
+			if containsId(optionIds, option.ID) {
+				voteCount++
+				option.Voted = option.Voted || userId == viewerId
+			}
+		}
+		option.VoteCount = &voteCount
+		poll.Options[i] = option
+	}
+	hidePollResults(&poll)
+
+	return &poll
+}
+
+func (m *mockPostRepository) getNearbyPosts(ctx context.Context, viewerId uuid.UUID, latitude float64, longitude float64, radius float64, limit int) ([]shared.Post, error) {
+	var result []shared.Post
+	for _, post := range m.posts {
+		place := m.placeOf(post)
+		if place == nil || post.ArchivedAt != nil || !m.visibleTo(viewerId, post) || m.blurredFor(viewerId, post) {
+			continue
+		}
+		if distance(latitude, longitude, place.Latitude, place.Longitude) <= radius && len(result) < limit {
+			result = append(result, post)
+		}
+	}
+
+	return result, nil
+}
+
+func (m *mockPostRepository) getPostsByPlace(ctx context.Context, viewerId uuid.UUID, placeId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+	var result []shared.Post
+	for _, post := range m.posts {
+		place := m.placeOf(post)
+		if place != nil && place.ID == placeId && post.ArchivedAt == nil && m.visibleTo(viewerId, post) && !m.blurredFor(viewerId, post) && len(result) < limit {
+			result = append(result, post)
+		}
+	}
+
+	return result, nil
+}
+
+// upsertPlace mirrors upsertPlace, places being matched by name and coordinates
+func (m *mockPostRepository) upsertPlace(place *shared.Place) *shared.Place {
+	if place == nil {
+		return nil
+	}
+
+	key := *place
+	key.ID = uuid.Nil
+	if _, exists := m.places[key]; !exists {
+		m.places[key] = uuid.New()
+	}
+	key.ID = m.places[key]
+
+	return &key
+}
+
+// placeOf mirrors postJoins, leaving out the places of the posts of users who hide their location
+func (m *mockPostRepository) placeOf(post shared.Post) *shared.Place {
+	if post.Place == nil || m.hideLocation[post.User.ID] {
+		return nil
+	}
+
+	return post.Place
+}
+
+// distance returns the great-circle distance in metres between two positions, as computed by getNearbyPosts
+func distance(latitude1 float64, longitude1 float64, latitude2 float64, longitude2 float64) float64 {
+	lat1, lat2 := latitude1*math.Pi/180, latitude2*math.Pi/180
+	dLat, dLng := lat2-lat1, (longitude2-longitude1)*math.Pi/180
+
+	h := math.Pow(math.Sin(dLat/2), 2) + math.Cos(lat1)*math.Cos(lat2)*math.Pow(math.Sin(dLng/2), 2)
+
+	return 2 * earthRadius * math.Asin(math.Min(1, math.Sqrt(h)))
+}
+
+func (m *mockPostRepository) flagSensitive(ctx context.Context, id uuid.UUID, sensitive bool, contentWarning *string) error {
+	post, exists := m.posts[id]
+	if !exists || post.DeletedAt != nil {
+		return &PostNotFoundError{}
+	}
+
+	m.flagged[id] = sensitive
+	switch {
+	case sensitive && contentWarning != nil:
+		post.ContentWarning = contentWarning
+	case !sensitive && !post.Sensitive:
+		post.ContentWarning = nil
+	}
+	m.posts[id] = post
+
+	return nil
+}
+
+// sensitive mirrors shared.PostSensitive, posts being marked sensitive by their authors or flagged by moderators
+func (m *mockPostRepository) sensitive(post shared.Post) bool {
+	return post.Sensitive || m.flagged[post.ID]
+}
+
+// blurredFor mirrors shared.PostBlurredFor for the posts of the mock
+func (m *mockPostRepository) blurredFor(viewerId uuid.UUID, post shared.Post) bool {
+	return m.sensitive(post) && post.User.ID != viewerId && !m.showSensitive[viewerId]
+}
+
+// visibleTo mirrors shared.PostVisibleTo for the posts of the mock, so tests using it cover the usecases and not the
+// sql, which is checked by the shared visibility tests
+func (m *mockPostRepository) visibleTo(viewerId uuid.UUID, post shared.Post) bool {
+	switch {
+	case post.Draft, post.DeletedAt != nil:
+		return false
+	case post.User != nil && post.User.ID == viewerId:
+		return true
+	case post.Visibility == shared.VisibilityFollowers:
+		return containsId(m.followed[viewerId], post.User.ID)
+	case post.Visibility == shared.VisibilityCloseFriends:
+		return containsId(m.closeFriends[post.User.ID], viewerId)
+	default:
+		return true
+	}
+}
+
+func containsId(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+
+	return false
 }
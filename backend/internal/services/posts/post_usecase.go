@@ -3,24 +3,75 @@ package posts
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
+	"y-net/internal/logger"
+	"y-net/internal/media"
 	"y-net/internal/services/shared"
 
 	"github.com/google/uuid"
 )
 
 type IPostUsecase interface {
-	Create(ctx context.Context, post shared.Post) (uuid.UUID, error)
-	GetPosts(ctx context.Context, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error)
-	GetPost(ctx context.Context, id uuid.UUID) (shared.Post, error)
+	Create(ctx context.Context, post shared.Post) (shared.Post, error)
+	GetPosts(ctx context.Context, viewerId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error)
+	GetPost(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) (shared.Post, error)
+	GetBySearch(ctx context.Context, viewerId uuid.UUID, searchStr string) ([]shared.Post, error)
 	Update(ctx context.Context, post shared.Post, id uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	Like(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error
-	GetLikes(ctx context.Context, id uuid.UUID) ([]shared.User, error)
+	GetLikes(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) ([]shared.User, error)
 	Unlike(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error
-	UserLikedPost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) (bool, error)
+	UserLikedPost(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID, postId uuid.UUID) (bool, error)
+	Save(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error
+	Unsave(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error
+	UserSavedPost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) (bool, error)
+	GetVideoPath(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID, mediaId uuid.UUID) (string, error)
+	BanImage(ctx context.Context, bannedImage BannedImage) (BannedImage, error)
+	GetBannedImages(ctx context.Context) ([]BannedImage, error)
+	UnbanImage(ctx context.Context, id uuid.UUID) error
+	GetSimilarImages(ctx context.Context, postId uuid.UUID, maxDistance int) ([]SimilarImage, error)
+	GetPostsByTag(ctx context.Context, viewerId uuid.UUID, tag string, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error)
+	GetTrendingTags(ctx context.Context, window time.Duration, limit int) ([]TrendingTag, error)
+	Repost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) (uuid.UUID, error)
+	Unrepost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error
+	React(ctx context.Context, userId uuid.UUID, postId uuid.UUID, reaction string) error
+	Unreact(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error
+	GetReactions(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID, reaction string) ([]Reaction, error)
+	GetDrafts(ctx context.Context, userId uuid.UUID) ([]shared.Post, error)
+	Publish(ctx context.Context, id uuid.UUID) error
+	Schedule(ctx context.Context, id uuid.UUID, scheduledAt time.Time) error
+	Unschedule(ctx context.Context, id uuid.UUID) error
+	GetRevisions(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID) ([]PostRevision, error)
+	GetTrash(ctx context.Context, userId uuid.UUID) ([]shared.Post, error)
+	Restore(ctx context.Context, userId uuid.UUID, id uuid.UUID) error
+	Archive(ctx context.Context, id uuid.UUID) error
+	Unarchive(ctx context.Context, id uuid.UUID) error
+	Pin(ctx context.Context, id uuid.UUID) error
+	Unpin(ctx context.Context, id uuid.UUID) error
+	Vote(ctx context.Context, userId uuid.UUID, postId uuid.UUID, optionIds []uuid.UUID) error
+	GetPoll(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID) (shared.Poll, error)
+	GetNearbyPosts(ctx context.Context, viewerId uuid.UUID, latitude float64, longitude float64, radius float64) ([]shared.Post, error)
+	GetPostsByPlace(ctx context.Context, viewerId uuid.UUID, placeId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error)
+	FlagSensitive(ctx context.Context, id uuid.UUID, flag SensitiveFlag) error
 }
 
+const (
+	maxPostMedia     = 10
+	maxAltTextLength = 1000
+
+	// Hamming distance under which an upload is considered a copy of a banned image
+	BannedImageMaxDistance = 10
+	MaxSimilarDistance     = 20
+	maxSimilarResults      = 50
+
+	MaxTrendingWindow = 7 * 24 * time.Hour
+	MaxTrendingTags   = 50
+
+	maxPinnedPosts = 3
+)
+
 type postUsecaseImpl struct {
 	usecase    IPostUsecase
 	repository iPostRepository
@@ -33,24 +84,75 @@ func NewPostUsecase() IPostUsecase {
 	}
 }
 
-func (u *postUsecaseImpl) Create(ctx context.Context, post shared.Post) (uuid.UUID, error) {
+func (u *postUsecaseImpl) Create(ctx context.Context, post shared.Post) (shared.Post, error) {
 	if (post.User == &shared.User{}) {
-		return uuid.Nil, fmt.Errorf("user must not be empty")
+		return shared.Post{}, fmt.Errorf("user must not be empty")
 	}
-	if post.Image == "" {
-		return uuid.Nil, fmt.Errorf("post image must not be empty")
+
+	post, err := prepareMedia(post)
+	if err != nil {
+		return shared.Post{}, err
 	}
 
-	id, err := u.repository.create(ctx, post)
+	err = u.checkBannedImages(ctx, post.Media)
 	if err != nil {
-		return uuid.Nil, err
+		return shared.Post{}, err
 	}
 
-	return id, nil
+	post.Visibility, err = normalizeVisibility(post.Visibility)
+	if err != nil {
+		return shared.Post{}, err
+	}
+
+	post.CommentPolicy, err = normalizeCommentPolicy(post.CommentPolicy)
+	if err != nil {
+		return shared.Post{}, err
+	}
+
+	post, err = normalizeSchedule(post)
+	if err != nil {
+		return shared.Post{}, err
+	}
+
+	post, err = normalizePoll(post)
+	if err != nil {
+		return shared.Post{}, err
+	}
+
+	post.Place, err = normalizePlace(post.Place)
+	if err != nil {
+		return shared.Post{}, err
+	}
+
+	post, err = normalizeSensitive(post)
+	if err != nil {
+		return shared.Post{}, err
+	}
+
+	post.Tags = ParseHashtags(post.Description)
+
+	post.Mentions, err = u.resolveMentions(ctx, post.Description)
+	if err != nil {
+		return shared.Post{}, err
+	}
+
+	// Videos are only written once the post is valid, and removed again if it can not be stored
+	err = storeVideos(post.Media)
+	if err != nil {
+		return shared.Post{}, err
+	}
+
+	post.ID, err = u.repository.create(ctx, post)
+	if err != nil {
+		removeVideos(post.Media)
+		return shared.Post{}, err
+	}
+
+	return post, nil
 }
 
-func (u *postUsecaseImpl) GetPosts(ctx context.Context, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
-	posts, err := u.repository.getPosts(ctx, limit, lastCreatedAt, lastId)
+func (u *postUsecaseImpl) GetPosts(ctx context.Context, viewerId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+	posts, err := u.repository.getPosts(ctx, viewerId, limit, lastCreatedAt, lastId)
 	if err != nil {
 		return nil, err
 	}
@@ -58,8 +160,8 @@ func (u *postUsecaseImpl) GetPosts(ctx context.Context, limit int, lastCreatedAt
 	return posts, nil
 }
 
-func (u *postUsecaseImpl) GetPost(ctx context.Context, id uuid.UUID) (shared.Post, error) {
-	post, err := u.repository.getPost(ctx, id)
+func (u *postUsecaseImpl) GetPost(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) (shared.Post, error) {
+	post, err := u.repository.getPost(ctx, viewerId, id)
 	if err != nil {
 		return shared.Post{}, err
 	}
@@ -67,19 +169,72 @@ func (u *postUsecaseImpl) GetPost(ctx context.Context, id uuid.UUID) (shared.Pos
 	return post, nil
 }
 
+func (u *postUsecaseImpl) GetBySearch(ctx context.Context, viewerId uuid.UUID, searchStr string) ([]shared.Post, error) {
+	if strings.TrimSpace(searchStr) == "" {
+		return nil, fmt.Errorf("search term must not be empty")
+	}
+
+	posts, err := u.repository.getBySearch(ctx, viewerId, searchStr)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
 func (u *postUsecaseImpl) Update(ctx context.Context, post shared.Post, id uuid.UUID) error {
 	if (post.User == &shared.User{}) {
 		return fmt.Errorf("user must not be empty")
 	}
-	if post.Image == "" {
-		return fmt.Errorf("post image must not be empty")
+
+	post, err := prepareMedia(post)
+	if err != nil {
+		return err
+	}
+
+	err = u.checkBannedImages(ctx, post.Media)
+	if err != nil {
+		return err
+	}
+
+	post.Visibility, err = normalizeVisibility(post.Visibility)
+	if err != nil {
+		return err
+	}
+
+	post.CommentPolicy, err = normalizeCommentPolicy(post.CommentPolicy)
+	if err != nil {
+		return err
+	}
+
+	post.Place, err = normalizePlace(post.Place)
+	if err != nil {
+		return err
+	}
+
+	post, err = normalizeSensitive(post)
+	if err != nil {
+		return err
+	}
+
+	post.Tags = ParseHashtags(post.Description)
+
+	post.Mentions, err = u.resolveMentions(ctx, post.Description)
+	if err != nil {
+		return err
 	}
 
-	err := u.repository.update(ctx, post, id)
+	err = storeVideos(post.Media)
 	if err != nil {
 		return err
 	}
 
+	err = u.repository.update(ctx, post, id, EditWindow())
+	if err != nil {
+		removeVideos(post.Media)
+		return err
+	}
+
 	return nil
 }
 
@@ -101,8 +256,8 @@ func (i *postUsecaseImpl) Like(ctx context.Context, userId uuid.UUID, postId uui
 	return nil
 }
 
-func (i *postUsecaseImpl) GetLikes(ctx context.Context, id uuid.UUID) ([]shared.User, error) {
-	users, err := i.repository.getLikes(ctx, id)
+func (i *postUsecaseImpl) GetLikes(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) ([]shared.User, error) {
+	users, err := i.repository.getLikes(ctx, viewerId, id)
 	if err != nil {
 		return nil, err
 	}
@@ -119,11 +274,611 @@ func (i *postUsecaseImpl) Unlike(ctx context.Context, userId uuid.UUID, postId u
 	return nil
 }
 
-func (i *postUsecaseImpl) UserLikedPost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) (bool, error) {
-	isLiked, err := i.repository.userLikedPost(ctx, userId, postId)
+func (i *postUsecaseImpl) UserLikedPost(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID, postId uuid.UUID) (bool, error) {
+	isLiked, err := i.repository.userLikedPost(ctx, viewerId, userId, postId)
 	if err != nil {
 		return false, err
 	}
 
 	return isLiked, nil
 }
+
+func (i *postUsecaseImpl) React(ctx context.Context, userId uuid.UUID, postId uuid.UUID, reaction string) error {
+	reaction = strings.ToLower(strings.TrimSpace(reaction))
+	if !IsReactionType(reaction) {
+		return &InvalidReactionError{}
+	}
+
+	err := i.repository.react(ctx, userId, postId, reaction)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *postUsecaseImpl) Unreact(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error {
+	err := i.repository.unreact(ctx, userId, postId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetReactions lists who reacted to a post, only with the given reaction unless it is empty
+func (i *postUsecaseImpl) GetReactions(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID, reaction string) ([]Reaction, error) {
+	reaction = strings.ToLower(strings.TrimSpace(reaction))
+	if reaction != "" && !IsReactionType(reaction) {
+		return nil, &InvalidReactionError{}
+	}
+
+	reactions, err := i.repository.getReactions(ctx, viewerId, postId, reaction)
+	if err != nil {
+		return nil, err
+	}
+
+	return reactions, nil
+}
+
+func (i *postUsecaseImpl) GetDrafts(ctx context.Context, userId uuid.UUID) ([]shared.Post, error) {
+	drafts, err := i.repository.getDrafts(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return drafts, nil
+}
+
+func (i *postUsecaseImpl) Publish(ctx context.Context, id uuid.UUID) error {
+	err := i.repository.publish(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *postUsecaseImpl) Schedule(ctx context.Context, id uuid.UUID, scheduledAt time.Time) error {
+	scheduledAt, err := validateSchedule(scheduledAt)
+	if err != nil {
+		return err
+	}
+
+	err = i.repository.schedule(ctx, id, &scheduledAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *postUsecaseImpl) Unschedule(ctx context.Context, id uuid.UUID) error {
+	err := i.repository.schedule(ctx, id, nil)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *postUsecaseImpl) GetRevisions(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID) ([]PostRevision, error) {
+	revisions, err := i.repository.getRevisions(ctx, viewerId, postId)
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (i *postUsecaseImpl) GetTrash(ctx context.Context, userId uuid.UUID) ([]shared.Post, error) {
+	posts, err := i.repository.getTrash(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+func (i *postUsecaseImpl) Restore(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	err := i.repository.restore(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *postUsecaseImpl) Archive(ctx context.Context, id uuid.UUID) error {
+	err := i.repository.archive(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *postUsecaseImpl) Unarchive(ctx context.Context, id uuid.UUID) error {
+	err := i.repository.unarchive(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *postUsecaseImpl) Pin(ctx context.Context, id uuid.UUID) error {
+	err := i.repository.pin(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *postUsecaseImpl) Unpin(ctx context.Context, id uuid.UUID) error {
+	err := i.repository.unpin(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *postUsecaseImpl) Vote(ctx context.Context, userId uuid.UUID, postId uuid.UUID, optionIds []uuid.UUID) error {
+	optionIds, err := normalizeVote(optionIds)
+	if err != nil {
+		return err
+	}
+
+	err = i.repository.vote(ctx, userId, postId, optionIds)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *postUsecaseImpl) GetPoll(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID) (shared.Poll, error) {
+	post, err := i.repository.getPost(ctx, viewerId, postId)
+	if err != nil {
+		return shared.Poll{}, err
+	}
+
+	if post.Poll == nil {
+		return shared.Poll{}, &PollNotFoundError{}
+	}
+
+	return *post.Poll, nil
+}
+
+// GetNearbyPosts lists the most recent posts tagged with a place within radius metres of a position
+func (i *postUsecaseImpl) GetNearbyPosts(ctx context.Context, viewerId uuid.UUID, latitude float64, longitude float64, radius float64) ([]shared.Post, error) {
+	if !ValidCoordinates(latitude, longitude) {
+		return nil, fmt.Errorf("invalid nearby coordinates")
+	}
+	if radius <= 0 || radius > MaxNearbyRadius {
+		return nil, fmt.Errorf("nearby radius must be between 1 and %d metres", MaxNearbyRadius)
+	}
+
+	posts, err := i.repository.getNearbyPosts(ctx, viewerId, latitude, longitude, radius, maxNearbyResults)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+func (i *postUsecaseImpl) GetPostsByPlace(ctx context.Context, viewerId uuid.UUID, placeId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+	posts, err := i.repository.getPostsByPlace(ctx, viewerId, placeId, limit, lastCreatedAt, lastId)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+func (i *postUsecaseImpl) Save(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error {
+	err := i.repository.save(ctx, userId, postId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *postUsecaseImpl) Unsave(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error {
+	err := i.repository.unsave(ctx, userId, postId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *postUsecaseImpl) UserSavedPost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) (bool, error) {
+	saved, err := i.repository.userSavedPost(ctx, userId, postId)
+	if err != nil {
+		return false, err
+	}
+
+	return saved, nil
+}
+
+func (i *postUsecaseImpl) Repost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) (uuid.UUID, error) {
+	id, err := i.repository.repost(ctx, userId, postId)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return id, nil
+}
+
+func (i *postUsecaseImpl) Unrepost(ctx context.Context, userId uuid.UUID, postId uuid.UUID) error {
+	err := i.repository.unrepost(ctx, userId, postId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *postUsecaseImpl) GetVideoPath(ctx context.Context, viewerId uuid.UUID, postId uuid.UUID, mediaId uuid.UUID) (string, error) {
+	name, err := i.repository.getVideoPath(ctx, viewerId, postId, mediaId)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := media.FilePath(name)
+	if err != nil {
+		return "", err
+	}
+
+	return path, nil
+}
+
+func (i *postUsecaseImpl) BanImage(ctx context.Context, bannedImage BannedImage) (BannedImage, error) {
+	if bannedImage.Image != "" {
+		meta, err := media.ExtractImageMetadata(bannedImage.Image)
+		if err != nil {
+			return BannedImage{}, fmt.Errorf("invalid banned image: %w", err)
+		}
+		bannedImage.Hash = media.FormatHash(meta.Hash)
+	}
+	if bannedImage.Hash == "" {
+		return BannedImage{}, fmt.Errorf("banned image or hash must not be empty")
+	}
+
+	newBannedImage, err := i.repository.banImage(ctx, bannedImage)
+	if err != nil {
+		return BannedImage{}, err
+	}
+
+	return newBannedImage, nil
+}
+
+func (i *postUsecaseImpl) GetBannedImages(ctx context.Context) ([]BannedImage, error) {
+	bannedImages, err := i.repository.getBannedImages(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return bannedImages, nil
+}
+
+func (i *postUsecaseImpl) UnbanImage(ctx context.Context, id uuid.UUID) error {
+	err := i.repository.unbanImage(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (i *postUsecaseImpl) GetSimilarImages(ctx context.Context, postId uuid.UUID, maxDistance int) ([]SimilarImage, error) {
+	if maxDistance < 0 || maxDistance > MaxSimilarDistance {
+		return nil, fmt.Errorf("distance must be between 0 and %d", MaxSimilarDistance)
+	}
+
+	images, err := i.repository.getSimilarImages(ctx, postId, maxDistance, maxSimilarResults)
+	if err != nil {
+		return nil, err
+	}
+
+	return images, nil
+}
+
+func (u *postUsecaseImpl) GetPostsByTag(ctx context.Context, viewerId uuid.UUID, tag string, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+	tag, ok := NormalizeTag(tag)
+	if !ok {
+		return nil, fmt.Errorf("invalid tag")
+	}
+
+	posts, err := u.repository.getPostsByTag(ctx, viewerId, tag, limit, lastCreatedAt, lastId)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+func (u *postUsecaseImpl) GetTrendingTags(ctx context.Context, window time.Duration, limit int) ([]TrendingTag, error) {
+	if window < time.Hour || window > MaxTrendingWindow {
+		return nil, fmt.Errorf("trending window must be between 1 hour and %d hours", int(MaxTrendingWindow.Hours()))
+	}
+	if limit <= 0 || limit > MaxTrendingTags {
+		return nil, fmt.Errorf("trending tags limit must be between 1 and %d", MaxTrendingTags)
+	}
+
+	tags, err := u.repository.getTrendingTags(ctx, window, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return tags, nil
+}
+
+// FlagSensitive marks a post as sensitive on behalf of the moderators, whatever its author chose, or lifts their flag
+func (u *postUsecaseImpl) FlagSensitive(ctx context.Context, id uuid.UUID, flag SensitiveFlag) error {
+	contentWarning, err := NormalizeContentWarning(flag.ContentWarning)
+	if err != nil {
+		return err
+	}
+
+	err = u.repository.flagSensitive(ctx, id, flag.Sensitive, contentWarning)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// resolveMentions parses the @mentions of a description, keeping the ones that match a user
+func (u *postUsecaseImpl) resolveMentions(ctx context.Context, description *string) ([]shared.Mention, error) {
+	if description == nil {
+		return nil, nil
+	}
+
+	mentions := shared.ParseMentions(*description)
+	if len(mentions) == 0 {
+		return nil, nil
+	}
+
+	userIds, err := u.repository.getUserIdsByUsernames(ctx, shared.MentionedUsernames(mentions))
+	if err != nil {
+		return nil, err
+	}
+
+	return shared.ResolveMentions(mentions, userIds), nil
+}
+
+// checkBannedImages rejects media whose perceptual hash is close to one in the moderators' banned list
+func (u *postUsecaseImpl) checkBannedImages(ctx context.Context, items []shared.PostMedia) error {
+	var hashes []uint64
+	for _, item := range items {
+		if item.Hash != nil {
+			hashes = append(hashes, *item.Hash)
+		}
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+
+	banned, err := u.repository.matchBannedImage(ctx, hashes, BannedImageMaxDistance)
+	if err != nil {
+		return err
+	}
+	if banned {
+		return &BannedImageError{}
+	}
+
+	return nil
+}
+
+// normalizeSchedule keeps scheduled posts as drafts until they are due, rejecting times that have already passed
+func normalizeSchedule(post shared.Post) (shared.Post, error) {
+	if post.ScheduledAt == nil {
+		return post, nil
+	}
+
+	scheduledAt, err := validateSchedule(*post.ScheduledAt)
+	if err != nil {
+		return shared.Post{}, err
+	}
+	post.ScheduledAt = &scheduledAt
+	post.Draft = true
+
+	return post, nil
+}
+
+// validateSchedule rejects scheduled times that have already passed, returning the time in UTC as stored in the database
+func validateSchedule(scheduledAt time.Time) (time.Time, error) {
+	if !scheduledAt.After(time.Now()) {
+		return time.Time{}, &InvalidScheduleError{}
+	}
+
+	return scheduledAt.UTC(), nil
+}
+
+// normalizeVisibility defaults the visibility of a post to public, rejecting unknown levels
+func normalizeVisibility(visibility string) (string, error) {
+	if visibility == "" {
+		return shared.VisibilityPublic, nil
+	}
+	if !shared.IsVisibility(visibility) {
+		return "", &InvalidVisibilityError{}
+	}
+
+	return visibility, nil
+}
+
+// normalizeCommentPolicy defaults the comment policy of a post to everyone, rejecting unknown levels
+func normalizeCommentPolicy(policy string) (string, error) {
+	if policy == "" {
+		return shared.CommentPolicyEveryone, nil
+	}
+	if !shared.IsCommentPolicy(policy) {
+		return "", &InvalidCommentPolicyError{}
+	}
+
+	return policy, nil
+}
+
+// prepareMedia validates the media items of a post and computes their placeholders,
+// accepting a single image for older clients and using the first item as the post cover.
+// Items sent with an id refer to media already stored with the post and are kept as they are.
+// Quote posts may go without media as long as they have a description
+func prepareMedia(post shared.Post) (shared.Post, error) {
+	if len(post.Media) == 0 && post.Image != "" {
+		post.Media = []shared.PostMedia{{Image: post.Image, AltText: post.AltText}}
+	}
+	if len(post.Media) == 0 && (post.QuoteOf != nil || post.Poll != nil) {
+		if post.Description == nil || strings.TrimSpace(*post.Description) == "" {
+			return shared.Post{}, &MissingDescriptionError{}
+		}
+		post.Status = shared.StatusReady
+		post.Media = nil
+		post.MediaCount = 0
+		post.Image = ""
+		return post, nil
+	}
+	if len(post.Media) == 0 {
+		return shared.Post{}, &EmptyMediaError{}
+	}
+	if len(post.Media) > maxPostMedia {
+		return shared.Post{}, &TooManyMediaError{}
+	}
+
+	post.Status = shared.StatusReady
+
+	items := make([]shared.PostMedia, len(post.Media))
+	for i, item := range post.Media {
+		altText, err := NormalizeAltText(item.AltText)
+		if err != nil {
+			return shared.Post{}, err
+		}
+		item.AltText = altText
+
+		switch {
+		case item.ID != uuid.Nil:
+			item = shared.PostMedia{ID: item.ID, AltText: item.AltText}
+		case item.Video != "":
+			item, err = prepareVideo(item)
+		case item.Image != "":
+			item, err = prepareImage(item)
+		default:
+			err = &EmptyMediaError{}
+		}
+		if err != nil {
+			return shared.Post{}, err
+		}
+
+		if item.Status == shared.StatusProcessing {
+			post.Status = shared.StatusProcessing
+		}
+
+		item.Position = i
+		items[i] = item
+	}
+
+	cover := items[0]
+	post.Media = items
+	post.MediaCount = len(items)
+	post.Image = cover.Image
+	post.Width = cover.Width
+	post.Height = cover.Height
+	post.DominantColor = cover.DominantColor
+	post.BlurHash = cover.BlurHash
+	post.AltText = cover.AltText
+
+	return post, nil
+}
+
+// NormalizeAltText trims the alt text of an image, returning nil when it is blank
+func NormalizeAltText(altText *string) (*string, error) {
+	if altText == nil {
+		return nil, nil
+	}
+
+	trimmed := strings.TrimSpace(*altText)
+	if trimmed == "" {
+		return nil, nil
+	}
+	if utf8.RuneCountInString(trimmed) > maxAltTextLength {
+		return nil, &AltTextTooLongError{}
+	}
+
+	return &trimmed, nil
+}
+
+func prepareImage(item shared.PostMedia) (shared.PostMedia, error) {
+	meta, err := media.ExtractImageMetadata(item.Image)
+	if err != nil {
+		return shared.PostMedia{}, &InvalidImageError{err: err}
+	}
+
+	item.Type = shared.MediaTypeImage
+	item.Status = shared.StatusReady
+	item.Width = &meta.Width
+	item.Height = &meta.Height
+	item.DominantColor = &meta.DominantColor
+	item.BlurHash = &meta.BlurHash
+	item.Hash = &meta.Hash
+
+	return item, nil
+}
+
+// prepareVideo validates an uploaded video and names the file it is stored in once the post is valid,
+// leaving its metadata and poster frame to the background processing
+func prepareVideo(item shared.PostMedia) (shared.PostMedia, error) {
+	data, err := media.DecodeBase64(item.Video)
+	if err != nil {
+		return shared.PostMedia{}, &InvalidVideoError{err: err}
+	}
+	if len(data) > media.MaxVideoSize {
+		return shared.PostMedia{}, &VideoTooLargeError{}
+	}
+
+	ext, err := media.SniffVideo(data)
+	if err != nil {
+		return shared.PostMedia{}, &InvalidVideoError{err: err}
+	}
+
+	return shared.PostMedia{
+		Type:      shared.MediaTypeVideo,
+		Status:    shared.StatusProcessing,
+		VideoPath: media.NewFileName(ext),
+		VideoData: data,
+		AltText:   item.AltText,
+	}, nil
+}
+
+// storeVideos writes the uploaded videos of a post to the media folder, removing them all if one fails
+func storeVideos(items []shared.PostMedia) error {
+	for _, item := range items {
+		if item.VideoData == nil {
+			continue
+		}
+
+		err := media.WriteFile(item.VideoPath, item.VideoData)
+		if err != nil {
+			removeVideos(items)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// removeVideos deletes the uploaded videos of a post that could not be stored, logging instead of failing
+func removeVideos(items []shared.PostMedia) {
+	for _, item := range items {
+		if item.VideoData == nil {
+			continue
+		}
+
+		if err := media.RemoveFile(item.VideoPath); err != nil {
+			logger.ServerLogger.Error(err.Error())
+		}
+	}
+}
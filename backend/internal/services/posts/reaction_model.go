@@ -0,0 +1,16 @@
+package posts
+
+import (
+	"time"
+	"y-net/internal/services/shared"
+)
+
+type Reaction struct {
+	User      *shared.User `json:"user,omitempty"`
+	Type      string       `json:"type,omitempty"`
+	CreatedAt time.Time    `json:"createdAt,omitempty"`
+}
+
+type ReactionJson struct {
+	Type string `json:"type,omitempty"`
+}
@@ -0,0 +1,18 @@
+package posts
+
+import (
+	"time"
+	"y-net/internal/services/shared"
+
+	"github.com/google/uuid"
+)
+
+// PostRevision is the state of a post before one of its edits. Media only record their layout and alt texts
+type PostRevision struct {
+	ID          uuid.UUID          `json:"id,omitempty"`
+	PostID      uuid.UUID          `json:"postId,omitempty"`
+	Description *string            `json:"description,omitempty"`
+	Visibility  string             `json:"visibility,omitempty"`
+	Media       []shared.PostMedia `json:"media,omitempty"`
+	CreatedAt   time.Time          `json:"createdAt,omitempty"`
+}
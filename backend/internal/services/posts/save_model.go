@@ -0,0 +1,5 @@
+package posts
+
+type SavedJson struct {
+	Saved bool `json:"saved,omitempty"`
+}
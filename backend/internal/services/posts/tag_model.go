@@ -0,0 +1,6 @@
+package posts
+
+type TrendingTag struct {
+	Name      string `json:"name"`
+	PostCount int    `json:"postCount"`
+}
@@ -0,0 +1,18 @@
+package shared
+
+const (
+	CommentPolicyEveryone  = "everyone"
+	CommentPolicyFollowers = "followers"
+	CommentPolicyMentioned = "mentioned"
+	CommentPolicyOff       = "off"
+)
+
+// IsCommentPolicy reports whether a value is one of the levels restricting who can comment on a post
+func IsCommentPolicy(policy string) bool {
+	switch policy {
+	case CommentPolicyEveryone, CommentPolicyFollowers, CommentPolicyMentioned, CommentPolicyOff:
+		return true
+	}
+
+	return false
+}
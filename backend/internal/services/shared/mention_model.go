@@ -0,0 +1,11 @@
+package shared
+
+import "github.com/google/uuid"
+
+// Mention locates an @username inside a text, its offset and length being counted in utf-16 code units as clients index strings
+type Mention struct {
+	UserID   uuid.UUID `json:"userId"`
+	Username string    `json:"username"`
+	Offset   int       `json:"offset"`
+	Length   int       `json:"length"`
+}
@@ -0,0 +1,104 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const maxMentions = 50
+
+// A mention must not follow a word character, @ or / so that emails and urls are not picked up
+var mentionRegexp = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_.@/])@([\p{L}\p{N}_.]+)`)
+
+// ParseMentions returns the @username candidates of a text in order of appearance, without resolving them to users.
+// Trailing dots are treated as punctuation and left out of the mention
+func ParseMentions(text string) []Mention {
+	var mentions []Mention
+	for _, match := range mentionRegexp.FindAllStringSubmatchIndex(text, -1) {
+		username := strings.TrimRight(text[match[2]:match[3]], ".")
+		if username == "" {
+			continue
+		}
+
+		// The @ is the byte right before the captured username
+		start := match[2] - 1
+		mentions = append(mentions, Mention{
+			Username: username,
+			Offset:   utf16Len(text[:start]),
+			Length:   utf16Len(username) + 1,
+		})
+		if len(mentions) == maxMentions {
+			break
+		}
+	}
+
+	return mentions
+}
+
+// MentionedUsernames returns the distinct usernames of the given mentions
+func MentionedUsernames(mentions []Mention) []string {
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, mention := range mentions {
+		if !seen[mention.Username] {
+			seen[mention.Username] = true
+			usernames = append(usernames, mention.Username)
+		}
+	}
+
+	return usernames
+}
+
+// ResolveMentions sets the user ids of the mentions, dropping the ones that do not match any user
+func ResolveMentions(mentions []Mention, userIds map[string]uuid.UUID) []Mention {
+	var resolved []Mention
+	for _, mention := range mentions {
+		if id, ok := userIds[mention.Username]; ok {
+			mention.UserID = id
+			resolved = append(resolved, mention)
+		}
+	}
+
+	return resolved
+}
+
+// GetUserIdsByUsernames returns the ids of the users with the given usernames, keyed by username.
+// Usernames that do not match any user are left out
+func GetUserIdsByUsernames(ctx context.Context, tx pgx.Tx, usernames []string) (map[string]uuid.UUID, error) {
+	rows, err := tx.Query(ctx, "SELECT id, username FROM users WHERE username = ANY($1)", usernames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select users: %w", err)
+	}
+	defer rows.Close()
+
+	userIds := make(map[string]uuid.UUID)
+	for rows.Next() {
+		var id uuid.UUID
+		var username string
+		err := rows.Scan(&id, &username)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		userIds[username] = id
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return userIds, nil
+}
+
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+
+	return n
+}
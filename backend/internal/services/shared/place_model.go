@@ -0,0 +1,12 @@
+package shared
+
+import "github.com/google/uuid"
+
+// Place is a named location a post can be tagged with. Its coordinates are rounded before being stored
+// so that posts do not give away the exact position of their authors
+type Place struct {
+	ID        uuid.UUID `json:"id,omitempty"`
+	Name      string    `json:"name"`
+	Latitude  float64   `json:"latitude"`
+	Longitude float64   `json:"longitude"`
+}
@@ -0,0 +1,26 @@
+package shared
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Poll is attached to a post. The vote counts of its options are only read once the viewer has voted
+// or the poll has closed so that the results do not sway the votes
+type Poll struct {
+	Options        []PollOption `json:"options,omitempty"`
+	MultipleChoice bool         `json:"multipleChoice"`
+	ClosesAt       time.Time    `json:"closesAt"`
+	Closed         bool         `json:"closed"`
+	VoterCount     int          `json:"voterCount"`
+	Voted          bool         `json:"voted"`
+}
+
+type PollOption struct {
+	ID        uuid.UUID `json:"id,omitempty"`
+	Position  int       `json:"position"`
+	Text      string    `json:"text"`
+	VoteCount *int      `json:"voteCount,omitempty"`
+	Voted     bool      `json:"voted,omitempty"`
+}
@@ -6,12 +6,71 @@ import (
 	"github.com/google/uuid"
 )
 
+const (
+	MediaTypeImage = "image"
+	MediaTypeVideo = "video"
+
+	StatusProcessing = "processing"
+	StatusReady      = "ready"
+	StatusFailed     = "failed"
+)
+
+// Post is a post as returned to clients. LikeCount only counts hearts, the reactions listed by the likes endpoints,
+// while Reactions holds the count of every reaction type
 type Post struct {
-	ID           uuid.UUID `json:"id,omitempty"`
-	User         *User     `json:"user,omitempty"`
-	Image        string    `json:"image,omitempty"`
-	Description  *string   `json:"description,omitempty"`
-	LikeCount    int       `json:"likeCount,omitempty"`
-	CommentCount int       `json:"commentCount,omitempty"`
-	CreatedAt    time.Time `json:"createdAt,omitempty"`
+	ID             uuid.UUID      `json:"id,omitempty"`
+	User           *User          `json:"user,omitempty"`
+	Image          string         `json:"image,omitempty"`
+	Width          *int           `json:"width,omitempty"`
+	Height         *int           `json:"height,omitempty"`
+	DominantColor  *string        `json:"dominantColor,omitempty"`
+	BlurHash       *string        `json:"blurHash,omitempty"`
+	AltText        *string        `json:"altText,omitempty"`
+	Media          []PostMedia    `json:"media,omitempty"`
+	MediaCount     int            `json:"mediaCount,omitempty"`
+	Status         string         `json:"status,omitempty"`
+	Visibility     string         `json:"visibility,omitempty"`
+	CommentPolicy  string         `json:"commentPolicy,omitempty"`
+	Draft          bool           `json:"draft,omitempty"`
+	ScheduledAt    *time.Time     `json:"scheduledAt,omitempty"`
+	Description    *string        `json:"description,omitempty"`
+	ContentWarning *string        `json:"contentWarning,omitempty"`
+	Sensitive      bool           `json:"sensitive,omitempty"`
+	Blurred        bool           `json:"blurred,omitempty"`
+	Tags           []string       `json:"tags,omitempty"`
+	Mentions       []Mention      `json:"mentions,omitempty"`
+	RepostOf       *Post          `json:"repostOf,omitempty"`
+	QuoteOf        *Post          `json:"quoteOf,omitempty"`
+	Poll           *Poll          `json:"poll,omitempty"`
+	Place          *Place         `json:"place,omitempty"`
+	LikeCount      int            `json:"likeCount,omitempty"`
+	Reactions      map[string]int `json:"reactions,omitempty"`
+	Reaction       *string        `json:"reaction,omitempty"`
+	CommentCount   int            `json:"commentCount,omitempty"`
+	RepostCount    int            `json:"repostCount,omitempty"`
+	Saved          bool           `json:"saved,omitempty"`
+	SavedAt        *time.Time     `json:"savedAt,omitempty"`
+	EditedAt       *time.Time     `json:"editedAt,omitempty"`
+	DeletedAt      *time.Time     `json:"deletedAt,omitempty"`
+	ArchivedAt     *time.Time     `json:"archivedAt,omitempty"`
+	PinnedAt       *time.Time     `json:"pinnedAt,omitempty"`
+	CreatedAt      time.Time      `json:"createdAt,omitempty"`
+}
+
+type PostMedia struct {
+	ID            uuid.UUID `json:"id,omitempty"`
+	Position      int       `json:"position"`
+	Type          string    `json:"type,omitempty"`
+	Status        string    `json:"status,omitempty"`
+	Image         string    `json:"image,omitempty"`
+	Video         string    `json:"video,omitempty"`
+	VideoPath     string    `json:"-"`
+	VideoData     []byte    `json:"-"`
+	Duration      *float64  `json:"duration,omitempty"`
+	AltText       *string   `json:"altText,omitempty"`
+	Width         *int      `json:"width,omitempty"`
+	Height        *int      `json:"height,omitempty"`
+	DominantColor *string   `json:"dominantColor,omitempty"`
+	BlurHash      *string   `json:"blurHash,omitempty"`
+	Hash          *uint64   `json:"-"`
 }
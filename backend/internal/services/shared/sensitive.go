@@ -0,0 +1,19 @@
+package shared
+
+import "fmt"
+
+// PostSensitive returns the sql condition under which the post with the given alias is sensitive,
+// having been marked so by its author or flagged by a moderator
+func PostSensitive(alias string) string {
+	return fmt.Sprintf("(%[1]s.sensitive OR %[1]s.flagged_sensitive)", alias)
+}
+
+// PostBlurredFor returns the sql condition under which the media of the post with the given alias are hidden from the
+// viewer whose id is bound to the given parameter. Sensitive posts are blurred for everyone but their authors, unless
+// the viewer chose to reveal sensitive media
+func PostBlurredFor(alias string, viewerParam string) string {
+	return fmt.Sprintf(
+		"(%s AND %[2]s.user_id <> %[3]s AND NOT EXISTS (SELECT 1 FROM users vs WHERE vs.id = %[3]s AND vs.show_sensitive))",
+		PostSensitive(alias), alias, viewerParam,
+	)
+}
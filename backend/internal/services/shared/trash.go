@@ -0,0 +1,6 @@
+package shared
+
+import "time"
+
+// TrashRetention is how long deleted posts and comments can be restored before they are purged
+const TrashRetention = 30 * 24 * time.Hour
@@ -13,4 +13,7 @@ type User struct {
 	PostCount     int       `json:"postCount,omitempty"`
 	FollowerCount int       `json:"followerCount,omitempty"`
 	FollowedCount int       `json:"followedCount,omitempty"`
+	IsModerator   bool      `json:"isModerator,omitempty"`
+	HideLocation  bool      `json:"hideLocation,omitempty"`
+	ShowSensitive bool      `json:"showSensitive,omitempty"`
 }
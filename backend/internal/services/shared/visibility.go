@@ -0,0 +1,39 @@
+package shared
+
+import "fmt"
+
+const (
+	VisibilityPublic       = "public"
+	VisibilityFollowers    = "followers"
+	VisibilityCloseFriends = "close_friends"
+)
+
+// IsVisibility reports whether a value is one of the post visibility levels
+func IsVisibility(visibility string) bool {
+	switch visibility {
+	case VisibilityPublic, VisibilityFollowers, VisibilityCloseFriends:
+		return true
+	}
+
+	return false
+}
+
+// PostVisibleTo returns the sql condition under which the post with the given alias can be read by the viewer
+// whose id is bound to the given parameter. Authors always see their own published posts and reposts are only
+// visible along with the post they repost. Drafts are left out for everyone, their authors reading them by id,
+// and deleted posts are only listed in the trash of their authors
+func PostVisibleTo(alias string, viewerParam string) string {
+	condition := func(alias string) string {
+		return fmt.Sprintf(`(NOT %[1]s.draft AND %[1]s.deleted_at IS NULL AND (
+			%[1]s.visibility = 'public'
+			OR %[1]s.user_id = %[2]s
+			OR (%[1]s.visibility = 'followers' AND EXISTS (SELECT 1 FROM followers vf WHERE vf.follower_id = %[2]s AND vf.followed_id = %[1]s.user_id))
+			OR (%[1]s.visibility = 'close_friends' AND EXISTS (SELECT 1 FROM close_friends vc WHERE vc.user_id = %[1]s.user_id AND vc.friend_id = %[2]s))
+		))`, alias, viewerParam)
+	}
+
+	return fmt.Sprintf(
+		"(%s AND (%[2]s.repost_of IS NULL OR EXISTS (SELECT 1 FROM posts vo WHERE vo.id = %[2]s.repost_of AND %[3]s)))",
+		condition(alias), alias, condition("vo"),
+	)
+}
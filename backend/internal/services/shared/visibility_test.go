@@ -0,0 +1,51 @@
+package shared
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsVisibility(t *testing.T) {
+	for _, visibility := range []string{VisibilityPublic, VisibilityFollowers, VisibilityCloseFriends} {
+		assert.True(t, IsVisibility(visibility))
+	}
+	assert.False(t, IsVisibility("friends"))
+	assert.False(t, IsVisibility(""))
+}
+
+func TestPostVisibleTo(t *testing.T) {
+	condition := PostVisibleTo("cp", "$4")
+
+	assert.NotContains(t, condition, "%!")
+	assert.Equal(t, strings.Count(condition, "("), strings.Count(condition, ")"))
+
+	// Drafts and deleted posts are hidden, the other posts being readable depending on their visibility level
+	for _, clause := range []string{
+		"NOT cp.draft",
+		"cp.deleted_at IS NULL",
+		"cp.visibility = 'public'",
+		"OR cp.user_id = $4",
+		"(cp.visibility = 'followers' AND EXISTS (SELECT 1 FROM followers vf WHERE vf.follower_id = $4 AND vf.followed_id = cp.user_id))",
+		"(cp.visibility = 'close_friends' AND EXISTS (SELECT 1 FROM close_friends vc WHERE vc.user_id = cp.user_id AND vc.friend_id = $4))",
+	} {
+		assert.Contains(t, condition, clause)
+	}
+
+	// Reposts are only visible when the post they repost passes the same checks
+	assert.Contains(t, condition, "(cp.repost_of IS NULL OR EXISTS (SELECT 1 FROM posts vo WHERE vo.id = cp.repost_of AND (NOT vo.draft AND vo.deleted_at IS NULL AND (")
+	for _, clause := range []string{
+		"vo.visibility = 'public'",
+		"OR vo.user_id = $4",
+		"(vo.visibility = 'followers' AND EXISTS (SELECT 1 FROM followers vf WHERE vf.follower_id = $4 AND vf.followed_id = vo.user_id))",
+		"(vo.visibility = 'close_friends' AND EXISTS (SELECT 1 FROM close_friends vc WHERE vc.user_id = vo.user_id AND vc.friend_id = $4))",
+	} {
+		assert.Contains(t, condition, clause)
+	}
+
+	// Every level is covered by the condition
+	for _, visibility := range []string{VisibilityPublic, VisibilityFollowers, VisibilityCloseFriends} {
+		assert.Contains(t, condition, "cp.visibility = '"+visibility+"'")
+	}
+}
@@ -0,0 +1,7 @@
+package stories
+
+type StoryNotFoundError struct{}
+
+func (m *StoryNotFoundError) Error() string {
+	return "story not found"
+}
@@ -0,0 +1,32 @@
+package stories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"y-net/internal/logger"
+)
+
+// StartStorySweeper deletes expired stories in the background, polling every interval until ctx is cancelled
+func StartStorySweeper(ctx context.Context, interval time.Duration) {
+	repository := &storyRepositoryImpl{}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		deleted, err := repository.deleteExpired(ctx)
+		if err != nil {
+			logger.ServerLogger.Error(err.Error())
+		} else if deleted > 0 {
+			logger.ServerLogger.Info(fmt.Sprintf("deleted %d expired stories", deleted))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,35 @@
+package stories
+
+import (
+	"time"
+	"y-net/internal/services/shared"
+
+	"github.com/google/uuid"
+)
+
+type Story struct {
+	ID            uuid.UUID    `json:"id,omitempty"`
+	User          *shared.User `json:"user,omitempty"`
+	Image         string       `json:"image,omitempty"`
+	Width         *int         `json:"width,omitempty"`
+	Height        *int         `json:"height,omitempty"`
+	DominantColor *string      `json:"dominantColor,omitempty"`
+	BlurHash      *string      `json:"blurHash,omitempty"`
+	Seen          bool         `json:"seen,omitempty"`
+	ViewCount     int          `json:"viewCount,omitempty"`
+	CreatedAt     time.Time    `json:"createdAt,omitempty"`
+	ExpiresAt     time.Time    `json:"expiresAt,omitempty"`
+}
+
+// StoryTrayItem is an account of the story tray with the number of its active stories the viewer has not seen yet
+type StoryTrayItem struct {
+	User        shared.User `json:"user"`
+	StoryCount  int         `json:"storyCount"`
+	UnseenCount int         `json:"unseenCount"`
+	LatestAt    time.Time   `json:"latestAt"`
+}
+
+type StoryView struct {
+	User     shared.User `json:"user"`
+	ViewedAt time.Time   `json:"viewedAt"`
+}
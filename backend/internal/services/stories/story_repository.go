@@ -0,0 +1,309 @@
+package stories
+
+import (
+	"context"
+	"fmt"
+	"time"
+	database "y-net/internal/database/postgres"
+	"y-net/internal/services/shared"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+type iStoryRepository interface {
+	create(ctx context.Context, story Story, lifetime time.Duration) (Story, error)
+	get(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) (Story, error)
+	getTray(ctx context.Context, viewerId uuid.UUID) ([]StoryTrayItem, error)
+	getUserStories(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID) ([]Story, error)
+	markViewed(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) error
+	getViews(ctx context.Context, id uuid.UUID) ([]StoryView, error)
+	delete(ctx context.Context, id uuid.UUID) error
+	deleteExpired(ctx context.Context) (int64, error)
+}
+
+type storyRepositoryImpl struct{}
+
+// Stories are only shown to their author and the author's followers until they expire, $1 being the viewer
+const storyVisibleToViewer = `
+	s.expires_at > (NOW() AT TIME ZONE 'utc')
+	AND (s.user_id = $1 OR EXISTS (SELECT 1 FROM followers f WHERE f.follower_id = $1 AND f.followed_id = s.user_id))
+`
+
+// storyColumns selects a story in the order read by scanStory, the view count only being exposed to the author
+const storyColumns = `
+	s.id, s.user_id, u.username, u.avatar,
+	s.image, s.image_width, s.image_height, s.image_color, s.image_blurhash,
+	(s.user_id = $1 OR EXISTS (SELECT 1 FROM story_views v WHERE v.story_id = s.id AND v.viewer_id = $1)),
+	CASE WHEN s.user_id = $1 THEN (SELECT COUNT(*) FROM story_views v WHERE v.story_id = s.id) ELSE 0 END,
+	s.created_at, s.expires_at
+`
+
+func scanStory(row pgx.Row) (Story, error) {
+	var story Story
+	story.User = &shared.User{}
+	err := row.Scan(
+		&story.ID, &story.User.ID, &story.User.Username, &story.User.Avatar,
+		&story.Image, &story.Width, &story.Height, &story.DominantColor, &story.BlurHash,
+		&story.Seen, &story.ViewCount, &story.CreatedAt, &story.ExpiresAt,
+	)
+
+	return story, err
+}
+
+func (r *storyRepositoryImpl) create(ctx context.Context, story Story, lifetime time.Duration) (Story, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return Story{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	query := `
+		INSERT INTO stories (user_id, image, image_width, image_height, image_color, image_blurhash, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, (NOW() AT TIME ZONE 'utc') + make_interval(secs => $7))
+		RETURNING id, created_at, expires_at
+	`
+
+	err = tx.QueryRow(
+		ctx,
+		query,
+		story.User.ID, story.Image, story.Width, story.Height, story.DominantColor, story.BlurHash, lifetime.Seconds(),
+	).Scan(&story.ID, &story.CreatedAt, &story.ExpiresAt)
+	if err != nil {
+		return Story{}, fmt.Errorf("failed to insert story: %w", err)
+	}
+
+	return story, nil
+}
+
+func (r *storyRepositoryImpl) get(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) (Story, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return Story{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	query := `
+		SELECT ` + storyColumns + `
+		FROM stories s
+		INNER JOIN users u ON u.id = s.user_id
+		WHERE s.id = $2 AND ` + storyVisibleToViewer
+
+	story, err := scanStory(tx.QueryRow(ctx, query, viewerId, id))
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = &StoryNotFoundError{}
+			return Story{}, err
+		}
+
+		return Story{}, fmt.Errorf("failed to select story: %w", err)
+	}
+
+	return story, nil
+}
+
+func (r *storyRepositoryImpl) getTray(ctx context.Context, viewerId uuid.UUID) ([]StoryTrayItem, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	// The viewer's own stories come first, followed by the accounts with unseen stories, most recent first
+	query := `
+		SELECT u.id, u.username, u.avatar, COUNT(*),
+			COUNT(*) FILTER (WHERE s.user_id <> $1 AND v.story_id IS NULL),
+			MAX(s.created_at)
+		FROM stories s
+		INNER JOIN users u ON u.id = s.user_id
+		LEFT JOIN story_views v ON v.story_id = s.id AND v.viewer_id = $1
+		WHERE ` + storyVisibleToViewer + `
+		GROUP BY u.id, u.username, u.avatar
+		HAVING u.id = $1 OR COUNT(*) FILTER (WHERE v.story_id IS NULL) > 0
+		ORDER BY u.id = $1 DESC, MAX(s.created_at) DESC
+	`
+
+	rows, err := tx.Query(ctx, query, viewerId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select story tray: %w", err)
+	}
+	defer rows.Close()
+
+	var tray []StoryTrayItem
+	for rows.Next() {
+		var item StoryTrayItem
+		err := rows.Scan(&item.User.ID, &item.User.Username, &item.User.Avatar, &item.StoryCount, &item.UnseenCount, &item.LatestAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan story tray: %w", err)
+		}
+		tray = append(tray, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return tray, nil
+}
+
+func (r *storyRepositoryImpl) getUserStories(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID) ([]Story, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	query := `
+		SELECT ` + storyColumns + `
+		FROM stories s
+		INNER JOIN users u ON u.id = s.user_id
+		WHERE s.user_id = $2 AND ` + storyVisibleToViewer + `
+		ORDER BY s.created_at ASC
+	`
+
+	rows, err := tx.Query(ctx, query, viewerId, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select stories: %w", err)
+	}
+	defer rows.Close()
+
+	var stories []Story
+	for rows.Next() {
+		story, err := scanStory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan story: %w", err)
+		}
+		stories = append(stories, story)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return stories, nil
+}
+
+func (r *storyRepositoryImpl) markViewed(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	var authorId uuid.UUID
+	err = tx.QueryRow(ctx, "SELECT s.user_id FROM stories s WHERE s.id = $2 AND "+storyVisibleToViewer, viewerId, id).Scan(&authorId)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			err = &StoryNotFoundError{}
+			return err
+		}
+
+		return fmt.Errorf("failed to select story: %w", err)
+	}
+
+	// Authors looking at their own stories are not counted as viewers
+	if authorId == viewerId {
+		return nil
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO story_views (story_id, viewer_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", id, viewerId)
+	if err != nil {
+		return fmt.Errorf("failed to insert story view: %w", err)
+	}
+
+	return nil
+}
+
+func (r *storyRepositoryImpl) getViews(ctx context.Context, id uuid.UUID) ([]StoryView, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	query := `
+		SELECT u.id, u.username, u.full_name, u.avatar, v.viewed_at
+		FROM story_views v
+		INNER JOIN users u ON u.id = v.viewer_id
+		WHERE v.story_id = $1
+		ORDER BY v.viewed_at DESC
+	`
+
+	rows, err := tx.Query(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select story views: %w", err)
+	}
+	defer rows.Close()
+
+	var views []StoryView
+	for rows.Next() {
+		var view StoryView
+		err := rows.Scan(&view.User.ID, &view.User.Username, &view.User.FullName, &view.User.Avatar, &view.ViewedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan story view: %w", err)
+		}
+		views = append(views, view)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return views, nil
+}
+
+func (r *storyRepositoryImpl) delete(ctx context.Context, id uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	tag, err := tx.Exec(ctx, "DELETE FROM stories WHERE id = $1", id)
+	if err != nil {
+		return fmt.Errorf("failed to delete story: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		err = &StoryNotFoundError{}
+		return err
+	}
+
+	return nil
+}
+
+// deleteExpired removes the expired stories along with their images and views, returning how many were removed
+func (r *storyRepositoryImpl) deleteExpired(ctx context.Context) (int64, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	tag, err := tx.Exec(ctx, "DELETE FROM stories WHERE expires_at <= (NOW() AT TIME ZONE 'utc')")
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired stories: %w", err)
+	}
+
+	return tag.RowsAffected(), nil
+}
@@ -0,0 +1,301 @@
+package stories
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"sort"
+	"testing"
+	"time"
+	"y-net/internal/services/shared"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type TestSetup struct {
+	usecase IStoryUsecase
+	repo    *mockStoryRepository
+}
+
+func setup() *TestSetup {
+	repo := newMockStoryRepository()
+	usecase := &storyUsecaseImpl{repository: repo}
+
+	return &TestSetup{usecase: usecase, repo: repo}
+}
+
+// newTestImage returns a base64 encoded png with the given dimensions
+func newTestImage(t *testing.T, width int, height int) string {
+	var buf bytes.Buffer
+	err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height)))
+	require.NoError(t, err)
+
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+func TestCreateStory(t *testing.T) {
+	ts := setup()
+
+	user := shared.User{ID: uuid.New(), Username: "testuser"}
+	story, err := ts.usecase.Create(context.Background(), Story{User: &user, Image: newTestImage(t, 9, 16)})
+	assert.NoError(t, err)
+	assert.NotEqual(t, uuid.Nil, story.ID)
+	assert.Equal(t, 9, *story.Width)
+	assert.Equal(t, 16, *story.Height)
+	assert.Equal(t, StoryLifetime, story.ExpiresAt.Sub(story.CreatedAt))
+
+	_, err = ts.usecase.Create(context.Background(), Story{User: &user})
+	assert.Error(t, err)
+
+	_, err = ts.usecase.Create(context.Background(), Story{User: &user, Image: base64.StdEncoding.EncodeToString([]byte("not an image"))})
+	assert.Error(t, err)
+}
+
+func TestStoryTray(t *testing.T) {
+	ts := setup()
+
+	viewer := shared.User{ID: uuid.New(), Username: "viewer"}
+	followed := shared.User{ID: uuid.New(), Username: "followed"}
+	stranger := shared.User{ID: uuid.New(), Username: "stranger"}
+	ts.repo.follow(viewer.ID, followed.ID)
+
+	first, _ := ts.usecase.Create(context.Background(), Story{User: &followed, Image: newTestImage(t, 1, 1)})
+	_, _ = ts.usecase.Create(context.Background(), Story{User: &followed, Image: newTestImage(t, 1, 1)})
+	_, _ = ts.usecase.Create(context.Background(), Story{User: &stranger, Image: newTestImage(t, 1, 1)})
+
+	tray, err := ts.usecase.GetTray(context.Background(), viewer.ID)
+	assert.NoError(t, err)
+	assert.Len(t, tray, 1)
+	assert.Equal(t, followed.ID, tray[0].User.ID)
+	assert.Equal(t, 2, tray[0].UnseenCount)
+
+	err = ts.usecase.MarkViewed(context.Background(), viewer.ID, first.ID)
+	assert.NoError(t, err)
+
+	tray, _ = ts.usecase.GetTray(context.Background(), viewer.ID)
+	assert.Equal(t, 1, tray[0].UnseenCount)
+
+	userStories, err := ts.usecase.GetUserStories(context.Background(), viewer.ID, followed.ID)
+	assert.NoError(t, err)
+	assert.Len(t, userStories, 2)
+	assert.True(t, userStories[0].Seen)
+	assert.False(t, userStories[1].Seen)
+
+	// Stories of accounts the viewer does not follow can't be read or viewed
+	userStories, err = ts.usecase.GetUserStories(context.Background(), viewer.ID, stranger.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, userStories)
+
+	strangerStories, _ := ts.usecase.GetUserStories(context.Background(), stranger.ID, stranger.ID)
+	err = ts.usecase.MarkViewed(context.Background(), viewer.ID, strangerStories[0].ID)
+	assert.IsType(t, &StoryNotFoundError{}, err)
+}
+
+func TestStoryViews(t *testing.T) {
+	ts := setup()
+
+	author := shared.User{ID: uuid.New(), Username: "author"}
+	viewer := shared.User{ID: uuid.New(), Username: "viewer"}
+	ts.repo.follow(viewer.ID, author.ID)
+
+	story, _ := ts.usecase.Create(context.Background(), Story{User: &author, Image: newTestImage(t, 1, 1)})
+
+	// Repeated views and the author's own views are not counted
+	assert.NoError(t, ts.usecase.MarkViewed(context.Background(), viewer.ID, story.ID))
+	assert.NoError(t, ts.usecase.MarkViewed(context.Background(), viewer.ID, story.ID))
+	assert.NoError(t, ts.usecase.MarkViewed(context.Background(), author.ID, story.ID))
+
+	views, err := ts.usecase.GetViews(context.Background(), story.ID)
+	assert.NoError(t, err)
+	assert.Len(t, views, 1)
+	assert.Equal(t, viewer.ID, views[0].User.ID)
+
+	ownStory, err := ts.usecase.Get(context.Background(), author.ID, story.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, ownStory.ViewCount)
+}
+
+func TestExpiredStories(t *testing.T) {
+	ts := setup()
+
+	author := shared.User{ID: uuid.New(), Username: "author"}
+	viewer := shared.User{ID: uuid.New(), Username: "viewer"}
+	ts.repo.follow(viewer.ID, author.ID)
+
+	story, _ := ts.usecase.Create(context.Background(), Story{User: &author, Image: newTestImage(t, 1, 1)})
+	expired := ts.repo.stories[story.ID]
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+	ts.repo.stories[story.ID] = expired
+
+	_, err := ts.usecase.Get(context.Background(), viewer.ID, story.ID)
+	assert.IsType(t, &StoryNotFoundError{}, err)
+
+	tray, err := ts.usecase.GetTray(context.Background(), viewer.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, tray)
+
+	deleted, err := ts.repo.deleteExpired(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+	assert.Empty(t, ts.repo.stories)
+}
+
+// mockStoryRepository is a mock implementation of iStoryRepository for testing purposes
+type mockStoryRepository struct {
+	stories map[uuid.UUID]Story
+	// views maps a story to the time each viewer has seen it
+	views map[uuid.UUID]map[uuid.UUID]time.Time
+	// followed maps a follower to the users they follow
+	followed map[uuid.UUID]map[uuid.UUID]bool
+}
+
+func newMockStoryRepository() *mockStoryRepository {
+	return &mockStoryRepository{
+		stories:  make(map[uuid.UUID]Story),
+		views:    make(map[uuid.UUID]map[uuid.UUID]time.Time),
+		followed: make(map[uuid.UUID]map[uuid.UUID]bool),
+	}
+}
+
+func (m *mockStoryRepository) follow(followerId uuid.UUID, followedId uuid.UUID) {
+	if m.followed[followerId] == nil {
+		m.followed[followerId] = make(map[uuid.UUID]bool)
+	}
+	m.followed[followerId][followedId] = true
+}
+
+func (m *mockStoryRepository) visibleTo(viewerId uuid.UUID, story Story) bool {
+	if !story.ExpiresAt.After(time.Now()) {
+		return false
+	}
+
+	return story.User.ID == viewerId || m.followed[viewerId][story.User.ID]
+}
+
+func (m *mockStoryRepository) forViewer(viewerId uuid.UUID, story Story) Story {
+	_, seen := m.views[story.ID][viewerId]
+	story.Seen = story.User.ID == viewerId || seen
+	if story.User.ID == viewerId {
+		story.ViewCount = len(m.views[story.ID])
+	}
+
+	return story
+}
+
+func (m *mockStoryRepository) create(ctx context.Context, story Story, lifetime time.Duration) (Story, error) {
+	story.ID = uuid.New()
+	story.CreatedAt = time.Now()
+	story.ExpiresAt = story.CreatedAt.Add(lifetime)
+	m.stories[story.ID] = story
+
+	return story, nil
+}
+
+func (m *mockStoryRepository) get(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) (Story, error) {
+	story, exists := m.stories[id]
+	if !exists || !m.visibleTo(viewerId, story) {
+		return Story{}, &StoryNotFoundError{}
+	}
+
+	return m.forViewer(viewerId, story), nil
+}
+
+func (m *mockStoryRepository) getTray(ctx context.Context, viewerId uuid.UUID) ([]StoryTrayItem, error) {
+	items := make(map[uuid.UUID]*StoryTrayItem)
+	for _, story := range m.stories {
+		if !m.visibleTo(viewerId, story) {
+			continue
+		}
+
+		item, exists := items[story.User.ID]
+		if !exists {
+			item = &StoryTrayItem{User: *story.User}
+			items[story.User.ID] = item
+		}
+		item.StoryCount++
+		if !m.forViewer(viewerId, story).Seen {
+			item.UnseenCount++
+		}
+		if story.CreatedAt.After(item.LatestAt) {
+			item.LatestAt = story.CreatedAt
+		}
+	}
+
+	var tray []StoryTrayItem
+	for _, item := range items {
+		if item.User.ID == viewerId || item.UnseenCount > 0 {
+			tray = append(tray, *item)
+		}
+	}
+
+	return tray, nil
+}
+
+func (m *mockStoryRepository) getUserStories(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID) ([]Story, error) {
+	var stories []Story
+	for _, story := range m.stories {
+		if story.User.ID == userId && m.visibleTo(viewerId, story) {
+			stories = append(stories, m.forViewer(viewerId, story))
+		}
+	}
+	sort.Slice(stories, func(i, j int) bool {
+		return stories[i].CreatedAt.Before(stories[j].CreatedAt)
+	})
+
+	return stories, nil
+}
+
+func (m *mockStoryRepository) markViewed(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) error {
+	story, exists := m.stories[id]
+	if !exists || !m.visibleTo(viewerId, story) {
+		return &StoryNotFoundError{}
+	}
+	if story.User.ID == viewerId {
+		return nil
+	}
+
+	if m.views[id] == nil {
+		m.views[id] = make(map[uuid.UUID]time.Time)
+	}
+	if _, seen := m.views[id][viewerId]; !seen {
+		m.views[id][viewerId] = time.Now()
+	}
+
+	return nil
+}
+
+func (m *mockStoryRepository) getViews(ctx context.Context, id uuid.UUID) ([]StoryView, error) {
+	var views []StoryView
+	for viewerId, viewedAt := range m.views[id] {
+		views = append(views, StoryView{User: shared.User{ID: viewerId}, ViewedAt: viewedAt})
+	}
+
+	return views, nil
+}
+
+func (m *mockStoryRepository) delete(ctx context.Context, id uuid.UUID) error {
+	if _, exists := m.stories[id]; !exists {
+		return &StoryNotFoundError{}
+	}
+	delete(m.stories, id)
+	delete(m.views, id)
+
+	return nil
+}
+
+func (m *mockStoryRepository) deleteExpired(ctx context.Context) (int64, error) {
+	var deleted int64
+	for id, story := range m.stories {
+		if !story.ExpiresAt.After(time.Now()) {
+			delete(m.stories, id)
+			delete(m.views, id)
+			deleted++
+		}
+	}
+
+	return deleted, nil
+}
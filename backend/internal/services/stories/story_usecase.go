@@ -0,0 +1,114 @@
+package stories
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"y-net/internal/media"
+
+	"github.com/google/uuid"
+)
+
+type IStoryUsecase interface {
+	Create(ctx context.Context, story Story) (Story, error)
+	Get(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) (Story, error)
+	GetTray(ctx context.Context, viewerId uuid.UUID) ([]StoryTrayItem, error)
+	GetUserStories(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID) ([]Story, error)
+	MarkViewed(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) error
+	GetViews(ctx context.Context, id uuid.UUID) ([]StoryView, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+// Time after which a story is no longer shown and gets removed by the sweeper
+const StoryLifetime = 24 * time.Hour
+
+type storyUsecaseImpl struct {
+	usecase    IStoryUsecase
+	repository iStoryRepository
+}
+
+func NewStoryUsecase() IStoryUsecase {
+	return &storyUsecaseImpl{
+		usecase:    &storyUsecaseImpl{},
+		repository: &storyRepositoryImpl{},
+	}
+}
+
+func (u *storyUsecaseImpl) Create(ctx context.Context, story Story) (Story, error) {
+	if story.User == nil || story.User.ID == uuid.Nil {
+		return Story{}, fmt.Errorf("user must not be empty")
+	}
+	if story.Image == "" {
+		return Story{}, fmt.Errorf("story image must not be empty")
+	}
+
+	meta, err := media.ExtractImageMetadata(story.Image)
+	if err != nil {
+		return Story{}, fmt.Errorf("invalid story image: %w", err)
+	}
+	story.Width = &meta.Width
+	story.Height = &meta.Height
+	story.DominantColor = &meta.DominantColor
+	story.BlurHash = &meta.BlurHash
+
+	newStory, err := u.repository.create(ctx, story, StoryLifetime)
+	if err != nil {
+		return Story{}, err
+	}
+
+	return newStory, nil
+}
+
+func (u *storyUsecaseImpl) Get(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) (Story, error) {
+	story, err := u.repository.get(ctx, viewerId, id)
+	if err != nil {
+		return Story{}, err
+	}
+
+	return story, nil
+}
+
+func (u *storyUsecaseImpl) GetTray(ctx context.Context, viewerId uuid.UUID) ([]StoryTrayItem, error) {
+	tray, err := u.repository.getTray(ctx, viewerId)
+	if err != nil {
+		return nil, err
+	}
+
+	return tray, nil
+}
+
+func (u *storyUsecaseImpl) GetUserStories(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID) ([]Story, error) {
+	stories, err := u.repository.getUserStories(ctx, viewerId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return stories, nil
+}
+
+func (u *storyUsecaseImpl) MarkViewed(ctx context.Context, viewerId uuid.UUID, id uuid.UUID) error {
+	err := u.repository.markViewed(ctx, viewerId, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *storyUsecaseImpl) GetViews(ctx context.Context, id uuid.UUID) ([]StoryView, error) {
+	views, err := u.repository.getViews(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return views, nil
+}
+
+func (u *storyUsecaseImpl) Delete(ctx context.Context, id uuid.UUID) error {
+	err := u.repository.delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
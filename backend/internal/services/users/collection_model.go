@@ -0,0 +1,15 @@
+package users
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Collection struct {
+	ID        uuid.UUID `json:"id,omitempty"`
+	UserID    uuid.UUID `json:"userId,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	PostCount int       `json:"postCount,omitempty"`
+	CreatedAt time.Time `json:"createdAt,omitempty"`
+}
@@ -0,0 +1,9 @@
+package users
+
+type LocationPrivacy struct {
+	HideLocation bool `json:"hideLocation"`
+}
+
+type SensitiveMedia struct {
+	ShowSensitive bool `json:"showSensitive"`
+}
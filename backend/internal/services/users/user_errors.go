@@ -10,3 +10,26 @@ func (m *WrongUsernameOrPasswordError) Error() string {
 func (m *UserAlreadyExistsError) Error() string {
 	return "user already exists"
 }
+
+type CollectionNotFoundError struct{}
+type CollectionAlreadyExistsError struct{}
+
+func (m *CollectionNotFoundError) Error() string {
+	return "collection not found"
+}
+
+func (m *CollectionAlreadyExistsError) Error() string {
+	return "collection already exists"
+}
+
+type UserNotFoundError struct{}
+
+func (m *UserNotFoundError) Error() string {
+	return "user not found"
+}
+
+type PostNotFoundError struct{}
+
+func (m *PostNotFoundError) Error() string {
+	return "post not found"
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 
 	database "y-net/internal/database/postgres"
 	"y-net/internal/services/shared"
@@ -16,7 +17,8 @@ type iUserRepository interface {
 	create(ctx context.Context, user shared.User) (uuid.UUID, error)
 	get(ctx context.Context, id uuid.UUID) (shared.User, error)
 	getBySearch(ctx context.Context, searchStr string) ([]shared.User, error)
-	getPostsFromUser(ctx context.Context, userId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error)
+	getPostsFromUser(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error)
+	getArchivedPosts(ctx context.Context, userId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error)
 	update(ctx context.Context, user shared.User, id uuid.UUID) error
 	delete(ctx context.Context, id uuid.UUID) error
 	follow(ctx context.Context, followerId uuid.UUID, followedId uuid.UUID) error
@@ -24,10 +26,42 @@ type iUserRepository interface {
 	getFollowed(ctx context.Context, id uuid.UUID) ([]shared.User, error)
 	unfollow(ctx context.Context, followerId uuid.UUID, followedId uuid.UUID) error
 	userFollowsUser(ctx context.Context, followerId uuid.UUID, followedId uuid.UUID) (bool, error)
+	getSavedPosts(ctx context.Context, userId uuid.UUID, collectionId uuid.UUID, limit int, lastSavedAt time.Time, lastId uuid.UUID) ([]shared.Post, error)
+	createCollection(ctx context.Context, collection Collection) (Collection, error)
+	getCollections(ctx context.Context, userId uuid.UUID) ([]Collection, error)
+	updateCollection(ctx context.Context, collection Collection, id uuid.UUID) error
+	deleteCollection(ctx context.Context, userId uuid.UUID, id uuid.UUID) error
+	addToCollection(ctx context.Context, userId uuid.UUID, collectionId uuid.UUID, postId uuid.UUID) error
+	removeFromCollection(ctx context.Context, userId uuid.UUID, collectionId uuid.UUID, postId uuid.UUID) error
+	addCloseFriend(ctx context.Context, userId uuid.UUID, friendId uuid.UUID) error
+	getCloseFriends(ctx context.Context, userId uuid.UUID) ([]shared.User, error)
+	removeCloseFriend(ctx context.Context, userId uuid.UUID, friendId uuid.UUID) error
+	setHideLocation(ctx context.Context, id uuid.UUID, hide bool) error
+	setShowSensitive(ctx context.Context, id uuid.UUID, show bool) error
 }
 
 type userRepositoryImpl struct{}
 
+// gridCoverJoin picks the cover shown for a post in a user grid, reposts and quotes without media
+// being shown with the cover of the original post, blurred when the post it belongs to is sensitive.
+// Covers of posts the viewer bound to $1 cannot read are never picked, posts left without a cover such as
+// text only polls and quotes still being listed
+var gridCoverJoin = `LEFT JOIN LATERAL (
+		SELECT c.image, c.image_width, c.image_height, c.image_color, c.image_blurhash, c.alt_text,
+			` + shared.PostSensitive("cp") + ` AS sensitive, ` + shared.PostBlurredFor("cp", "$1") + ` AS blurred
+		FROM post_media c
+		INNER JOIN posts cp ON cp.id = c.post_id
+		WHERE c.post_id IN (p.id, p.repost_of, p.quote_of) AND c.position = 0 AND cp.deleted_at IS NULL AND ` + shared.PostVisibleTo("cp", "$1") + `
+		ORDER BY c.post_id = p.id DESC, c.post_id = p.repost_of DESC
+		LIMIT 1
+	) m ON true`
+
+// gridPostColumns are the columns read by selectGridPosts from posts p joined by gridCoverJoin.
+// Queries using them pass the viewer id as $1
+const gridPostColumns = `p.id, COALESCE(m.image, ''), m.image_width, m.image_height, m.image_color, m.image_blurhash, m.alt_text,
+	COALESCE(m.sensitive, false), COALESCE(m.blurred, false),
+	(SELECT COUNT(*) FROM post_media WHERE post_id = COALESCE(p.repost_of, p.id)), p.repost_of, p.quote_of, p.repost_count, p.pinned_at, p.archived_at, p.created_at`
+
 func (r *userRepositoryImpl) create(ctx context.Context, user shared.User) (uuid.UUID, error) {
 	tx, err := database.Postgres.Begin(ctx)
 	if err != nil {
@@ -62,13 +96,13 @@ func (r *userRepositoryImpl) get(ctx context.Context, id uuid.UUID) (shared.User
 	}()
 
 	query := `
-		SELECT id, username, full_name, description, avatar, post_count, follower_count, followed_count
+		SELECT id, username, full_name, description, avatar, post_count, follower_count, followed_count, hide_location, show_sensitive
 		FROM users
 		WHERE id = $1
 	`
 
 	var user shared.User
-	err = tx.QueryRow(ctx, query, id).Scan(&user.ID, &user.Username, &user.FullName, &user.Description, &user.Avatar, &user.PostCount, &user.FollowerCount, &user.FollowedCount)
+	err = tx.QueryRow(ctx, query, id).Scan(&user.ID, &user.Username, &user.FullName, &user.Description, &user.Avatar, &user.PostCount, &user.FollowerCount, &user.FollowedCount, &user.HideLocation, &user.ShowSensitive)
 	if err != nil {
 		return shared.User{}, fmt.Errorf("failed to scan user: %w", err)
 	}
@@ -111,7 +145,7 @@ func (r *userRepositoryImpl) getBySearch(ctx context.Context, searchStr string)
 	return users, nil
 }
 
-func (r *userRepositoryImpl) getPostsFromUser(ctx context.Context, userId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+func (r *userRepositoryImpl) getPostsFromUser(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
 	tx, err := database.Postgres.Begin(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
@@ -122,48 +156,86 @@ func (r *userRepositoryImpl) getPostsFromUser(ctx context.Context, userId uuid.U
 
 	var query string
 	var args []interface{}
+	var posts []shared.Post
 
+	// Pinned posts are shown at the top of the first page only, the pages that follow leaving them out
+	// so that the cursor pagination of the other posts is not affected
 	if lastCreatedAt.IsZero() && lastId == uuid.Nil {
+		posts, err = selectGridPosts(ctx, tx, `
+			SELECT `+gridPostColumns+`
+			FROM posts p
+			`+gridCoverJoin+`
+			WHERE p.user_id = $2 AND p.status = 'ready' AND p.pinned_at IS NOT NULL AND p.archived_at IS NULL AND `+shared.PostVisibleTo("p", "$1")+`
+			ORDER BY p.pinned_at DESC
+		`, viewerId, userId)
+		if err != nil {
+			return nil, err
+		}
+
 		query = `
-			SELECT id, image, created_at
-			FROM posts
-			WHERE user_id = $1
-			ORDER BY created_at DESC, id DESC
-			LIMIT $2
+			SELECT ` + gridPostColumns + `
+			FROM posts p
+			` + gridCoverJoin + `
+			WHERE p.user_id = $2 AND p.status = 'ready' AND p.pinned_at IS NULL AND p.archived_at IS NULL AND ` + shared.PostVisibleTo("p", "$1") + `
+			ORDER BY p.created_at DESC, p.id DESC
+			LIMIT $3
 		`
-		args = append(args, userId, limit)
+		args = append(args, viewerId, userId, limit)
 	} else {
 		query = `
-			SELECT id, image, created_at
-			FROM posts
-			WHERE user_id = $1
-			AND (created_at < $2 OR (created_at = $2 AND id < $3))
-			ORDER BY created_at DESC, id DESC
-			LIMIT $4
+			SELECT ` + gridPostColumns + `
+			FROM posts p
+			` + gridCoverJoin + `
+			WHERE p.user_id = $2 AND p.status = 'ready' AND p.pinned_at IS NULL AND p.archived_at IS NULL AND ` + shared.PostVisibleTo("p", "$1") + `
+			AND (p.created_at < $3 OR (p.created_at = $3 AND p.id < $4))
+			ORDER BY p.created_at DESC, p.id DESC
+			LIMIT $5
 		`
-		args = append(args, userId, lastCreatedAt, lastId, limit)
+		args = append(args, viewerId, userId, lastCreatedAt, lastId, limit)
 	}
 
-	rows, err := tx.Query(ctx, query, args...)
+	page, err := selectGridPosts(ctx, tx, query, args...)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return []shared.Post{}, nil
-		}
+		return nil, err
+	}
 
-		return nil, fmt.Errorf("failed to select posts: %w", err)
+	return append(posts, page...), nil
+}
+
+func (r *userRepositoryImpl) getArchivedPosts(ctx context.Context, userId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
-	defer rows.Close()
 
-	var posts []shared.Post
-	for rows.Next() {
-		var post shared.Post
-		if err := rows.Scan(&post.ID, &post.Image, &post.CreatedAt); err != nil {
-			return nil, fmt.Errorf("failed to scan post: %w", err)
-		}
-		posts = append(posts, post)
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	query := `
+		SELECT ` + gridPostColumns + `
+		FROM posts p
+		` + gridCoverJoin + `
+		WHERE p.user_id = $1 AND p.status = 'ready' AND p.archived_at IS NOT NULL AND ` + shared.PostVisibleTo("p", "$1") + `
+	`
+	args := []interface{}{userId}
+
+	if !lastCreatedAt.IsZero() || lastId != uuid.Nil {
+		args = append(args, lastCreatedAt, lastId)
+		query += `
+			AND (p.created_at < $2 OR (p.created_at = $2 AND p.id < $3))
+		`
 	}
-	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("error reading rows: %w", err)
+
+	args = append(args, limit)
+	query += fmt.Sprintf(`
+		ORDER BY p.created_at DESC, p.id DESC
+		LIMIT $%d
+	`, len(args))
+
+	posts, err := selectGridPosts(ctx, tx, query, args...)
+	if err != nil {
+		return nil, err
 	}
 
 	return posts, nil
@@ -340,3 +412,406 @@ func (r *userRepositoryImpl) userFollowsUser(ctx context.Context, followerId uui
 
 	return exists, nil
 }
+
+// getSavedPosts reads the posts saved by a user, most recently saved first, optionally restricted to one of their collections.
+// Text only posts are listed without a cover
+func (r *userRepositoryImpl) getSavedPosts(ctx context.Context, userId uuid.UUID, collectionId uuid.UUID, limit int, lastSavedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	query := `
+		SELECT p.id, p.user_id, u.username, u.avatar, COALESCE(m.image, ''), m.image_width, m.image_height, m.image_color, m.image_blurhash, m.alt_text,
+			(SELECT COUNT(*) FROM post_media WHERE post_id = p.id), p.description, p.content_warning, ` + shared.PostSensitive("p") + `, ` + shared.PostBlurredFor("p", "$1") + `,
+			p.like_count, p.comment_count, s.created_at, p.created_at
+		FROM saves s
+		INNER JOIN posts p ON p.id = s.post_id
+		INNER JOIN users u ON p.user_id = u.id
+		LEFT JOIN post_media m ON m.post_id = p.id AND m.position = 0
+		WHERE s.user_id = $1 AND p.status = 'ready' AND ` + shared.PostVisibleTo("p", "$1") + `
+	`
+	args := []interface{}{userId}
+
+	if collectionId != uuid.Nil {
+		args = append(args, collectionId)
+		query += fmt.Sprintf(`
+			AND EXISTS (
+				SELECT 1 FROM collection_posts cp
+				INNER JOIN collections c ON c.id = cp.collection_id
+				WHERE cp.collection_id = $%d AND c.user_id = $1 AND cp.post_id = p.id
+			)
+		`, len(args))
+	}
+
+	if !lastSavedAt.IsZero() || lastId != uuid.Nil {
+		args = append(args, lastSavedAt, lastId)
+		query += fmt.Sprintf(`
+			AND (s.created_at < $%d OR (s.created_at = $%d AND p.id < $%d))
+		`, len(args)-1, len(args)-1, len(args))
+	}
+
+	args = append(args, limit)
+	query += fmt.Sprintf(`
+		ORDER BY s.created_at DESC, p.id DESC
+		LIMIT $%d
+	`, len(args))
+
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select saved posts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []shared.Post
+	for rows.Next() {
+		var post shared.Post
+		post.User = &shared.User{}
+		post.Saved = true
+		if err := rows.Scan(&post.ID, &post.User.ID, &post.User.Username, &post.User.Avatar, &post.Image, &post.Width, &post.Height, &post.DominantColor, &post.BlurHash, &post.AltText, &post.MediaCount, &post.Description, &post.ContentWarning, &post.Sensitive, &post.Blurred, &post.LikeCount, &post.CommentCount, &post.SavedAt, &post.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return posts, nil
+}
+
+func (r *userRepositoryImpl) createCollection(ctx context.Context, collection Collection) (Collection, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return Collection{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	newCollection := Collection{UserID: collection.UserID, Name: collection.Name}
+	err = tx.QueryRow(
+		ctx,
+		"INSERT INTO collections (user_id, name) VALUES ($1, $2) RETURNING id, created_at",
+		collection.UserID, collection.Name,
+	).Scan(&newCollection.ID, &newCollection.CreatedAt)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return Collection{}, &CollectionAlreadyExistsError{}
+		}
+
+		return Collection{}, fmt.Errorf("failed to insert collection: %w", err)
+	}
+
+	return newCollection, nil
+}
+
+func (r *userRepositoryImpl) getCollections(ctx context.Context, userId uuid.UUID) ([]Collection, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	query := `
+		SELECT c.id, c.user_id, c.name, (SELECT COUNT(*) FROM collection_posts WHERE collection_id = c.id), c.created_at
+		FROM collections c
+		WHERE c.user_id = $1
+		ORDER BY c.created_at, c.id
+	`
+
+	rows, err := tx.Query(ctx, query, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select collections: %w", err)
+	}
+	defer rows.Close()
+
+	collections := []Collection{}
+	for rows.Next() {
+		var collection Collection
+		if err := rows.Scan(&collection.ID, &collection.UserID, &collection.Name, &collection.PostCount, &collection.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan collection: %w", err)
+		}
+		collections = append(collections, collection)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return collections, nil
+}
+
+func (r *userRepositoryImpl) updateCollection(ctx context.Context, collection Collection, id uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	tag, err := tx.Exec(ctx, "UPDATE collections SET name = $1 WHERE id = $2 AND user_id = $3", collection.Name, id, collection.UserID)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23505" {
+			return &CollectionAlreadyExistsError{}
+		}
+
+		return fmt.Errorf("failed to update collection: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		err = &CollectionNotFoundError{}
+		return err
+	}
+
+	return nil
+}
+
+func (r *userRepositoryImpl) deleteCollection(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	tag, err := tx.Exec(ctx, "DELETE FROM collections WHERE id = $1 AND user_id = $2", id, userId)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		err = &CollectionNotFoundError{}
+		return err
+	}
+
+	return nil
+}
+
+// addToCollection adds a post to a collection of the user, saving it first if it was not saved yet
+func (r *userRepositoryImpl) addToCollection(ctx context.Context, userId uuid.UUID, collectionId uuid.UUID, postId uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	var exists bool
+	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM collections WHERE id = $1 AND user_id = $2)", collectionId, userId).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check if collection exists: %w", err)
+	}
+	if !exists {
+		err = &CollectionNotFoundError{}
+		return err
+	}
+
+	err = tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM posts p WHERE p.id = $2 AND "+shared.PostVisibleTo("p", "$1")+")", userId, postId).Scan(&exists)
+	if err != nil {
+		return fmt.Errorf("failed to check if post exists: %w", err)
+	}
+	if !exists {
+		err = &PostNotFoundError{}
+		return err
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO saves (user_id, post_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", userId, postId)
+	if err != nil {
+		return fmt.Errorf("failed to insert save: %w", err)
+	}
+
+	_, err = tx.Exec(ctx, "INSERT INTO collection_posts (collection_id, post_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", collectionId, postId)
+	if err != nil {
+		return fmt.Errorf("failed to insert collection post: %w", err)
+	}
+
+	return nil
+}
+
+// removeFromCollection removes a post from a collection of the user, the post staying saved
+func (r *userRepositoryImpl) removeFromCollection(ctx context.Context, userId uuid.UUID, collectionId uuid.UUID, postId uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	_, err = tx.Exec(
+		ctx,
+		"DELETE FROM collection_posts cp USING collections c WHERE cp.collection_id = c.id AND c.id = $1 AND c.user_id = $2 AND cp.post_id = $3",
+		collectionId, userId, postId,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection post: %w", err)
+	}
+
+	return nil
+}
+
+func (r *userRepositoryImpl) addCloseFriend(ctx context.Context, userId uuid.UUID, friendId uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	_, err = tx.Exec(ctx, "INSERT INTO close_friends (user_id, friend_id) VALUES ($1, $2) ON CONFLICT DO NOTHING", userId, friendId)
+	if err != nil {
+		if pgErr, ok := err.(*pgconn.PgError); ok && pgErr.Code == "23503" {
+			return &UserNotFoundError{}
+		}
+
+		return fmt.Errorf("failed to insert close friend: %w", err)
+	}
+
+	return nil
+}
+
+func (r *userRepositoryImpl) getCloseFriends(ctx context.Context, userId uuid.UUID) ([]shared.User, error) {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	query := `
+		SELECT u.id, u.username, u.full_name, u.avatar
+		FROM close_friends cf
+		INNER JOIN users u ON u.id = cf.friend_id
+		WHERE cf.user_id = $1
+		ORDER BY u.username
+	`
+
+	rows, err := tx.Query(ctx, query, userId)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select close friends: %w", err)
+	}
+	defer rows.Close()
+
+	var friends []shared.User
+	for rows.Next() {
+		var user shared.User
+		if err := rows.Scan(&user.ID, &user.Username, &user.FullName, &user.Avatar); err != nil {
+			return nil, fmt.Errorf("failed to scan user: %w", err)
+		}
+		friends = append(friends, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return friends, nil
+}
+
+func (r *userRepositoryImpl) removeCloseFriend(ctx context.Context, userId uuid.UUID, friendId uuid.UUID) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	_, err = tx.Exec(ctx, "DELETE FROM close_friends WHERE user_id = $1 AND friend_id = $2", userId, friendId)
+	if err != nil {
+		return fmt.Errorf("failed to delete close friend: %w", err)
+	}
+
+	return nil
+}
+
+func (r *userRepositoryImpl) setHideLocation(ctx context.Context, id uuid.UUID, hide bool) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	tag, err := tx.Exec(ctx, "UPDATE users SET hide_location = $1 WHERE id = $2", hide, id)
+	if err != nil {
+		return fmt.Errorf("failed to update location privacy: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		err = &UserNotFoundError{}
+		return err
+	}
+
+	return nil
+}
+
+func (r *userRepositoryImpl) setShowSensitive(ctx context.Context, id uuid.UUID, show bool) error {
+	tx, err := database.Postgres.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	tag, err := tx.Exec(ctx, "UPDATE users SET show_sensitive = $1 WHERE id = $2", show, id)
+	if err != nil {
+		return fmt.Errorf("failed to update sensitive media setting: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		err = &UserNotFoundError{}
+		return err
+	}
+
+	return nil
+}
+
+// selectGridPosts reads the posts of a user grid selected by the given query with gridPostColumns
+func selectGridPosts(ctx context.Context, tx pgx.Tx, query string, args ...interface{}) ([]shared.Post, error) {
+	rows, err := tx.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select posts: %w", err)
+	}
+	defer rows.Close()
+
+	var posts []shared.Post
+	for rows.Next() {
+		var post shared.Post
+		var repostOf, quoteOf *uuid.UUID
+		if err := rows.Scan(&post.ID, &post.Image, &post.Width, &post.Height, &post.DominantColor, &post.BlurHash, &post.AltText, &post.Sensitive, &post.Blurred, &post.MediaCount, &repostOf, &quoteOf, &post.RepostCount, &post.PinnedAt, &post.ArchivedAt, &post.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan post: %w", err)
+		}
+		if repostOf != nil {
+			post.RepostOf = &shared.Post{ID: *repostOf}
+		}
+		if quoteOf != nil {
+			post.QuoteOf = &shared.Post{ID: *quoteOf}
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error reading rows: %w", err)
+	}
+
+	return posts, nil
+}
@@ -207,11 +207,238 @@ func TestGetFollowed(t *testing.T) {
 	assert.Contains(t, followedUsers, shared.User{ID: followedId2})
 }
 
+func TestCollections(t *testing.T) {
+	ts := setup()
+
+	userId := uuid.New()
+	postId := uuid.New()
+	ts.repo.posts[postId] = shared.Post{ID: postId}
+
+	collection, err := ts.usecase.CreateCollection(context.Background(), Collection{UserID: userId, Name: "  Recipes "})
+	assert.NoError(t, err)
+	assert.Equal(t, "Recipes", collection.Name)
+
+	_, err = ts.usecase.CreateCollection(context.Background(), Collection{UserID: userId, Name: "Recipes"})
+	var existsErr *CollectionAlreadyExistsError
+	assert.ErrorAs(t, err, &existsErr)
+
+	_, err = ts.usecase.CreateCollection(context.Background(), Collection{UserID: userId, Name: " "})
+	assert.Error(t, err)
+
+	err = ts.usecase.AddToCollection(context.Background(), userId, collection.ID, postId)
+	assert.NoError(t, err)
+
+	saved, err := ts.usecase.GetSavedPosts(context.Background(), userId, uuid.Nil, 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, saved, 1)
+
+	collections, err := ts.usecase.GetCollections(context.Background(), userId)
+	assert.NoError(t, err)
+	assert.Len(t, collections, 1)
+	assert.Equal(t, 1, collections[0].PostCount)
+
+	err = ts.usecase.AddToCollection(context.Background(), uuid.New(), collection.ID, postId)
+	var notFoundErr *CollectionNotFoundError
+	assert.ErrorAs(t, err, &notFoundErr)
+
+	err = ts.usecase.RemoveFromCollection(context.Background(), userId, collection.ID, postId)
+	assert.NoError(t, err)
+
+	saved, err = ts.usecase.GetSavedPosts(context.Background(), userId, collection.ID, 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, saved, 0)
+
+	saved, err = ts.usecase.GetSavedPosts(context.Background(), userId, uuid.Nil, 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, saved, 1)
+
+	err = ts.usecase.DeleteCollection(context.Background(), userId, collection.ID)
+	assert.NoError(t, err)
+
+	err = ts.usecase.DeleteCollection(context.Background(), userId, collection.ID)
+	assert.ErrorAs(t, err, &notFoundErr)
+}
+
+func TestGetPostsFromUserVisibility(t *testing.T) {
+	ts := setup()
+
+	authorId := uuid.New()
+	followerId := uuid.New()
+	friendId := uuid.New()
+	strangerId := uuid.New()
+
+	for _, visibility := range []string{shared.VisibilityPublic, shared.VisibilityFollowers, shared.VisibilityCloseFriends} {
+		id := uuid.New()
+		ts.repo.posts[id] = shared.Post{ID: id, User: &shared.User{ID: authorId}, Visibility: visibility, CreatedAt: time.Now()}
+	}
+
+	err := ts.usecase.Follow(context.Background(), followerId, authorId)
+	assert.NoError(t, err)
+	err = ts.usecase.AddCloseFriend(context.Background(), authorId, friendId)
+	assert.NoError(t, err)
+
+	err = ts.usecase.AddCloseFriend(context.Background(), authorId, authorId)
+	assert.Error(t, err)
+
+	for viewerId, count := range map[uuid.UUID]int{authorId: 3, followerId: 2, friendId: 2, strangerId: 1} {
+		posts, err := ts.usecase.GetPostsFromUser(context.Background(), viewerId, authorId, 10, time.Time{}, uuid.Nil)
+		assert.NoError(t, err)
+		assert.Len(t, posts, count)
+	}
+
+	friends, err := ts.usecase.GetCloseFriends(context.Background(), authorId)
+	assert.NoError(t, err)
+	assert.Equal(t, []shared.User{{ID: friendId}}, friends)
+
+	err = ts.usecase.RemoveCloseFriend(context.Background(), authorId, friendId)
+	assert.NoError(t, err)
+
+	posts, err := ts.usecase.GetPostsFromUser(context.Background(), friendId, authorId, 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+}
+
+func TestGetPostsFromUserProcessing(t *testing.T) {
+	ts := setup()
+
+	authorId := uuid.New()
+	archivedAt := time.Now()
+	for _, status := range []string{shared.StatusReady, shared.StatusProcessing, shared.StatusFailed} {
+		id := uuid.New()
+		ts.repo.posts[id] = shared.Post{ID: id, User: &shared.User{ID: authorId}, Status: status, CreatedAt: time.Now()}
+		archivedId := uuid.New()
+		ts.repo.posts[archivedId] = shared.Post{ID: archivedId, User: &shared.User{ID: authorId}, Status: status, ArchivedAt: &archivedAt, CreatedAt: time.Now()}
+	}
+
+	// Posts whose videos are still processing or failed are left out of the grids
+	posts, err := ts.usecase.GetPostsFromUser(context.Background(), authorId, authorId, 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+	assert.Equal(t, shared.StatusReady, posts[0].Status)
+
+	posts, err = ts.usecase.GetArchivedPosts(context.Background(), authorId, 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+	assert.Equal(t, shared.StatusReady, posts[0].Status)
+}
+
+func TestGetPostsFromUserWithoutMedia(t *testing.T) {
+	ts := setup()
+
+	authorId := uuid.New()
+	pinnedAt := time.Now()
+	poll := &shared.Poll{Options: []shared.PollOption{{Position: 0, Text: "Cats"}, {Position: 1, Text: "Dogs"}}}
+	pollId := uuid.New()
+	ts.repo.posts[pollId] = shared.Post{ID: pollId, User: &shared.User{ID: authorId}, Poll: poll, CreatedAt: time.Now()}
+	pinnedId := uuid.New()
+	ts.repo.posts[pinnedId] = shared.Post{ID: pinnedId, User: &shared.User{ID: authorId}, Poll: poll, PinnedAt: &pinnedAt, CreatedAt: time.Now()}
+
+	// Polls without media are still listed in the grid, pinned ones included, just without a cover
+	posts, err := ts.usecase.GetPostsFromUser(context.Background(), uuid.New(), authorId, 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 2)
+	for _, post := range posts {
+		assert.Empty(t, post.Image)
+		assert.Contains(t, []uuid.UUID{pollId, pinnedId}, post.ID)
+	}
+}
+
+func TestGetArchivedPosts(t *testing.T) {
+	ts := setup()
+
+	authorId := uuid.New()
+	archivedAt := time.Now()
+	archivedId := uuid.New()
+	ts.repo.posts[archivedId] = shared.Post{ID: archivedId, User: &shared.User{ID: authorId}, ArchivedAt: &archivedAt, CreatedAt: time.Now()}
+	postId := uuid.New()
+	ts.repo.posts[postId] = shared.Post{ID: postId, User: &shared.User{ID: authorId}, CreatedAt: time.Now()}
+
+	// Archived posts are left out of the profile, even for their author
+	posts, err := ts.usecase.GetPostsFromUser(context.Background(), authorId, authorId, 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+	assert.Equal(t, postId, posts[0].ID)
+
+	archived, err := ts.usecase.GetArchivedPosts(context.Background(), authorId, 10, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, archived, 1)
+	assert.Equal(t, archivedId, archived[0].ID)
+}
+
+func TestGetPostsFromUserPinned(t *testing.T) {
+	ts := setup()
+
+	authorId := uuid.New()
+	pinnedAt := time.Now()
+	pinnedId := uuid.New()
+	ts.repo.posts[pinnedId] = shared.Post{ID: pinnedId, User: &shared.User{ID: authorId}, PinnedAt: &pinnedAt, CreatedAt: time.Now().Add(-time.Hour)}
+	for i := 0; i < 3; i++ {
+		id := uuid.New()
+		ts.repo.posts[id] = shared.Post{ID: id, User: &shared.User{ID: authorId}, CreatedAt: time.Now()}
+	}
+
+	// Pinned posts come first on the first page, on top of the requested number of posts
+	posts, err := ts.usecase.GetPostsFromUser(context.Background(), uuid.New(), authorId, 2, time.Time{}, uuid.Nil)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 3)
+	assert.Equal(t, pinnedId, posts[0].ID)
+
+	// The pages that follow leave them out
+	posts, err = ts.usecase.GetPostsFromUser(context.Background(), uuid.New(), authorId, 10, time.Now().Add(-2*time.Hour), uuid.New())
+	assert.NoError(t, err)
+	assert.Len(t, posts, 3)
+	for _, post := range posts {
+		assert.NotEqual(t, pinnedId, post.ID)
+	}
+}
+
+func TestSetHideLocation(t *testing.T) {
+	ts := setup()
+
+	id, _ := ts.usecase.Create(context.Background(), shared.User{Username: "testuser", Password: "password123"})
+
+	err := ts.usecase.SetHideLocation(context.Background(), id, true)
+	assert.NoError(t, err)
+
+	user, err := ts.usecase.Get(context.Background(), id)
+	assert.NoError(t, err)
+	assert.True(t, user.HideLocation)
+
+	err = ts.usecase.SetHideLocation(context.Background(), uuid.New(), true)
+	assert.IsType(t, &UserNotFoundError{}, err)
+}
+
+func TestSetShowSensitive(t *testing.T) {
+	ts := setup()
+
+	id, _ := ts.usecase.Create(context.Background(), shared.User{Username: "testuser", Password: "password123"})
+
+	err := ts.usecase.SetShowSensitive(context.Background(), id, true)
+	assert.NoError(t, err)
+
+	user, err := ts.usecase.Get(context.Background(), id)
+	assert.NoError(t, err)
+	assert.True(t, user.ShowSensitive)
+
+	err = ts.usecase.SetShowSensitive(context.Background(), id, false)
+	assert.NoError(t, err)
+
+	user, _ = ts.usecase.Get(context.Background(), id)
+	assert.False(t, user.ShowSensitive)
+
+	err = ts.usecase.SetShowSensitive(context.Background(), uuid.New(), true)
+	assert.IsType(t, &UserNotFoundError{}, err)
+}
+
 // mockUserRepository is a mock implementation of iUserRepository for testing
 type mockUserRepository struct {
 	users        map[uuid.UUID]shared.User
 	followersMap map[uuid.UUID][]uuid.UUID
 	posts        map[uuid.UUID]shared.Post
+	saves        map[uuid.UUID][]uuid.UUID
+	collections  map[uuid.UUID]Collection
+	collected    map[uuid.UUID][]uuid.UUID
+	closeFriends map[uuid.UUID][]uuid.UUID
 }
 
 func newMockUserRepository() *mockUserRepository {
@@ -219,6 +446,10 @@ func newMockUserRepository() *mockUserRepository {
 		users:        make(map[uuid.UUID]shared.User),
 		followersMap: make(map[uuid.UUID][]uuid.UUID),
 		posts:        make(map[uuid.UUID]shared.Post),
+		saves:        make(map[uuid.UUID][]uuid.UUID),
+		collections:  make(map[uuid.UUID]Collection),
+		collected:    make(map[uuid.UUID][]uuid.UUID),
+		closeFriends: make(map[uuid.UUID][]uuid.UUID),
 	}
 }
 
@@ -247,13 +478,38 @@ func (m *mockUserRepository) getBySearch(ctx context.Context, searchStr string)
 	return result, nil
 }
 
-func (m *mockUserRepository) getPostsFromUser(ctx context.Context, userId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+func (m *mockUserRepository) getPostsFromUser(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+	var pinned, result []shared.Post
+	for _, post := range m.posts {
+		if post.User.ID != userId || !ready(post) || post.ArchivedAt != nil || !m.visibleTo(viewerId, post) {
+			continue
+		}
+		if post.PinnedAt != nil {
+			if lastCreatedAt.IsZero() && lastId == uuid.Nil {
+				pinned = append(pinned, post)
+			}
+			continue
+		}
+		if len(result) < limit && post.CreatedAt.After(lastCreatedAt) && post.ID != lastId {
+			result = append(result, post)
+		}
+	}
+
+	return append(pinned, result...), nil
+}
+
+// ready leaves out the posts whose videos are still processing or failed, posts of the mock without a status being ready
+func ready(post shared.Post) bool {
+	return post.Status == "" || post.Status == shared.StatusReady
+}
+
+func (m *mockUserRepository) getArchivedPosts(ctx context.Context, userId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
 	var result []shared.Post
 	for _, post := range m.posts {
 		if len(result) >= limit {
 			break
 		}
-		if post.User.ID == userId && post.CreatedAt.After(lastCreatedAt) && post.ID != lastId {
+		if post.User.ID == userId && ready(post) && post.ArchivedAt != nil && post.CreatedAt.After(lastCreatedAt) && post.ID != lastId {
 			result = append(result, post)
 		}
 	}
@@ -338,3 +594,169 @@ func (m *mockUserRepository) userFollowsUser(ctx context.Context, followerId uui
 
 	return false, nil
 }
+
+func (m *mockUserRepository) getSavedPosts(ctx context.Context, userId uuid.UUID, collectionId uuid.UUID, limit int, lastSavedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+	var result []shared.Post
+	for _, postId := range m.saves[userId] {
+		if len(result) >= limit {
+			break
+		}
+		if collectionId != uuid.Nil && !containsId(m.collected[collectionId], postId) {
+			continue
+		}
+		post := m.posts[postId]
+		post.Saved = true
+		result = append(result, post)
+	}
+
+	return result, nil
+}
+
+func (m *mockUserRepository) createCollection(ctx context.Context, collection Collection) (Collection, error) {
+	for _, existing := range m.collections {
+		if existing.UserID == collection.UserID && existing.Name == collection.Name {
+			return Collection{}, &CollectionAlreadyExistsError{}
+		}
+	}
+	collection.ID = uuid.New()
+	m.collections[collection.ID] = collection
+
+	return collection, nil
+}
+
+func (m *mockUserRepository) getCollections(ctx context.Context, userId uuid.UUID) ([]Collection, error) {
+	collections := []Collection{}
+	for id, collection := range m.collections {
+		if collection.UserID == userId {
+			collection.PostCount = len(m.collected[id])
+			collections = append(collections, collection)
+		}
+	}
+
+	return collections, nil
+}
+
+func (m *mockUserRepository) updateCollection(ctx context.Context, collection Collection, id uuid.UUID) error {
+	existing, exists := m.collections[id]
+	if !exists || existing.UserID != collection.UserID {
+		return &CollectionNotFoundError{}
+	}
+	existing.Name = collection.Name
+	m.collections[id] = existing
+
+	return nil
+}
+
+func (m *mockUserRepository) deleteCollection(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	collection, exists := m.collections[id]
+	if !exists || collection.UserID != userId {
+		return &CollectionNotFoundError{}
+	}
+	delete(m.collections, id)
+	delete(m.collected, id)
+
+	return nil
+}
+
+func (m *mockUserRepository) addToCollection(ctx context.Context, userId uuid.UUID, collectionId uuid.UUID, postId uuid.UUID) error {
+	collection, exists := m.collections[collectionId]
+	if !exists || collection.UserID != userId {
+		return &CollectionNotFoundError{}
+	}
+	if !containsId(m.saves[userId], postId) {
+		m.saves[userId] = append(m.saves[userId], postId)
+	}
+	if !containsId(m.collected[collectionId], postId) {
+		m.collected[collectionId] = append(m.collected[collectionId], postId)
+	}
+
+	return nil
+}
+
+func (m *mockUserRepository) removeFromCollection(ctx context.Context, userId uuid.UUID, collectionId uuid.UUID, postId uuid.UUID) error {
+	posts := m.collected[collectionId]
+	for i, id := range posts {
+		if id == postId {
+			m.collected[collectionId] = append(posts[:i], posts[i+1:]...)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func containsId(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, other := range ids {
+		if other == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (m *mockUserRepository) addCloseFriend(ctx context.Context, userId uuid.UUID, friendId uuid.UUID) error {
+	if !containsId(m.closeFriends[userId], friendId) {
+		m.closeFriends[userId] = append(m.closeFriends[userId], friendId)
+	}
+
+	return nil
+}
+
+func (m *mockUserRepository) getCloseFriends(ctx context.Context, userId uuid.UUID) ([]shared.User, error) {
+	var friends []shared.User
+	for _, id := range m.closeFriends[userId] {
+		friends = append(friends, shared.User{ID: id})
+	}
+
+	return friends, nil
+}
+
+func (m *mockUserRepository) removeCloseFriend(ctx context.Context, userId uuid.UUID, friendId uuid.UUID) error {
+	friends := m.closeFriends[userId]
+	for i, id := range friends {
+		if id == friendId {
+			m.closeFriends[userId] = append(friends[:i], friends[i+1:]...)
+			return nil
+		}
+	}
+
+	return nil
+}
+
+func (m *mockUserRepository) setHideLocation(ctx context.Context, id uuid.UUID, hide bool) error {
+	user, exists := m.users[id]
+	if !exists {
+		return &UserNotFoundError{}
+	}
+	user.HideLocation = hide
+	m.users[id] = user
+
+	return nil
+}
+
+func (m *mockUserRepository) setShowSensitive(ctx context.Context, id uuid.UUID, show bool) error {
+	user, exists := m.users[id]
+	if !exists {
+		return &UserNotFoundError{}
+	}
+	user.ShowSensitive = show
+	m.users[id] = user
+
+	return nil
+}
+
+// visibleTo mirrors shared.PostVisibleTo for the posts of the mock, so tests using it cover the usecases and not the
+// sql, which is checked by the shared visibility tests
+func (m *mockUserRepository) visibleTo(viewerId uuid.UUID, post shared.Post) bool {
+	switch {
+	case post.User.ID == viewerId:
+		return true
+	case post.Visibility == shared.VisibilityFollowers:
+		return containsId(m.followersMap[viewerId], post.User.ID)
+	case post.Visibility == shared.VisibilityCloseFriends:
+		return containsId(m.closeFriends[post.User.ID], viewerId)
+	default:
+		return true
+	}
+}
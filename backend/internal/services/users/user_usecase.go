@@ -3,7 +3,9 @@ package users
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
+	"unicode/utf8"
 	database "y-net/internal/database/postgres"
 	"y-net/internal/services/shared"
 
@@ -16,7 +18,8 @@ type IUserUsecase interface {
 	Create(ctx context.Context, user shared.User) (uuid.UUID, error)
 	Get(ctx context.Context, id uuid.UUID) (shared.User, error)
 	GetBySearch(ctx context.Context, searchStr string) ([]shared.User, error)
-	GetPostsFromUser(ctx context.Context, userId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error)
+	GetPostsFromUser(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error)
+	GetArchivedPosts(ctx context.Context, userId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error)
 	Update(ctx context.Context, user shared.User, id uuid.UUID) error
 	Delete(ctx context.Context, id uuid.UUID) error
 	Follow(ctx context.Context, followerId uuid.UUID, followedId uuid.UUID) error
@@ -24,8 +27,22 @@ type IUserUsecase interface {
 	GetFollowed(ctx context.Context, id uuid.UUID) ([]shared.User, error)
 	Unfollow(ctx context.Context, followerId uuid.UUID, followedId uuid.UUID) error
 	UserFollowsUser(ctx context.Context, followerId uuid.UUID, followedId uuid.UUID) (bool, error)
+	GetSavedPosts(ctx context.Context, userId uuid.UUID, collectionId uuid.UUID, limit int, lastSavedAt time.Time, lastId uuid.UUID) ([]shared.Post, error)
+	CreateCollection(ctx context.Context, collection Collection) (Collection, error)
+	GetCollections(ctx context.Context, userId uuid.UUID) ([]Collection, error)
+	UpdateCollection(ctx context.Context, collection Collection, id uuid.UUID) error
+	DeleteCollection(ctx context.Context, userId uuid.UUID, id uuid.UUID) error
+	AddToCollection(ctx context.Context, userId uuid.UUID, collectionId uuid.UUID, postId uuid.UUID) error
+	RemoveFromCollection(ctx context.Context, userId uuid.UUID, collectionId uuid.UUID, postId uuid.UUID) error
+	AddCloseFriend(ctx context.Context, userId uuid.UUID, friendId uuid.UUID) error
+	GetCloseFriends(ctx context.Context, userId uuid.UUID) ([]shared.User, error)
+	RemoveCloseFriend(ctx context.Context, userId uuid.UUID, friendId uuid.UUID) error
+	SetHideLocation(ctx context.Context, id uuid.UUID, hide bool) error
+	SetShowSensitive(ctx context.Context, id uuid.UUID, show bool) error
 }
 
+const maxCollectionNameLength = 100
+
 type userUsecaseImpl struct {
 	usecase    IUserUsecase
 	repository iUserRepository
@@ -75,8 +92,17 @@ func (u *userUsecaseImpl) GetBySearch(ctx context.Context, searchStr string) ([]
 	return users, nil
 }
 
-func (u *userUsecaseImpl) GetPostsFromUser(ctx context.Context, userId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
-	posts, err := u.repository.getPostsFromUser(ctx, userId, limit, lastCreatedAt, lastId)
+func (u *userUsecaseImpl) GetPostsFromUser(ctx context.Context, viewerId uuid.UUID, userId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+	posts, err := u.repository.getPostsFromUser(ctx, viewerId, userId, limit, lastCreatedAt, lastId)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+func (u *userUsecaseImpl) GetArchivedPosts(ctx context.Context, userId uuid.UUID, limit int, lastCreatedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+	posts, err := u.repository.getArchivedPosts(ctx, userId, limit, lastCreatedAt, lastId)
 	if err != nil {
 		return nil, err
 	}
@@ -159,6 +185,147 @@ func (u *userUsecaseImpl) UserFollowsUser(ctx context.Context, followerId uuid.U
 	return follows, nil
 }
 
+func (u *userUsecaseImpl) GetSavedPosts(ctx context.Context, userId uuid.UUID, collectionId uuid.UUID, limit int, lastSavedAt time.Time, lastId uuid.UUID) ([]shared.Post, error) {
+	posts, err := u.repository.getSavedPosts(ctx, userId, collectionId, limit, lastSavedAt, lastId)
+	if err != nil {
+		return nil, err
+	}
+
+	return posts, nil
+}
+
+func (u *userUsecaseImpl) CreateCollection(ctx context.Context, collection Collection) (Collection, error) {
+	name, err := normalizeCollectionName(collection.Name)
+	if err != nil {
+		return Collection{}, err
+	}
+	collection.Name = name
+
+	newCollection, err := u.repository.createCollection(ctx, collection)
+	if err != nil {
+		return Collection{}, err
+	}
+
+	return newCollection, nil
+}
+
+func (u *userUsecaseImpl) GetCollections(ctx context.Context, userId uuid.UUID) ([]Collection, error) {
+	collections, err := u.repository.getCollections(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+func (u *userUsecaseImpl) UpdateCollection(ctx context.Context, collection Collection, id uuid.UUID) error {
+	name, err := normalizeCollectionName(collection.Name)
+	if err != nil {
+		return err
+	}
+	collection.Name = name
+
+	err = u.repository.updateCollection(ctx, collection, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *userUsecaseImpl) DeleteCollection(ctx context.Context, userId uuid.UUID, id uuid.UUID) error {
+	err := u.repository.deleteCollection(ctx, userId, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *userUsecaseImpl) AddToCollection(ctx context.Context, userId uuid.UUID, collectionId uuid.UUID, postId uuid.UUID) error {
+	err := u.repository.addToCollection(ctx, userId, collectionId, postId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *userUsecaseImpl) RemoveFromCollection(ctx context.Context, userId uuid.UUID, collectionId uuid.UUID, postId uuid.UUID) error {
+	err := u.repository.removeFromCollection(ctx, userId, collectionId, postId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *userUsecaseImpl) AddCloseFriend(ctx context.Context, userId uuid.UUID, friendId uuid.UUID) error {
+	if userId == friendId {
+		return fmt.Errorf("users can not add themselves to their close friends")
+	}
+
+	err := u.repository.addCloseFriend(ctx, userId, friendId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (u *userUsecaseImpl) GetCloseFriends(ctx context.Context, userId uuid.UUID) ([]shared.User, error) {
+	friends, err := u.repository.getCloseFriends(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return friends, nil
+}
+
+func (u *userUsecaseImpl) RemoveCloseFriend(ctx context.Context, userId uuid.UUID, friendId uuid.UUID) error {
+	err := u.repository.removeCloseFriend(ctx, userId, friendId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetHideLocation hides or shows the places of all the posts of a user, the posts keeping their places
+// so that showing them again restores them
+func (u *userUsecaseImpl) SetHideLocation(ctx context.Context, id uuid.UUID, hide bool) error {
+	err := u.repository.setHideLocation(ctx, id, hide)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// SetShowSensitive reveals or blurs the media of the sensitive posts of other users for a user,
+// sensitive posts being listed on the explore surfaces only for the users who reveal them
+func (u *userUsecaseImpl) SetShowSensitive(ctx context.Context, id uuid.UUID, show bool) error {
+	err := u.repository.setShowSensitive(ctx, id, show)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// normalizeCollectionName trims the name of a collection, which must not be empty
+func normalizeCollectionName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", fmt.Errorf("collection name must not be empty")
+	}
+	if utf8.RuneCountInString(name) > maxCollectionNameLength {
+		return "", fmt.Errorf("collection name must not be longer than %d characters", maxCollectionNameLength)
+	}
+
+	return name, nil
+}
+
 func Authenticate(ctx context.Context, user shared.User) (bool, error) {
 	conn, err := database.Postgres.Acquire(ctx)
 	if err != nil {
@@ -210,6 +377,32 @@ func GetUsernameByUserID(ctx context.Context, id uuid.UUID) (string, error) {
 	return username, nil
 }
 
+// GetAuthUserByID reads the identity and moderator flag of a user by given id
+func GetAuthUserByID(ctx context.Context, id uuid.UUID) (shared.User, error) {
+	conn, err := database.Postgres.Acquire(ctx)
+	if err != nil {
+		return shared.User{}, err
+	}
+	defer conn.Release()
+
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		return shared.User{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	defer func() {
+		database.HandleTransaction(ctx, tx, err)
+	}()
+
+	user := shared.User{ID: id}
+	err = tx.QueryRow(ctx, "SELECT username, is_moderator FROM users WHERE id = $1", id).Scan(&user.Username, &user.IsModerator)
+	if err != nil {
+		return shared.User{}, err
+	}
+
+	return user, nil
+}
+
 // GetUserIdByUsername checks if a user exists in database by given username
 func GetUserIdByUsername(ctx context.Context, username string) (uuid.UUID, error) {
 	conn, err := database.Postgres.Acquire(ctx)